@@ -27,16 +27,114 @@ type Config struct {
 	} `mapstructure:"DATABASE"`
 	JWT struct {
 		Key            string        `mapstructure:"KEY"`
+		Algorithm      string        `mapstructure:"ALGORITHM"`
+		Issuer         string        `mapstructure:"ISSUER"`
+		Audience       string        `mapstructure:"AUDIENCE"`
+		SigningKeyId   string        `mapstructure:"SIGNING_KEY_ID"`
+		KeysDir        string        `mapstructure:"KEYS_DIR"`
+		PrivateKey     string        `mapstructure:"PRIVATE_KEY"`
 		Expired        time.Duration `mapstructure:"EXPIRED"`
 		ExpiredRefresh time.Duration `mapstructure:"EXPIRED_REFRESH"`
+		RefreshCookie  bool          `mapstructure:"REFRESH_COOKIE"`
+		AuthCookie     bool          `mapstructure:"AUTH_COOKIE"`
+		CookieDomain   string        `mapstructure:"COOKIE_DOMAIN"`
+		CookieSameSite string        `mapstructure:"COOKIE_SAME_SITE"`
 	} `mapstructure:"JWT"`
-	DefaultPassword string `mapstructure:"DEFAULT_PASSWORD"`
-	DefaultLang     string `mapstructure:"DEFAULT_LANG"`
-	Files           struct {
+	Cors struct {
+		AllowedOrigins string `mapstructure:"ALLOWED_ORIGINS"`
+	} `mapstructure:"CORS"`
+	Mail struct {
+		Driver string `mapstructure:"DRIVER"`
+		Host   string `mapstructure:"HOST"`
+		Port   int    `mapstructure:"PORT"`
+		User   string `mapstructure:"USER"`
+		Pass   string `mapstructure:"PASS"`
+		From   string `mapstructure:"FROM"`
+		LogDir string `mapstructure:"LOG_DIR"`
+	} `mapstructure:"MAIL"`
+	Storage struct {
+		Driver      string `mapstructure:"DRIVER"`
+		Dir         string `mapstructure:"DIR"`
+		S3Endpoint  string `mapstructure:"S3_ENDPOINT"`
+		S3Region    string `mapstructure:"S3_REGION"`
+		S3Bucket    string `mapstructure:"S3_BUCKET"`
+		S3AccessKey string `mapstructure:"S3_ACCESS_KEY"`
+		S3SecretKey string `mapstructure:"S3_SECRET_KEY"`
+		S3PathStyle bool   `mapstructure:"S3_PATH_STYLE"`
+	} `mapstructure:"STORAGE"`
+	Password Password `mapstructure:"PASSWORD"`
+	Login    struct {
+		MaxAttempts   int           `mapstructure:"MAX_ATTEMPTS"`
+		MaxAttemptsIp int           `mapstructure:"MAX_ATTEMPTS_IP"`
+		LockDuration  time.Duration `mapstructure:"LOCK_DURATION"`
+		DelayStep     time.Duration `mapstructure:"DELAY_STEP"`
+		MaxDelay      time.Duration `mapstructure:"MAX_DELAY"`
+	} `mapstructure:"LOGIN"`
+	TwoFactor struct {
+		Issuer           string        `mapstructure:"ISSUER"`
+		ChallengeExpired time.Duration `mapstructure:"CHALLENGE_EXPIRED"`
+		RecoveryCodes    int           `mapstructure:"RECOVERY_CODES"`
+	} `mapstructure:"TWO_FACTOR"`
+	PasswordReset struct {
+		Expired     time.Duration `mapstructure:"EXPIRED"`
+		MaxRequests int           `mapstructure:"MAX_REQUESTS"`
+		Window      time.Duration `mapstructure:"WINDOW"`
+	} `mapstructure:"PASSWORD_RESET"`
+	MagicLink struct {
+		Enabled     bool          `mapstructure:"ENABLED"`
+		Expired     time.Duration `mapstructure:"EXPIRED"`
+		MaxRequests int           `mapstructure:"MAX_REQUESTS"`
+		Window      time.Duration `mapstructure:"WINDOW"`
+	} `mapstructure:"MAGIC_LINK"`
+	Account struct {
+		InactiveDays       int           `mapstructure:"INACTIVE_DAYS"`
+		TrashRetentionDays int           `mapstructure:"TRASH_RETENTION_DAYS"`
+		JobInterval        time.Duration `mapstructure:"JOB_INTERVAL"`
+	} `mapstructure:"ACCOUNT"`
+	Impersonation struct {
+		Expired time.Duration `mapstructure:"EXPIRED"`
+	} `mapstructure:"IMPERSONATION"`
+	Invitation struct {
+		Expired time.Duration `mapstructure:"EXPIRED"`
+	} `mapstructure:"INVITATION"`
+	Oidc struct {
+		StateExpired time.Duration           `mapstructure:"STATE_EXPIRED"`
+		Providers    map[string]OidcProvider `mapstructure:"PROVIDERS"`
+	} `mapstructure:"OIDC"`
+	AppUrl      string `mapstructure:"APP_URL"`
+	AdminEmail  string `mapstructure:"ADMIN_EMAIL"`
+	DefaultLang string `mapstructure:"DEFAULT_LANG"`
+	Files       struct {
 		Photo string `mapstructure:"PHOTO"`
 	} `mapstructure:"FILES"`
 }
 
+// Password is the password policy and hashing of PASSWORD
+type Password struct {
+	MinLength     int    `mapstructure:"MIN_LENGTH"`
+	RequireUpper  bool   `mapstructure:"REQUIRE_UPPER"`
+	RequireLower  bool   `mapstructure:"REQUIRE_LOWER"`
+	RequireDigit  bool   `mapstructure:"REQUIRE_DIGIT"`
+	RequireSymbol bool   `mapstructure:"REQUIRE_SYMBOL"`
+	History       int    `mapstructure:"HISTORY"`
+	BreachedList  string `mapstructure:"BREACHED_LIST"`
+	Hash          string `mapstructure:"HASH"`
+	BcryptCost    int    `mapstructure:"BCRYPT_COST"`
+	Argon2Memory  uint32 `mapstructure:"ARGON2_MEMORY"`
+	Argon2Time    uint32 `mapstructure:"ARGON2_TIME"`
+	Argon2Threads uint8  `mapstructure:"ARGON2_THREADS"`
+}
+
+// OidcProvider is one OpenID Connect provider of OIDC.PROVIDERS, keyed by the name used in the login routes
+type OidcProvider struct {
+	Issuer       string `mapstructure:"ISSUER"`
+	ClientId     string `mapstructure:"CLIENT_ID"`
+	ClientSecret string `mapstructure:"CLIENT_SECRET"`
+	RedirectUrl  string `mapstructure:"REDIRECT_URL"`
+	Scopes       string `mapstructure:"SCOPES"`
+	AutoCreate   bool   `mapstructure:"AUTO_CREATE"`
+}
+
 var (
 	conf Config
 	once sync.Once
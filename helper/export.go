@@ -0,0 +1,149 @@
+package helper
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Formats of an export, chosen with ?format=
+const (
+	ExportFormatCsv    = "csv"
+	ExportFormatNdjson = "ndjson"
+)
+
+// exportFlushRows is how many rows are buffered before they are sent to the client
+const exportFlushRows = 100
+
+// Exporter streams the rows of an export to the response as CSV or NDJSON. A row is a struct whose json tags name the
+// columns, so only the fields of that struct leave the server.
+type Exporter struct {
+	context   *gin.Context
+	fileName  string
+	format    string
+	columns   []string
+	fields    []int
+	csvWriter *csv.Writer
+	encoder   *json.Encoder
+	rows      int
+	isStarted bool
+}
+
+// NewExporter reads the format of the request, row is a zero value of the rows that will be written
+func NewExporter(context *gin.Context, fileName string, row interface{}) (*Exporter, error) {
+	format := context.DefaultQuery("format", ExportFormatCsv)
+	if format != ExportFormatCsv && format != ExportFormatNdjson {
+		return nil, errors.New("format must be " + ExportFormatCsv + " or " + ExportFormatNdjson)
+	}
+
+	exporter := &Exporter{
+		context:  context,
+		fileName: fileName,
+		format:   format,
+	}
+
+	rowType := reflect.TypeOf(row)
+	for index := 0; index < rowType.NumField(); index++ {
+		column := exportColumn(rowType.Field(index))
+		if column == "-" {
+			continue
+		}
+		exporter.columns = append(exporter.columns, column)
+		exporter.fields = append(exporter.fields, index)
+	}
+
+	return exporter, nil
+}
+
+// Write sends one row, an error means the client is gone and the export should stop
+func (e *Exporter) Write(row interface{}) error {
+	e.start()
+
+	var err error
+	if e.format == ExportFormatNdjson {
+		err = e.encoder.Encode(row)
+	} else {
+		value := reflect.ValueOf(row)
+		record := make([]string, 0, len(e.fields))
+		for _, index := range e.fields {
+			record = append(record, exportCsvCell(fmt.Sprint(value.Field(index).Interface())))
+		}
+		err = e.csvWriter.Write(record)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+
+	return nil
+}
+
+// Close sends what is left, an export without rows still gets its CSV header
+func (e *Exporter) Close() error {
+	e.start()
+
+	return e.flush()
+}
+
+func (e *Exporter) start() {
+	if e.isStarted {
+		return
+	}
+	e.isStarted = true
+
+	if e.format == ExportFormatNdjson {
+		e.context.Writer.Header().Add("Content-Type", "application/x-ndjson")
+		e.encoder = json.NewEncoder(e.context.Writer)
+	} else {
+		e.context.Writer.Header().Add("Content-Type", "text/csv")
+		e.csvWriter = csv.NewWriter(e.context.Writer)
+	}
+	e.context.Writer.Header().Add("Content-Disposition", `attachment; filename="`+e.fileName+"."+e.format+`"`)
+	e.context.Writer.Header().Add("Cache-Control", "no-store")
+	e.context.Status(200)
+
+	if e.csvWriter != nil {
+		e.csvWriter.Write(e.columns)
+	}
+}
+
+func (e *Exporter) flush() error {
+	if e.csvWriter != nil {
+		e.csvWriter.Flush()
+		if err := e.csvWriter.Error(); err != nil {
+			return err
+		}
+	}
+	e.context.Writer.Flush()
+
+	return nil
+}
+
+// exportCsvCell keeps a spreadsheet from reading a cell as a formula, a cell starting with a formula character is
+// prefixed with a quote so it is shown as text
+func exportCsvCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+
+	return cell
+}
+
+// exportColumn names a column by the json tag of the field
+func exportColumn(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
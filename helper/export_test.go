@@ -0,0 +1,110 @@
+package helper
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type exportTestRow struct {
+	Id     int    `json:"id"`
+	Name   string `json:"name"`
+	Secret string `json:"-"`
+	Note   string
+}
+
+func newExportTestContext(query string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request = httptest.NewRequest("GET", "/export"+query, nil)
+
+	return context, recorder
+}
+
+func TestExporter(t *testing.T) {
+	rows := []exportTestRow{
+		{Id: 1, Name: "Alice", Secret: "hidden", Note: "a, b"},
+		{Id: 2, Name: "=HYPERLINK(\"http://evil\")", Note: "+1"},
+		{Id: 3, Name: "-2+3", Note: "@SUM(A1)"},
+		{Id: 4, Name: "\tcmd", Note: "\rcmd"},
+		{Id: 5, Name: "a=b", Note: ""},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		rows        []exportTestRow
+		contentType string
+		body        string
+	}{
+		{
+			name:        "csv",
+			query:       "",
+			rows:        rows[:1],
+			contentType: "text/csv",
+			body:        "id,name,Note\n1,Alice,\"a, b\"\n",
+		},
+		{
+			name:        "csv formula cells",
+			query:       "?format=csv",
+			rows:        rows[1:],
+			contentType: "text/csv",
+			body: "id,name,Note\n" +
+				"2,\"'=HYPERLINK(\"\"http://evil\"\")\",'+1\n" +
+				"3,'-2+3,'@SUM(A1)\n" +
+				"4,'\tcmd,\"'\rcmd\"\n" +
+				"5,a=b,\n",
+		},
+		{
+			name:        "csv without rows",
+			query:       "?format=csv",
+			contentType: "text/csv",
+			body:        "id,name,Note\n",
+		},
+		{
+			name:        "ndjson",
+			query:       "?format=ndjson",
+			rows:        rows[:2],
+			contentType: "application/x-ndjson",
+			body: `{"id":1,"name":"Alice","Note":"a, b"}` + "\n" +
+				`{"id":2,"name":"=HYPERLINK(\"http://evil\")","Note":"+1"}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			context, recorder := newExportTestContext(test.query)
+
+			exporter, err := NewExporter(context, "rows", exportTestRow{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, row := range test.rows {
+				if err := exporter.Write(row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := exporter.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := recorder.Header().Get("Content-Type"); got != test.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, test.contentType)
+			}
+			if got := recorder.Body.String(); got != test.body {
+				t.Errorf("body = %q, want %q", got, test.body)
+			}
+		})
+	}
+}
+
+func TestNewExporterRejectsUnknownFormat(t *testing.T) {
+	context, _ := newExportTestContext("?format=xlsx")
+
+	_, err := NewExporter(context, "rows", exportTestRow{})
+	if err == nil {
+		t.Fatal("NewExporter accepted an unknown format")
+	}
+}
@@ -2,10 +2,9 @@ package helper
 
 import (
 	"collapp/module/user/model"
-	"io"
-	"os"
-	"path/filepath"
-	"time"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,7 +24,7 @@ func PayloadJwt(context *gin.Context) model.User {
 
 	user_name, ok := context.Get("user_name")
 	if ok {
-		payloadJwt.UserEmail = user_name.(string)
+		payloadJwt.UserName = user_name.(string)
 	}
 
 	user_lang_code, ok := context.Get("user_lang_code")
@@ -33,33 +32,66 @@ func PayloadJwt(context *gin.Context) model.User {
 		payloadJwt.UserLangCode = user_lang_code.(string)
 	}
 
-	return payloadJwt
-}
+	user_roles, ok := context.Get("user_roles")
+	if ok {
+		payloadJwt.UserRoles = user_roles.([]string)
+	}
+
+	session_id, ok := context.Get("session_id")
+	if ok {
+		payloadJwt.SessionId = session_id.(int)
+	}
+
+	// ActorId is who really acts and is recorded as the author of writes, the impersonating user while impersonating
+	payloadJwt.ActorId = payloadJwt.UserId
+	actor_id, ok := context.Get("actor_id")
+	if ok {
+		payloadJwt.ActorId = actor_id.(int)
+		payloadJwt.IsImpersonated = true
+	}
+
+	actor_name, ok := context.Get("actor_name")
+	if ok {
+		payloadJwt.ActorName = actor_name.(string)
+	}
 
-func UploadFile(context *gin.Context, requestName string, destination string) (string, error) {
-	currentTime := time.Now()
+	actor_session_id, ok := context.Get("actor_session_id")
+	if ok {
+		payloadJwt.ActorSessionId = actor_session_id.(int)
+	}
 
-	file, handler, err := context.Request.FormFile(requestName)
-	if err != nil {
-		IfError(err)
+	api_key_id, ok := context.Get("api_key_id")
+	if ok {
+		payloadJwt.ApiKeyId = api_key_id.(int)
 	}
-	defer file.Close()
 
-	var fileName = currentTime.Format("20060102150405") + handler.Filename
-	f, err := os.OpenFile(destination+fileName, os.O_WRONLY|os.O_CREATE, 0666)
-	if err != nil {
-		IfError(err)
+	api_key_scopes, ok := context.Get("api_key_scopes")
+	if ok {
+		payloadJwt.ApiKeyScopes = api_key_scopes.([]string)
 	}
-	defer f.Close()
 
-	_, err = io.Copy(f, file)
+	return payloadJwt
+}
 
-	return fileName, err
+// HashToken returns the sha256 hex digest of a token, tokens are only stored hashed
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Truncate cuts a string down to max characters so it fits its column
+func Truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) > max {
+		return string(runes[:max])
+	}
+	return value
 }
 
-func DeleteFile(fileName string, destination string) {
-	dir, _ := os.Getwd()
-	fileLocation := filepath.Join(dir, destination+fileName)
-	err := os.Remove(fileLocation)
+// GenerateRandomToken returns length random bytes hex encoded
+func GenerateRandomToken(length int) string {
+	b := make([]byte, length)
+	_, err := rand.Read(b)
 	IfError(err)
+	return hex.EncodeToString(b)
 }
@@ -0,0 +1,300 @@
+package helper
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	listDefaultLimit = 20
+	listMaxLimit     = 100
+)
+
+// ListSort is one column of the ORDER BY of a list query
+type ListSort struct {
+	Field  string
+	Column string
+	IsDesc bool
+}
+
+// ListQuery is the page, sort and filters of a list endpoint read from the query string:
+//
+//	?page=2&limit=20                            offset pagination
+//	?cursor=<next_cursor>&limit=20              keyset pagination, the cursor comes from the previous page
+//	?sort=-created_at,user_name                 sort fields, a leading "-" sorts descending
+//	?user_lang_code=en,id                       equality filter, a comma separated list matches any value
+//	?created_at_from=2022-10-01&created_at_to=2022-10-31
+//
+// Fields ending in _at are date ranges, a date without time in _to includes the whole day. Only the fields of the
+// endpoint are accepted and every value is bound as a query argument, so user input never becomes SQL.
+type ListQuery struct {
+	Page    int
+	Limit   int
+	Sorts   []ListSort
+	filters []string
+	args    []interface{}
+	cursor  []*string
+	sort    string
+}
+
+// Pagination is returned next to Data of a list response
+type Pagination struct {
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// NewListQuery reads the list query of the request. sorts and filters map the field names of the endpoint to their
+// columns and keyField is the unique sort field used by default and as the last sort to keep the order stable.
+func NewListQuery(context *gin.Context, sorts map[string]string, filters map[string]string, keyField string) (ListQuery, error) {
+	query := ListQuery{Page: 1, Limit: listDefaultLimit}
+
+	if limit := context.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > listMaxLimit {
+			return query, errors.New("limit must be between 1 and " + strconv.Itoa(listMaxLimit))
+		}
+		query.Limit = value
+	}
+
+	if page := context.Query("page"); page != "" {
+		value, err := strconv.Atoi(page)
+		if err != nil || value < 1 {
+			return query, errors.New("page must be a positive number")
+		}
+		query.Page = value
+	}
+
+	query.sort = context.Query("sort")
+	hasKey := false
+	for _, field := range strings.Split(query.sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		sort := ListSort{Field: strings.TrimPrefix(field, "-"), IsDesc: strings.HasPrefix(field, "-")}
+		column, ok := sorts[sort.Field]
+		if !ok {
+			return query, errors.New("unknown sort field " + sort.Field)
+		}
+		sort.Column = column
+		query.Sorts = append(query.Sorts, sort)
+
+		if sort.Field == keyField {
+			hasKey = true
+			break
+		}
+	}
+	if !hasKey {
+		query.Sorts = append(query.Sorts, ListSort{Field: keyField, Column: sorts[keyField]})
+	}
+
+	for field, column := range filters {
+		if strings.HasSuffix(field, "_at") {
+			if from := context.Query(field + "_from"); from != "" {
+				value, _, err := parseListDate(from)
+				if err != nil {
+					return query, errors.New(field + "_from must be a date")
+				}
+				query.filters = append(query.filters, column+" >= ?")
+				query.args = append(query.args, value.Format("2006-01-02 15:04:05"))
+			}
+			if to := context.Query(field + "_to"); to != "" {
+				value, isDate, err := parseListDate(to)
+				if err != nil {
+					return query, errors.New(field + "_to must be a date")
+				}
+				if isDate {
+					query.filters = append(query.filters, column+" < ?")
+					query.args = append(query.args, value.AddDate(0, 0, 1).Format("2006-01-02 15:04:05"))
+				} else {
+					query.filters = append(query.filters, column+" <= ?")
+					query.args = append(query.args, value.Format("2006-01-02 15:04:05"))
+				}
+			}
+			continue
+		}
+
+		if value := context.Query(field); value != "" {
+			values := strings.Split(value, ",")
+			query.filters = append(query.filters, column+" IN (?"+strings.Repeat(", ?", len(values)-1)+")")
+			for _, value := range values {
+				query.args = append(query.args, strings.TrimSpace(value))
+			}
+		}
+	}
+
+	if cursor := context.Query("cursor"); cursor != "" {
+		var values []*string
+		decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err == nil {
+			err = json.Unmarshal(decoded, &values)
+		}
+		if err != nil || len(values) != len(query.Sorts)+1 || values[0] == nil || *values[0] != query.sort {
+			return query, errors.New("cursor does not belong to this list or sort")
+		}
+		query.Page = 0
+		query.cursor = values[1:]
+	}
+
+	return query, nil
+}
+
+// Where returns the WHERE clause of the conditions and filters with its arguments, used to count the rows
+func (query ListQuery) Where(conditions ...string) (string, []interface{}) {
+	conditions = append(conditions, query.filters...)
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), append([]interface{}{}, query.args...)
+}
+
+// PageWhere is Where plus the position of the cursor, used to select the rows of the page
+func (query ListQuery) PageWhere(conditions ...string) (string, []interface{}) {
+	where, args := query.Where(conditions...)
+	if query.cursor == nil {
+		return where, args
+	}
+
+	// rows after the cursor: the first sort column is past the cursor value, or it is equal and the next one is past...
+	var after []string
+	var cursorArgs []interface{}
+	for index, sort := range query.Sorts {
+		var equal []string
+		for previousIndex, previous := range query.Sorts[:index] {
+			condition, arg := listEqual(previous.Column, query.cursor[previousIndex])
+			equal = append(equal, condition)
+			cursorArgs = append(cursorArgs, arg...)
+		}
+
+		condition, arg := listAfter(sort, query.cursor[index])
+		after = append(after, "("+strings.Join(append(equal, condition), " AND ")+")")
+		cursorArgs = append(cursorArgs, arg...)
+	}
+
+	condition := "(" + strings.Join(after, " OR ") + ")"
+	if where == "" {
+		return "WHERE " + condition, cursorArgs
+	}
+
+	return where + " AND " + condition, append(args, cursorArgs...)
+}
+
+// OrderBy returns the ORDER BY and LIMIT clauses, one row more than the limit is selected to know if more follow
+func (query ListQuery) OrderBy() (string, []interface{}) {
+	if query.cursor != nil {
+		return query.Sort() + " LIMIT ?", []interface{}{query.Limit + 1}
+	}
+
+	return query.Sort() + " LIMIT ? OFFSET ?", []interface{}{query.Limit + 1, (query.Page - 1) * query.Limit}
+}
+
+// Sort returns the ORDER BY clause alone, used by exports that read every row
+func (query ListQuery) Sort() string {
+	var orders []string
+	for _, sort := range query.Sorts {
+		if sort.IsDesc {
+			orders = append(orders, sort.Column+" DESC")
+		} else {
+			orders = append(orders, sort.Column+" ASC")
+		}
+	}
+
+	return "ORDER BY " + strings.Join(orders, ", ")
+}
+
+// CursorColumns returns the sort columns to add to the selected columns, they are scanned into CursorKeys
+func (query ListQuery) CursorColumns() string {
+	var columns string
+	for _, sort := range query.Sorts {
+		columns += ", " + sort.Column
+	}
+
+	return columns
+}
+
+// CursorKeys returns the scan destinations of CursorColumns for one row
+func (query ListQuery) CursorKeys() []interface{} {
+	keys := make([]interface{}, len(query.Sorts))
+	for index := range keys {
+		keys[index] = &sql.NullString{}
+	}
+
+	return keys
+}
+
+// Pagination describes the page, hasMore tells that a row followed the last one and lastKeys are its CursorKeys
+func (query ListQuery) Pagination(total int, hasMore bool, lastKeys []interface{}) Pagination {
+	pagination := Pagination{
+		Page:    query.Page,
+		Limit:   query.Limit,
+		Total:   total,
+		HasMore: hasMore,
+	}
+	if query.cursor == nil {
+		pagination.TotalPages = (total + query.Limit - 1) / query.Limit
+	}
+
+	if hasMore && lastKeys != nil {
+		values := []*string{&query.sort}
+		for _, key := range lastKeys {
+			value := key.(*sql.NullString)
+			if value.Valid {
+				values = append(values, &value.String)
+			} else {
+				values = append(values, nil)
+			}
+		}
+
+		encoded, err := json.Marshal(values)
+		IfError(err)
+		pagination.NextCursor = base64.RawURLEncoding.EncodeToString(encoded)
+	}
+
+	return pagination
+}
+
+// listEqual matches rows whose column equals the cursor value
+func listEqual(column string, value *string) (string, []interface{}) {
+	if value == nil {
+		return column + " IS NULL", nil
+	}
+
+	return column + " = ?", []interface{}{*value}
+}
+
+// listAfter matches rows that come after the cursor value in the sort, NULL sorts first as MySQL does
+func listAfter(sort ListSort, value *string) (string, []interface{}) {
+	switch {
+	case value == nil && sort.IsDesc:
+		return "FALSE", nil
+	case value == nil:
+		return sort.Column + " IS NOT NULL", nil
+	case sort.IsDesc:
+		return "(" + sort.Column + " < ? OR " + sort.Column + " IS NULL)", []interface{}{*value}
+	}
+
+	return sort.Column + " > ?", []interface{}{*value}
+}
+
+func parseListDate(value string) (time.Time, bool, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err == nil {
+		return date, true, nil
+	}
+
+	date, err = time.Parse("2006-01-02 15:04:05", value)
+	return date, false, err
+}
@@ -0,0 +1,280 @@
+package helper
+
+import (
+	"database/sql"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	listTestSorts = map[string]string{
+		"user_id":    "u.user_id",
+		"user_name":  "u.user_name",
+		"created_at": "u.user_created_at",
+	}
+	listTestFilters = map[string]string{
+		"user_lang_code": "u.user_lang_code",
+		"created_at":     "u.user_created_at",
+	}
+)
+
+func newListTestQuery(rawQuery string) (ListQuery, error) {
+	gin.SetMode(gin.TestMode)
+	context, _ := gin.CreateTestContext(httptest.NewRecorder())
+	context.Request = httptest.NewRequest("GET", "/users?"+rawQuery, nil)
+
+	return NewListQuery(context, listTestSorts, listTestFilters, "user_id")
+}
+
+func TestNewListQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		rawQuery  string
+		isInvalid bool
+		where     string
+		args      []interface{}
+		orderBy   string
+		orderArgs []interface{}
+	}{
+		{
+			name:      "defaults",
+			orderBy:   "ORDER BY u.user_id ASC LIMIT ? OFFSET ?",
+			orderArgs: []interface{}{21, 0},
+		},
+		{
+			name:      "page and limit",
+			rawQuery:  "page=3&limit=10",
+			orderBy:   "ORDER BY u.user_id ASC LIMIT ? OFFSET ?",
+			orderArgs: []interface{}{11, 20},
+		},
+		{
+			name:      "sort with the key appended",
+			rawQuery:  "sort=-created_at,user_name",
+			orderBy:   "ORDER BY u.user_created_at DESC, u.user_name ASC, u.user_id ASC LIMIT ? OFFSET ?",
+			orderArgs: []interface{}{21, 0},
+		},
+		{
+			name:      "sort fields after the key are dropped",
+			rawQuery:  "sort=-user_id,user_name",
+			orderBy:   "ORDER BY u.user_id DESC LIMIT ? OFFSET ?",
+			orderArgs: []interface{}{21, 0},
+		},
+		{
+			name:      "filter values",
+			rawQuery:  "user_lang_code=en,+id",
+			where:     "WHERE u.user_deleted_at IS NULL AND u.user_lang_code IN (?, ?)",
+			args:      []interface{}{"en", "id"},
+			orderBy:   "ORDER BY u.user_id ASC LIMIT ? OFFSET ?",
+			orderArgs: []interface{}{21, 0},
+		},
+		{
+			name:      "filter a value that looks like SQL",
+			rawQuery:  "user_lang_code=en')+OR+1=1--",
+			where:     "WHERE u.user_deleted_at IS NULL AND u.user_lang_code IN (?)",
+			args:      []interface{}{"en') OR 1=1--"},
+			orderBy:   "ORDER BY u.user_id ASC LIMIT ? OFFSET ?",
+			orderArgs: []interface{}{21, 0},
+		},
+		{
+			name:      "date range includes the whole last day",
+			rawQuery:  "created_at_from=2022-10-01&created_at_to=2022-10-31",
+			where:     "WHERE u.user_deleted_at IS NULL AND u.user_created_at >= ? AND u.user_created_at < ?",
+			args:      []interface{}{"2022-10-01 00:00:00", "2022-11-01 00:00:00"},
+			orderBy:   "ORDER BY u.user_id ASC LIMIT ? OFFSET ?",
+			orderArgs: []interface{}{21, 0},
+		},
+		{
+			name:      "date time range",
+			rawQuery:  "created_at_to=2022-10-31+12:30:00",
+			where:     "WHERE u.user_deleted_at IS NULL AND u.user_created_at <= ?",
+			args:      []interface{}{"2022-10-31 12:30:00"},
+			orderBy:   "ORDER BY u.user_id ASC LIMIT ? OFFSET ?",
+			orderArgs: []interface{}{21, 0},
+		},
+		{name: "limit too high", rawQuery: "limit=101", isInvalid: true},
+		{name: "limit zero", rawQuery: "limit=0", isInvalid: true},
+		{name: "page not a number", rawQuery: "page=x", isInvalid: true},
+		{name: "unknown sort field", rawQuery: "sort=user_password", isInvalid: true},
+		{name: "sort field that is SQL", rawQuery: "sort=user_id%3BDROP+TABLE+user", isInvalid: true},
+		{name: "invalid date", rawQuery: "created_at_from=yesterday", isInvalid: true},
+		{name: "invalid cursor", rawQuery: "cursor=abc", isInvalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, err := newListTestQuery(test.rawQuery)
+			if test.isInvalid {
+				if err == nil {
+					t.Fatal("NewListQuery accepted the query")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			where, args := query.PageWhere("u.user_deleted_at IS NULL")
+			if test.where == "" {
+				test.where = "WHERE u.user_deleted_at IS NULL"
+				test.args = []interface{}{}
+			}
+			if where != test.where || !reflect.DeepEqual(args, test.args) {
+				t.Errorf("PageWhere = %q %v, want %q %v", where, args, test.where, test.args)
+			}
+
+			orderBy, orderArgs := query.OrderBy()
+			if orderBy != test.orderBy || !reflect.DeepEqual(orderArgs, test.orderArgs) {
+				t.Errorf("OrderBy = %q %v, want %q %v", orderBy, orderArgs, test.orderBy, test.orderArgs)
+			}
+		})
+	}
+}
+
+// listTestKeys are the scanned CursorKeys of the last row of a page
+func listTestKeys(values ...*string) []interface{} {
+	keys := make([]interface{}, len(values))
+	for index, value := range values {
+		if value == nil {
+			keys[index] = &sql.NullString{}
+		} else {
+			keys[index] = &sql.NullString{String: *value, Valid: true}
+		}
+	}
+
+	return keys
+}
+
+func TestListQueryCursor(t *testing.T) {
+	name := "Alice"
+	createdAt := "2022-10-01 08:00:00"
+	userId := "7"
+
+	tests := []struct {
+		name     string
+		sort     string
+		lastKeys []interface{}
+		where    string
+		args     []interface{}
+	}{
+		{
+			name:     "key only",
+			lastKeys: listTestKeys(&userId),
+			where:    "WHERE u.user_deleted_at IS NULL AND u.user_lang_code IN (?) AND ((u.user_id > ?))",
+			args:     []interface{}{"en", "7"},
+		},
+		{
+			name:     "descending sort",
+			sort:     "-created_at",
+			lastKeys: listTestKeys(&createdAt, &userId),
+			where: "WHERE u.user_deleted_at IS NULL AND u.user_lang_code IN (?) AND (" +
+				"((u.user_created_at < ? OR u.user_created_at IS NULL)) OR " +
+				"(u.user_created_at = ? AND u.user_id > ?))",
+			args: []interface{}{"en", "2022-10-01 08:00:00", "2022-10-01 08:00:00", "7"},
+		},
+		{
+			name:     "ascending NULL value",
+			sort:     "user_name",
+			lastKeys: listTestKeys(nil, &userId),
+			where: "WHERE u.user_deleted_at IS NULL AND u.user_lang_code IN (?) AND (" +
+				"(u.user_name IS NOT NULL) OR " +
+				"(u.user_name IS NULL AND u.user_id > ?))",
+			args: []interface{}{"en", "7"},
+		},
+		{
+			name:     "descending NULL value",
+			sort:     "-user_name",
+			lastKeys: listTestKeys(nil, &userId),
+			where: "WHERE u.user_deleted_at IS NULL AND u.user_lang_code IN (?) AND (" +
+				"(FALSE) OR " +
+				"(u.user_name IS NULL AND u.user_id > ?))",
+			args: []interface{}{"en", "7"},
+		},
+		{
+			name:     "two sort fields",
+			sort:     "user_name,-created_at",
+			lastKeys: listTestKeys(&name, &createdAt, &userId),
+			where: "WHERE u.user_deleted_at IS NULL AND u.user_lang_code IN (?) AND (" +
+				"(u.user_name > ?) OR " +
+				"(u.user_name = ? AND (u.user_created_at < ? OR u.user_created_at IS NULL)) OR " +
+				"(u.user_name = ? AND u.user_created_at = ? AND u.user_id > ?))",
+			args: []interface{}{"en", "Alice", "Alice", "2022-10-01 08:00:00", "Alice", "2022-10-01 08:00:00", "7"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			firstPage, err := newListTestQuery("limit=5&user_lang_code=en&sort=" + test.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if keys := firstPage.CursorKeys(); len(keys) != len(test.lastKeys) {
+				t.Fatalf("CursorKeys has %d keys, want %d", len(keys), len(test.lastKeys))
+			}
+
+			pagination := firstPage.Pagination(12, true, test.lastKeys)
+			if pagination.NextCursor == "" || pagination.Page != 1 || pagination.TotalPages != 3 {
+				t.Fatalf("Pagination = %+v", pagination)
+			}
+
+			nextPage, err := newListTestQuery("limit=5&user_lang_code=en&sort=" + test.sort + "&cursor=" + pagination.NextCursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			where, args := nextPage.PageWhere("u.user_deleted_at IS NULL")
+			if where != test.where || !reflect.DeepEqual(args, test.args) {
+				t.Errorf("PageWhere = %q %v, want %q %v", where, args, test.where, test.args)
+			}
+
+			countWhere, countArgs := nextPage.Where("u.user_deleted_at IS NULL")
+			if countWhere != "WHERE u.user_deleted_at IS NULL AND u.user_lang_code IN (?)" || !reflect.DeepEqual(countArgs, []interface{}{"en"}) {
+				t.Errorf("Where = %q %v, the cursor must not limit the count", countWhere, countArgs)
+			}
+
+			orderBy, orderArgs := nextPage.OrderBy()
+			if orderBy != nextPage.Sort()+" LIMIT ?" || !reflect.DeepEqual(orderArgs, []interface{}{6}) {
+				t.Errorf("OrderBy = %q %v", orderBy, orderArgs)
+			}
+
+			nextPagination := nextPage.Pagination(12, false, nil)
+			if nextPagination.NextCursor != "" || nextPagination.Page != 0 || nextPagination.TotalPages != 0 {
+				t.Errorf("Pagination of the last page = %+v", nextPagination)
+			}
+		})
+	}
+}
+
+func TestListQueryCursorOfOtherSort(t *testing.T) {
+	createdAt := "2022-10-01 08:00:00"
+	userId := "7"
+
+	query, err := newListTestQuery("sort=-created_at")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor := query.Pagination(30, true, listTestKeys(&createdAt, &userId)).NextCursor
+
+	for _, rawQuery := range []string{"sort=created_at&cursor=" + cursor, "sort=-created_at,user_name&cursor=" + cursor, "cursor=" + cursor} {
+		if _, err := newListTestQuery(rawQuery); err == nil {
+			t.Errorf("NewListQuery accepted the cursor with %s", rawQuery)
+		}
+	}
+}
+
+func TestListQueryWhereWithoutConditions(t *testing.T) {
+	query, err := newListTestQuery("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	where, args := query.Where()
+	if where != "" || args != nil {
+		t.Errorf("Where = %q %v, want nothing", where, args)
+	}
+	if columns := query.CursorColumns(); columns != ", u.user_id" {
+		t.Errorf("CursorColumns = %q", columns)
+	}
+}
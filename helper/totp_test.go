@@ -0,0 +1,129 @@
+package helper
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// totpTestSecret is the SHA1 key of the RFC 6238 test vectors, the ASCII string "12345678901234567890"
+const totpTestSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTotpCode(t *testing.T) {
+	// RFC 6238 appendix B, the vectors are 8 digits long and a 6 digit code is their last 6 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, test := range tests {
+		t.Run(time.Unix(test.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			code, err := TotpCode(totpTestSecret, TotpStep(time.Unix(test.unix, 0)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code != test.code {
+				t.Errorf("TotpCode = %s, want %s", code, test.code)
+			}
+
+			lowerCode, err := TotpCode(strings.ToLower(totpTestSecret), TotpStep(time.Unix(test.unix, 0)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lowerCode != test.code {
+				t.Errorf("TotpCode of the lower case secret = %s, want %s", lowerCode, test.code)
+			}
+		})
+	}
+}
+
+func TestTotpCodeInvalidSecret(t *testing.T) {
+	_, err := TotpCode("not base32!", 1)
+	if err == nil {
+		t.Fatal("TotpCode accepted a secret that is not base32")
+	}
+}
+
+func TestValidateTotp(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TotpStep(now)
+
+	tests := []struct {
+		name    string
+		step    int64
+		isValid bool
+	}{
+		{name: "current step", step: current, isValid: true},
+		{name: "previous step", step: current - 1, isValid: true},
+		{name: "next step", step: current + 1, isValid: true},
+		{name: "two steps behind", step: current - 2},
+		{name: "two steps ahead", step: current + 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, err := TotpCode(totpTestSecret, test.step)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			step, isValid := ValidateTotp(totpTestSecret, code, now)
+			if isValid != test.isValid {
+				t.Fatalf("ValidateTotp = %v, want %v", isValid, test.isValid)
+			}
+			if isValid && step != test.step {
+				t.Errorf("ValidateTotp step = %d, want %d", step, test.step)
+			}
+		})
+	}
+
+	if _, isValid := ValidateTotp(totpTestSecret, "", now); isValid {
+		t.Error("ValidateTotp accepted an empty code")
+	}
+}
+
+func TestGenerateTotpSecret(t *testing.T) {
+	secret := GenerateTotpSecret()
+	if len(secret) != 32 {
+		t.Errorf("secret %s has %d characters, want 32", secret, len(secret))
+	}
+	if _, err := TotpCode(secret, 1); err != nil {
+		t.Errorf("generated secret does not decode: %v", err)
+	}
+	if GenerateTotpSecret() == secret {
+		t.Error("two generated secrets are equal")
+	}
+}
+
+func TestTotpUri(t *testing.T) {
+	uri := TotpUri("Coll App", "user@example.com", totpTestSecret)
+
+	parsedUri, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsedUri.Scheme != "otpauth" || parsedUri.Host != "totp" || parsedUri.Path != "/Coll App:user@example.com" {
+		t.Errorf("uri %s does not name the account", uri)
+	}
+
+	expected := map[string]string{
+		"secret":    totpTestSecret,
+		"issuer":    "Coll App",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	for name, value := range expected {
+		if got := parsedUri.Query().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
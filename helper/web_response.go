@@ -4,4 +4,6 @@ type WebResponse struct {
 	Code   int         `json:"code"`
 	Status string      `json:"status"`
 	Data   interface{} `json:"data"`
+	// Pagination is only set by list endpoints
+	Pagination *Pagination `json:"pagination,omitempty"`
 }
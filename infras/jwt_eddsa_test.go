@@ -0,0 +1,165 @@
+package infras
+
+import (
+	"collapp/configs"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestSigningMethodEdDSA(t *testing.T) {
+	// RFC 8037 appendix A.4, Ed25519 is deterministic so the signature must match the example exactly
+	seed, err := base64.RawURLEncoding.DecodeString("nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	if x := base64.RawURLEncoding.EncodeToString(publicKey); x != "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo" {
+		t.Fatalf("public key = %s", x)
+	}
+
+	signingString := "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc"
+	signature := "hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"
+
+	got, err := SigningMethodEdDSA.Sign(signingString, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != signature {
+		t.Errorf("Sign = %s, want %s", got, signature)
+	}
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		signingString string
+		signature     string
+		key           interface{}
+		isValid       bool
+	}{
+		{name: "valid", signingString: signingString, signature: signature, key: publicKey, isValid: true},
+		{name: "changed payload", signingString: signingString + "x", signature: signature, key: publicKey},
+		{name: "other key", signingString: signingString, signature: signature, key: otherKey.Public()},
+		{name: "private key", signingString: signingString, signature: signature, key: privateKey},
+		{name: "hmac secret", signingString: signingString, signature: signature, key: []byte("secret")},
+		{name: "bad encoding", signingString: signingString, signature: "not*base64", key: publicKey},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := SigningMethodEdDSA.Verify(test.signingString, test.signature, test.key)
+			if test.isValid != (err == nil) {
+				t.Errorf("Verify error = %v, want valid %v", err, test.isValid)
+			}
+		})
+	}
+
+	if _, err := SigningMethodEdDSA.Sign(signingString, publicKey); err != jwt.ErrInvalidKeyType {
+		t.Errorf("Sign with a public key error = %v, want %v", err, jwt.ErrInvalidKeyType)
+	}
+	if method := jwt.GetSigningMethod("EdDSA"); method != SigningMethodEdDSA {
+		t.Errorf("EdDSA is registered as %v", method)
+	}
+}
+
+func writeTestKey(t *testing.T, dir string, kid string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	blockType := "PRIVATE KEY"
+	if _, isPublic := key.(ed25519.PublicKey); isPublic {
+		der, err = x509.MarshalPKIXPublicKey(key)
+		blockType = "PUBLIC KEY"
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeySetEdDSA(t *testing.T) {
+	dir := t.TempDir()
+
+	_, currentKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, previousKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, unknownKey, _ := ed25519.GenerateKey(rand.Reader)
+	writeTestKey(t, dir, "current", currentKey)
+	writeTestKey(t, dir, "previous", previousKey.Public())
+
+	cfg := &configs.Config{}
+	cfg.JWT.Algorithm = "EdDSA"
+	cfg.JWT.KeysDir = dir
+	cfg.JWT.SigningKeyId = "current"
+	keySet := NewKeySet(cfg)
+
+	claims := jwt.StandardClaims{Subject: "1", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+	signTestToken := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		tokenString, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tokenString
+	}
+
+	signedToken, err := keySet.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		isValid bool
+	}{
+		{name: "signed by the set", token: signedToken, isValid: true},
+		{name: "rotated out key", token: signTestToken(SigningMethodEdDSA, "previous", previousKey), isValid: true},
+		{name: "unknown kid", token: signTestToken(SigningMethodEdDSA, "unknown", unknownKey)},
+		{name: "kid of another key", token: signTestToken(SigningMethodEdDSA, "current", unknownKey)},
+		{name: "other algorithm", token: signTestToken(jwt.SigningMethodHS256, "current", []byte("secret"))},
+		{name: "none algorithm", token: signTestToken(jwt.SigningMethodNone, "current", jwt.UnsafeAllowNoneSignatureType)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := jwt.ParseWithClaims(test.token, &jwt.StandardClaims{}, keySet.Keyfunc)
+			if test.isValid != (err == nil) {
+				t.Errorf("Parse error = %v, want valid %v", err, test.isValid)
+			}
+		})
+	}
+
+	if header := strings.Split(signedToken, ".")[0]; header != base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA","kid":"current","typ":"JWT"}`)) {
+		t.Errorf("token header = %s", header)
+	}
+
+	jwks := keySet.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "current" || jwks.Keys[1].Kid != "previous" {
+		t.Fatalf("JWKS = %+v", jwks)
+	}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.Alg != "EdDSA" || jwk.Use != "sig" || jwk.N != "" {
+			t.Errorf("JWK = %+v", jwk)
+		}
+	}
+	if jwks.Keys[0].X != base64.RawURLEncoding.EncodeToString(currentKey.Public().(ed25519.PublicKey)) {
+		t.Errorf("JWK of current publishes %s", jwks.Keys[0].X)
+	}
+}
@@ -0,0 +1,227 @@
+package infras
+
+import (
+	"collapp/configs"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// KeySet holds the JWT keys. New tokens are signed with the key named by JWT.SIGNING_KEY_ID and carry its kid,
+// tokens are verified with whichever key of the set their kid points at, so a rotated out key keeps verifying the
+// tokens it signed for as long as it stays in the set.
+type KeySet struct {
+	method     jwt.SigningMethod
+	signingKid string
+	signingKey interface{}
+	verifyKeys map[string]interface{}
+	kids       []string
+}
+
+// JSONWebKey is the public part of one key as published at /.well-known/jwks.json
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+var errUnknownKid = errors.New("unknown kid")
+
+// NewKeySet will load the keys of JWT.ALGORITHM. HS256 keeps using the JWT.KEY secret. RS256 and EdDSA load every
+// <kid>.pem file of JWT.KEYS_DIR plus the optional JWT.PRIVATE_KEY pem of JWT.SIGNING_KEY_ID, a private key signs and
+// verifies while a public key only verifies.
+func NewKeySet(cfg *configs.Config) *KeySet {
+	keySet := &KeySet{
+		verifyKeys: map[string]interface{}{},
+	}
+
+	switch cfg.JWT.Algorithm {
+	case "", jwt.SigningMethodHS256.Alg():
+		keySet.method = jwt.SigningMethodHS256
+		keySet.signingKey = []byte(cfg.JWT.Key)
+		keySet.verifyKeys[""] = keySet.signingKey
+		return keySet
+	case jwt.SigningMethodRS256.Alg():
+		keySet.method = jwt.SigningMethodRS256
+	case SigningMethodEdDSA.Alg():
+		keySet.method = SigningMethodEdDSA
+	default:
+		log.Fatalln("Unsupported JWT.ALGORITHM.", cfg.JWT.Algorithm)
+	}
+
+	keySet.signingKid = cfg.JWT.SigningKeyId
+
+	keyFiles := map[string][]byte{}
+	if cfg.JWT.KeysDir != "" {
+		files, err := filepath.Glob(filepath.Join(cfg.JWT.KeysDir, "*.pem"))
+		if err != nil {
+			log.Fatalln("Failed listing JWT.KEYS_DIR.", err)
+		}
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				log.Fatalln("Failed reading JWT key.", err)
+			}
+			keyFiles[strings.TrimSuffix(filepath.Base(file), ".pem")] = data
+		}
+	}
+	if cfg.JWT.PrivateKey != "" {
+		keyFiles[cfg.JWT.SigningKeyId] = []byte(cfg.JWT.PrivateKey)
+	}
+
+	for kid, data := range keyFiles {
+		key, err := parseKey(data)
+		if err != nil {
+			log.Fatalln("Failed parsing JWT key "+kid+".", err)
+		}
+
+		err = keySet.add(kid, key)
+		if err != nil {
+			log.Fatalln("Failed loading JWT key "+kid+".", err)
+		}
+	}
+
+	if keySet.signingKey == nil {
+		log.Fatalln("JWT.SIGNING_KEY_ID has no private key.", keySet.signingKid)
+	}
+
+	sort.Strings(keySet.kids)
+
+	return keySet
+}
+
+func (k *KeySet) add(kid string, key interface{}) error {
+	publicKey := key
+	isPrivate := true
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		publicKey = &key.PublicKey
+	case ed25519.PrivateKey:
+		publicKey = key.Public()
+	default:
+		isPrivate = false
+	}
+
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		if k.method != jwt.SigningMethodRS256 {
+			return fmt.Errorf("rsa key used with %s", k.method.Alg())
+		}
+	case ed25519.PublicKey:
+		if k.method != SigningMethodEdDSA {
+			return fmt.Errorf("ed25519 key used with %s", k.method.Alg())
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+
+	if kid == k.signingKid && isPrivate {
+		k.signingKey = key
+	}
+	k.verifyKeys[kid] = publicKey
+	k.kids = append(k.kids, kid)
+
+	return nil
+}
+
+// Algorithm is the only alg the set signs and accepts
+func (k *KeySet) Algorithm() string {
+	return k.method.Alg()
+}
+
+// Sign signs the claims with the current signing key
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.signingKid != "" {
+		token.Header["kid"] = k.signingKid
+	}
+
+	return token.SignedString(k.signingKey)
+}
+
+// Keyfunc is the jwt.Keyfunc of the set, it only accepts the configured algorithm and a known kid
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.verifyKeys[kid]
+	if !ok {
+		return nil, errUnknownKid
+	}
+
+	return key, nil
+}
+
+// JWKS lists the public keys of the set, a shared HS256 secret is never published
+func (k *KeySet) JWKS() JSONWebKeySet {
+	jwks := JSONWebKeySet{
+		Keys: []JSONWebKey{},
+	}
+
+	for _, kid := range k.kids {
+		jwk := JSONWebKey{
+			Use: "sig",
+			Alg: k.method.Alg(),
+			Kid: kid,
+		}
+
+		switch key := k.verifyKeys[kid].(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(key)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+func parseKey(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no pem block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	return nil, fmt.Errorf("unsupported pem block %s", block.Type)
+}
@@ -0,0 +1,93 @@
+package infras
+
+import (
+	"collapp/configs"
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Mailer sends plain text emails
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// NewMailer will build the mailer selected by MAIL.DRIVER, anything other than smtp falls back to the log sink
+func NewMailer(cfg *configs.Config) Mailer {
+	if cfg.Mail.Driver == "smtp" {
+		return &SMTPMailer{
+			Host: cfg.Mail.Host,
+			Port: cfg.Mail.Port,
+			User: cfg.Mail.User,
+			Pass: cfg.Mail.Pass,
+			From: cfg.Mail.From,
+		}
+	}
+
+	return &LogMailer{
+		Dir:  cfg.Mail.LogDir,
+		From: cfg.Mail.From,
+	}
+}
+
+// SMTPMailer delivers emails through an SMTP server
+type SMTPMailer struct {
+	Host string
+	Port int
+	User string
+	Pass string
+	From string
+}
+
+func (m *SMTPMailer) Send(to string, subject string, body string) error {
+	var auth smtp.Auth
+	if m.User != "" {
+		auth = smtp.PlainAuth("", m.User, m.Pass, m.Host)
+	}
+
+	address := fmt.Sprintf("%s:%d", m.Host, m.Port)
+	return smtp.SendMail(address, auth, m.From, []string{to}, buildMessage(m.From, to, subject, body))
+}
+
+// LogMailer is the development and test sink, every email is written as a file into Dir or to the log when Dir is empty
+type LogMailer struct {
+	Dir  string
+	From string
+}
+
+var unsafeFileName = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+func (m *LogMailer) Send(to string, subject string, body string) error {
+	message := buildMessage(m.From, to, subject, body)
+
+	if m.Dir == "" {
+		log.Printf("Mail to %s:\n%s", to, message)
+		return nil
+	}
+
+	err := os.MkdirAll(m.Dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	fileName := time.Now().Format("20060102150405.000000000") + "-" + unsafeFileName.ReplaceAllString(to, "_") + ".eml"
+	return os.WriteFile(filepath.Join(m.Dir, fileName), message, 0644)
+}
+
+func buildMessage(from string, to string, subject string, body string) []byte {
+	headers := []string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"UTF-8\"",
+	}
+
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + body)
+}
@@ -0,0 +1,378 @@
+package infras
+
+import (
+	"collapp/configs"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Oidc signs users in with the OpenID Connect providers of OIDC.PROVIDERS using the authorization code flow with
+// PKCE. Discovery documents and signing keys of a provider are fetched on first use and cached.
+type Oidc struct {
+	providers map[string]*OidcProvider
+}
+
+// OidcProvider is one configured issuer
+type OidcProvider struct {
+	Name   string
+	config configs.OidcProvider
+	client *http.Client
+
+	mutex        sync.Mutex
+	discovery    oidcDiscovery
+	keys         map[string]interface{}
+	keysLoadedAt time.Time
+}
+
+// OidcIdentity is what a verified ID token tells about the user
+type OidcIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+// oidcKeysReloadInterval is the least time between two loads of the keys of a provider, so tokens with made up kids
+// can not make every login wait for the provider
+const oidcKeysReloadInterval = time.Minute
+
+var (
+	ErrOidcUnknownProvider = errors.New("oidc: unknown provider")
+	errOidcUnknownKid      = errors.New("oidc: unknown kid")
+)
+
+// NewOidc will build the configured providers, nothing is fetched until a provider is used
+func NewOidc(cfg *configs.Config) *Oidc {
+	oidc := &Oidc{
+		providers: map[string]*OidcProvider{},
+	}
+
+	for name, providerConfig := range cfg.Oidc.Providers {
+		oidc.providers[strings.ToLower(name)] = &OidcProvider{
+			Name:   strings.ToLower(name),
+			config: providerConfig,
+			client: &http.Client{Timeout: 10 * time.Second},
+		}
+	}
+
+	return oidc
+}
+
+// Provider returns the provider of that name or ErrOidcUnknownProvider
+func (o *Oidc) Provider(name string) (*OidcProvider, error) {
+	provider, ok := o.providers[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrOidcUnknownProvider
+	}
+
+	return provider, nil
+}
+
+// AutoCreate tells whether an unknown verified email gets a new account
+func (p *OidcProvider) AutoCreate() bool {
+	return p.config.AutoCreate
+}
+
+// AuthorizationUrl is where the browser is sent to sign in, the code challenge is the S256 hash of codeVerifier
+func (p *OidcProvider) AuthorizationUrl(ctx context.Context, state string, nonce string, codeVerifier string) (string, error) {
+	discovery, err := p.loadDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := p.config.Scopes
+	if scopes == "" {
+		scopes = "openid email profile"
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientId)
+	query.Set("redirect_uri", p.config.RedirectUrl)
+	query.Set("scope", scopes)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the authorization code and verifies the returned ID token against the provider keys, the
+// issuer, the client id as audience, its lifetime and the nonce of the login
+func (p *OidcProvider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (OidcIdentity, error) {
+	discovery, err := p.loadDiscovery(ctx)
+	if err != nil {
+		return OidcIdentity{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectUrl)
+	form.Set("client_id", p.config.ClientId)
+	form.Set("code_verifier", codeVerifier)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return OidcIdentity{}, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(p.config.ClientId), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return OidcIdentity{}, err
+	}
+	defer response.Body.Close()
+
+	tokenResponse := struct {
+		IdToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&tokenResponse)
+	if err != nil {
+		return OidcIdentity{}, err
+	}
+	if response.StatusCode != http.StatusOK || tokenResponse.IdToken == "" {
+		return OidcIdentity{}, fmt.Errorf("oidc: token endpoint answered %d %s %s", response.StatusCode, tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+
+	return p.verifyIdToken(ctx, discovery, tokenResponse.IdToken, nonce)
+}
+
+func (p *OidcProvider) verifyIdToken(ctx context.Context, discovery oidcDiscovery, idToken string, nonce string) (OidcIdentity, error) {
+	claims := jwt.MapClaims{}
+	parser := &jwt.Parser{ValidMethods: []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}}
+	_, err := parser.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.findKey(ctx, discovery, kid)
+	})
+	if err != nil {
+		return OidcIdentity{}, err
+	}
+
+	issuer, _ := claims["iss"].(string)
+	if issuer != discovery.Issuer {
+		return OidcIdentity{}, errors.New("oidc: invalid issuer")
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return OidcIdentity{}, errors.New("oidc: expired id token")
+	}
+	if !audienceContains(claims["aud"], p.config.ClientId) {
+		return OidcIdentity{}, errors.New("oidc: invalid audience")
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != p.config.ClientId {
+		return OidcIdentity{}, errors.New("oidc: invalid authorized party")
+	}
+	tokenNonce, _ := claims["nonce"].(string)
+	if subtle.ConstantTimeCompare([]byte(tokenNonce), []byte(nonce)) != 1 {
+		return OidcIdentity{}, errors.New("oidc: invalid nonce")
+	}
+
+	identity := OidcIdentity{}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+	switch emailVerified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = emailVerified
+	case string:
+		identity.EmailVerified = emailVerified == "true"
+	}
+
+	if identity.Subject == "" {
+		return OidcIdentity{}, errors.New("oidc: missing subject")
+	}
+
+	return identity, nil
+}
+
+func audienceContains(audience interface{}, clientId string) bool {
+	switch audience := audience.(type) {
+	case string:
+		return audience == clientId
+	case []interface{}:
+		for _, value := range audience {
+			if value == clientId {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (p *OidcProvider) loadDiscovery(ctx context.Context) (oidcDiscovery, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.discovery.Issuer != "" {
+		return p.discovery, nil
+	}
+
+	discovery := oidcDiscovery{}
+	err := p.getJson(ctx, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", &discovery)
+	if err != nil {
+		return discovery, err
+	}
+	if discovery.Issuer != p.config.Issuer {
+		return oidcDiscovery{}, fmt.Errorf("oidc: discovery issuer %s does not match %s", discovery.Issuer, p.config.Issuer)
+	}
+
+	p.discovery = discovery
+	return discovery, nil
+}
+
+// findKey looks the kid up in the cached keys and reloads them when it is unknown, the provider may have rotated. Keys
+// are reloaded at most once per oidcKeysReloadInterval and without holding the lock, other logins go on meanwhile.
+func (p *OidcProvider) findKey(ctx context.Context, discovery oidcDiscovery, kid string) (interface{}, error) {
+	p.mutex.Lock()
+	key, ok := p.keys[kid]
+	keysLoadedAt := p.keysLoadedAt
+	isReload := !ok && time.Since(keysLoadedAt) >= oidcKeysReloadInterval
+	if isReload {
+		// claimed before loading, so concurrent logins with the same unknown kid do not load the keys as well
+		p.keysLoadedAt = time.Now()
+	}
+	p.mutex.Unlock()
+
+	if ok {
+		return key, nil
+	}
+	if !isReload {
+		return nil, errOidcUnknownKid
+	}
+
+	keys, err := p.loadKeys(ctx, discovery.JwksUri)
+	if err != nil {
+		// a failing provider is asked again by the next login
+		p.mutex.Lock()
+		p.keysLoadedAt = keysLoadedAt
+		p.mutex.Unlock()
+
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	p.keys = keys
+	p.mutex.Unlock()
+
+	key, ok = keys[kid]
+	if !ok {
+		return nil, errOidcUnknownKid
+	}
+
+	return key, nil
+}
+
+// loadKeys fetches the signing keys of the JWKS, keys of other uses or unsupported types are left out
+func (p *OidcProvider) loadKeys(ctx context.Context, jwksUri string) (map[string]interface{}, error) {
+	jwks := struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}{}
+	err := p.getJson(ctx, jwksUri, &jwks)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		switch jwk.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch jwk.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[jwk.Kid] = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+
+	return keys, nil
+}
+
+func (p *OidcProvider) getJson(ctx context.Context, address string, target interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: %s answered %d", address, response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(target)
+}
@@ -0,0 +1,262 @@
+package infras
+
+import (
+	"collapp/configs"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// testIssuer is a mock OpenID Connect provider, its token endpoint answers with the ID token of idToken
+type testIssuer struct {
+	server     *httptest.Server
+	key        *rsa.PrivateKey
+	jwksLoads  int32
+	idToken    func(issuer string) string
+	codeChecks func(form url.Values) bool
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := &testIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                issuer.server.URL,
+			AuthorizationEndpoint: issuer.server.URL + "/authorize",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			JwksUri:               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&issuer.jwksLoads, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if issuer.codeChecks != nil && !issuer.codeChecks(r.PostForm) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": issuer.idToken(issuer.server.URL)})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+func (i *testIssuer) provider(t *testing.T) *OidcProvider {
+	cfg := &configs.Config{}
+	cfg.Oidc.Providers = map[string]configs.OidcProvider{
+		"mock": {
+			Issuer:      i.server.URL,
+			ClientId:    "client-1",
+			RedirectUrl: "http://localhost/callback",
+		},
+	}
+
+	provider, err := NewOidc(cfg).Provider("mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return provider
+}
+
+func signTestIdToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tokenString
+}
+
+func TestOidcProviderAuthorizationUrl(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	authorizationUrl, err := issuer.provider(t).AuthorizationUrl(context.Background(), "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsedUrl, err := url.Parse(authorizationUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authorizationUrl, issuer.server.URL+"/authorize?") {
+		t.Errorf("authorization url %s is not the discovered endpoint", authorizationUrl)
+	}
+
+	challenge := sha256.Sum256([]byte("verifier-1"))
+	expected := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-1",
+		"redirect_uri":          "http://localhost/callback",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(challenge[:]),
+		"code_challenge_method": "S256",
+	}
+	for name, value := range expected {
+		if got := parsedUrl.Query().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestOidcProviderExchange(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validClaims := func(issuer string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            issuer,
+			"sub":            "subject-1",
+			"aud":            "client-1",
+			"exp":            time.Now().Add(time.Minute).Unix(),
+			"nonce":          "nonce-1",
+			"email":          "user@example.com",
+			"email_verified": true,
+			"name":           "User",
+		}
+	}
+
+	tests := []struct {
+		name       string
+		kid        string
+		isOtherKey bool
+		claims     func(claims jwt.MapClaims)
+		isValid    bool
+	}{
+		{name: "valid", kid: "key-1", isValid: true},
+		{name: "audience list", kid: "key-1", claims: func(claims jwt.MapClaims) { claims["aud"] = []string{"other", "client-1"} }, isValid: true},
+		{name: "wrong signature", kid: "key-1", isOtherKey: true},
+		{name: "unknown kid", kid: "key-2"},
+		{name: "wrong issuer", kid: "key-1", claims: func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }},
+		{name: "wrong audience", kid: "key-1", claims: func(claims jwt.MapClaims) { claims["aud"] = "client-2" }},
+		{name: "wrong authorized party", kid: "key-1", claims: func(claims jwt.MapClaims) { claims["azp"] = "client-2" }},
+		{name: "wrong nonce", kid: "key-1", claims: func(claims jwt.MapClaims) { claims["nonce"] = "nonce-2" }},
+		{name: "missing nonce", kid: "key-1", claims: func(claims jwt.MapClaims) { delete(claims, "nonce") }},
+		{name: "expired", kid: "key-1", claims: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{name: "missing subject", kid: "key-1", claims: func(claims jwt.MapClaims) { delete(claims, "sub") }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issuer := newTestIssuer(t)
+			issuer.codeChecks = func(form url.Values) bool {
+				return form.Get("code") == "code-1" && form.Get("code_verifier") == "verifier-1" && form.Get("grant_type") == "authorization_code"
+			}
+			issuer.idToken = func(issuerUrl string) string {
+				claims := validClaims(issuerUrl)
+				if test.claims != nil {
+					test.claims(claims)
+				}
+				key := issuer.key
+				if test.isOtherKey {
+					key = otherKey
+				}
+				return signTestIdToken(t, key, test.kid, claims)
+			}
+
+			identity, err := issuer.provider(t).Exchange(context.Background(), "code-1", "verifier-1", "nonce-1")
+			if test.isValid != (err == nil) {
+				t.Fatalf("Exchange error = %v, want valid %v", err, test.isValid)
+			}
+			if test.isValid && (identity.Subject != "subject-1" || identity.Email != "user@example.com" || !identity.EmailVerified || identity.Name != "User") {
+				t.Errorf("identity = %+v", identity)
+			}
+		})
+	}
+}
+
+func TestOidcProviderExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.codeChecks = func(form url.Values) bool {
+		return form.Get("code_verifier") == "verifier-1"
+	}
+	issuer.idToken = func(issuerUrl string) string {
+		return signTestIdToken(t, issuer.key, "key-1", jwt.MapClaims{"iss": issuerUrl, "sub": "subject-1", "aud": "client-1", "nonce": "nonce-1"})
+	}
+
+	_, err := issuer.provider(t).Exchange(context.Background(), "code-1", "verifier-2", "nonce-1")
+	if err == nil {
+		t.Fatal("Exchange accepted a wrong code verifier")
+	}
+}
+
+func TestOidcProviderDiscoveryIssuerMismatch(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := issuer.provider(t)
+	provider.config.Issuer = issuer.server.URL + "/"
+
+	_, err := provider.AuthorizationUrl(context.Background(), "state-1", "nonce-1", "verifier-1")
+	if err == nil {
+		t.Fatal("AuthorizationUrl accepted a discovery document of another issuer")
+	}
+}
+
+func TestOidcProviderFindKeyReloadInterval(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := issuer.provider(t)
+	discovery, err := provider.loadDiscovery(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = provider.findKey(context.Background(), discovery, "key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for attempt := 0; attempt < 5; attempt++ {
+		_, err = provider.findKey(context.Background(), discovery, "unknown")
+		if err != errOidcUnknownKid {
+			t.Fatalf("findKey error = %v, want %v", err, errOidcUnknownKid)
+		}
+	}
+	if loads := atomic.LoadInt32(&issuer.jwksLoads); loads != 1 {
+		t.Errorf("keys were loaded %d times, want 1", loads)
+	}
+
+	// past the interval an unknown kid reloads the keys, the provider may have rotated
+	provider.keysLoadedAt = time.Now().Add(-oidcKeysReloadInterval)
+	_, err = provider.findKey(context.Background(), discovery, "unknown")
+	if err != errOidcUnknownKid {
+		t.Fatalf("findKey error = %v, want %v", err, errOidcUnknownKid)
+	}
+	if loads := atomic.LoadInt32(&issuer.jwksLoads); loads != 2 {
+		t.Errorf("keys were loaded %d times, want 2", loads)
+	}
+}
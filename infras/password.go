@@ -0,0 +1,252 @@
+package infras
+
+import (
+	"bufio"
+	"collapp/configs"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Reasons a new password is refused, each one is also the translation key of the message
+const (
+	PasswordTooShort      = "password_too_short"
+	PasswordTooLong       = "password_too_long"
+	PasswordMissingUpper  = "password_missing_upper"
+	PasswordMissingLower  = "password_missing_lower"
+	PasswordMissingDigit  = "password_missing_digit"
+	PasswordMissingSymbol = "password_missing_symbol"
+	PasswordBreached      = "password_breached"
+	PasswordReused        = "password_reused"
+)
+
+// Hash algorithms of PASSWORD.HASH
+const (
+	PasswordHashArgon2id = "argon2id"
+	PasswordHashBcrypt   = "bcrypt"
+)
+
+const (
+	passwordMaxLength      = 128
+	argon2SaltLength       = 16
+	argon2KeyLength        = 32
+	generatedPasswordChars = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	generatedPasswordSigns = "!#$%&*+-=?@_"
+)
+
+// PasswordPolicy hashes passwords with PASSWORD.HASH and checks new passwords against the configured rules and the
+// optional breached password list of PASSWORD.BREACHED_LIST
+type PasswordPolicy struct {
+	config   configs.Password
+	breached map[string]bool
+}
+
+// NewPasswordPolicy will load the breached password list, one password or SHA-1 hex (optionally followed by
+// ":count" as in the Have I Been Pwned dumps) per line
+func NewPasswordPolicy(cfg *configs.Config) *PasswordPolicy {
+	passwordPolicy := &PasswordPolicy{
+		config:   cfg.Password,
+		breached: map[string]bool{},
+	}
+
+	switch passwordPolicy.config.Hash {
+	case "":
+		passwordPolicy.config.Hash = PasswordHashArgon2id
+	case PasswordHashArgon2id, PasswordHashBcrypt:
+	default:
+		log.Fatalln("Unsupported PASSWORD.HASH.", cfg.Password.Hash)
+	}
+	if passwordPolicy.config.BcryptCost == 0 {
+		passwordPolicy.config.BcryptCost = bcrypt.DefaultCost
+	}
+	if passwordPolicy.config.Argon2Memory == 0 {
+		passwordPolicy.config.Argon2Memory = 64 * 1024
+	}
+	if passwordPolicy.config.Argon2Time == 0 {
+		passwordPolicy.config.Argon2Time = 3
+	}
+	if passwordPolicy.config.Argon2Threads == 0 {
+		passwordPolicy.config.Argon2Threads = 2
+	}
+
+	if cfg.Password.BreachedList != "" {
+		file, err := os.Open(cfg.Password.BreachedList)
+		if err != nil {
+			log.Fatalln("Failed opening PASSWORD.BREACHED_LIST.", err)
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			hash := strings.SplitN(line, ":", 2)[0]
+			if _, err := hex.DecodeString(hash); err != nil || len(hash) != 2*sha1.Size {
+				hash = sha1Hex(line)
+			}
+			passwordPolicy.breached[strings.ToUpper(hash)] = true
+		}
+		if err := scanner.Err(); err != nil {
+			log.Fatalln("Failed reading PASSWORD.BREACHED_LIST.", err)
+		}
+	}
+
+	return passwordPolicy
+}
+
+// Validate returns the reason the password breaks the policy or an empty string, reuse is checked by the caller with
+// Verify against the previous hashes of the user
+func (p *PasswordPolicy) Validate(password string) string {
+	length := len([]rune(password))
+	switch {
+	case length < p.config.MinLength || length == 0:
+		return PasswordTooShort
+	case length > passwordMaxLength:
+		return PasswordTooLong
+	}
+
+	hasUpper, hasLower, hasDigit, hasSymbol := false, false, false, false
+	for _, character := range password {
+		switch {
+		case unicode.IsUpper(character):
+			hasUpper = true
+		case unicode.IsLower(character):
+			hasLower = true
+		case unicode.IsDigit(character):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+
+	switch {
+	case p.config.RequireUpper && !hasUpper:
+		return PasswordMissingUpper
+	case p.config.RequireLower && !hasLower:
+		return PasswordMissingLower
+	case p.config.RequireDigit && !hasDigit:
+		return PasswordMissingDigit
+	case p.config.RequireSymbol && !hasSymbol:
+		return PasswordMissingSymbol
+	case p.breached[sha1Hex(password)]:
+		return PasswordBreached
+	}
+
+	return ""
+}
+
+// History is how many previous passwords of a user a new one must differ from
+func (p *PasswordPolicy) History() int {
+	return p.config.History
+}
+
+// Hash hashes the password with the configured algorithm and cost
+func (p *PasswordPolicy) Hash(password string) (string, error) {
+	if p.config.Hash == PasswordHashBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), p.config.BcryptCost)
+		return string(hash), err
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.config.Argon2Time, p.config.Argon2Memory, p.config.Argon2Threads, argon2KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.config.Argon2Memory, p.config.Argon2Time,
+		p.config.Argon2Threads, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify checks the password against a bcrypt or argon2id hash. needsRehash tells that the hash was made with another
+// algorithm or other parameters than the configured ones, so it should be replaced while the password is at hand.
+func (p *PasswordPolicy) Verify(hash string, password string) (isValid bool, needsRehash bool) {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		var version int
+		var memory, time uint32
+		var threads uint8
+		parts := strings.Split(hash, "$")
+		if len(parts) != 6 {
+			return false, false
+		}
+		_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+		if err != nil || version != argon2.Version {
+			return false, false
+		}
+		_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads)
+		if err != nil {
+			return false, false
+		}
+		salt, errSalt := base64.RawStdEncoding.DecodeString(parts[4])
+		key, errKey := base64.RawStdEncoding.DecodeString(parts[5])
+		if errSalt != nil || errKey != nil || len(key) == 0 {
+			return false, false
+		}
+
+		otherKey := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+			return false, false
+		}
+
+		return true, p.config.Hash != PasswordHashArgon2id || memory != p.config.Argon2Memory || time != p.config.Argon2Time || threads != p.config.Argon2Threads
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err != nil {
+		return false, false
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	return true, p.config.Hash != PasswordHashBcrypt || err != nil || cost != p.config.BcryptCost
+}
+
+// Generate makes a random temporary password that passes the policy
+func (p *PasswordPolicy) Generate() (string, error) {
+	length := p.config.MinLength
+	if length < 16 {
+		length = 16
+	}
+
+	for attempt := 0; attempt < 100; attempt++ {
+		password := make([]byte, length)
+		for i := range password {
+			chars := generatedPasswordChars
+			if i%4 == 3 {
+				chars = generatedPasswordSigns
+			}
+
+			index, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+			if err != nil {
+				return "", err
+			}
+			password[i] = chars[index.Int64()]
+		}
+
+		if p.Validate(string(password)) == "" {
+			return string(password), nil
+		}
+	}
+
+	return "", errors.New("no generated password passes the policy")
+}
+
+func sha1Hex(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,197 @@
+package infras
+
+import (
+	"collapp/configs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestPasswordPolicy keeps the hashing parameters low so the tests stay fast
+func newTestPasswordPolicy(change func(cfg *configs.Config)) *PasswordPolicy {
+	cfg := &configs.Config{}
+	cfg.Password.BcryptCost = bcrypt.MinCost
+	cfg.Password.Argon2Memory = 1024
+	cfg.Password.Argon2Time = 1
+	cfg.Password.Argon2Threads = 1
+	if change != nil {
+		change(cfg)
+	}
+
+	return NewPasswordPolicy(cfg)
+}
+
+func TestPasswordPolicyHashVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		hash   func(cfg *configs.Config)
+		prefix string
+	}{
+		{name: "argon2id by default", prefix: "$argon2id$v=19$m=1024,t=1,p=1$"},
+		{name: "argon2id", hash: func(cfg *configs.Config) { cfg.Password.Hash = PasswordHashArgon2id }, prefix: "$argon2id$v=19$m=1024,t=1,p=1$"},
+		{name: "bcrypt", hash: func(cfg *configs.Config) { cfg.Password.Hash = PasswordHashBcrypt }, prefix: "$2a$04$"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			passwordPolicy := newTestPasswordPolicy(test.hash)
+
+			hash, err := passwordPolicy.Hash("correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(hash, test.prefix) {
+				t.Errorf("hash %s does not start with %s", hash, test.prefix)
+			}
+
+			otherHash, err := passwordPolicy.Hash("correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if otherHash == hash {
+				t.Error("two hashes of one password are equal, the salt is not random")
+			}
+
+			isValid, needsRehash := passwordPolicy.Verify(hash, "correct horse")
+			if !isValid || needsRehash {
+				t.Errorf("Verify = %v, %v, want true, false", isValid, needsRehash)
+			}
+
+			isValid, needsRehash = passwordPolicy.Verify(hash, "wrong horse")
+			if isValid || needsRehash {
+				t.Errorf("Verify of a wrong password = %v, %v, want false, false", isValid, needsRehash)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyNeedsRehash(t *testing.T) {
+	argon2idPolicy := newTestPasswordPolicy(nil)
+	argon2idHash, err := argon2idPolicy.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bcryptPolicy := newTestPasswordPolicy(func(cfg *configs.Config) { cfg.Password.Hash = PasswordHashBcrypt })
+	bcryptHash, err := bcryptPolicy.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		hash        string
+		config      func(cfg *configs.Config)
+		needsRehash bool
+	}{
+		{name: "argon2id unchanged", hash: argon2idHash},
+		{name: "argon2id other memory", hash: argon2idHash, config: func(cfg *configs.Config) { cfg.Password.Argon2Memory = 2048 }, needsRehash: true},
+		{name: "argon2id other time", hash: argon2idHash, config: func(cfg *configs.Config) { cfg.Password.Argon2Time = 2 }, needsRehash: true},
+		{name: "argon2id other threads", hash: argon2idHash, config: func(cfg *configs.Config) { cfg.Password.Argon2Threads = 2 }, needsRehash: true},
+		{name: "argon2id to bcrypt", hash: argon2idHash, config: func(cfg *configs.Config) { cfg.Password.Hash = PasswordHashBcrypt }, needsRehash: true},
+		{name: "bcrypt unchanged", hash: bcryptHash, config: func(cfg *configs.Config) { cfg.Password.Hash = PasswordHashBcrypt }},
+		{name: "bcrypt other cost", hash: bcryptHash, config: func(cfg *configs.Config) {
+			cfg.Password.Hash = PasswordHashBcrypt
+			cfg.Password.BcryptCost = bcrypt.MinCost + 1
+		}, needsRehash: true},
+		{name: "bcrypt to argon2id", hash: bcryptHash, needsRehash: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isValid, needsRehash := newTestPasswordPolicy(test.config).Verify(test.hash, "correct horse")
+			if !isValid {
+				t.Fatal("Verify rejected the password")
+			}
+			if needsRehash != test.needsRehash {
+				t.Errorf("needsRehash = %v, want %v", needsRehash, test.needsRehash)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyVerifyMalformedHash(t *testing.T) {
+	passwordPolicy := newTestPasswordPolicy(nil)
+	hash, err := passwordPolicy.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(hash, "$")
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "empty", hash: ""},
+		{name: "plain text", hash: "correct horse"},
+		{name: "missing key", hash: strings.Join(parts[:5], "$")},
+		{name: "other version", hash: strings.Replace(hash, "$v=19$", "$v=16$", 1)},
+		{name: "bad parameters", hash: strings.Replace(hash, "$m=1024,", "$m=x,", 1)},
+		{name: "bad salt", hash: strings.Join([]string{parts[0], parts[1], parts[2], parts[3], "!", parts[5]}, "$")},
+		{name: "empty key", hash: strings.Join([]string{parts[0], parts[1], parts[2], parts[3], parts[4], ""}, "$")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isValid, needsRehash := passwordPolicy.Verify(test.hash, "correct horse")
+			if isValid || needsRehash {
+				t.Errorf("Verify = %v, %v, want false, false", isValid, needsRehash)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	breachedList := filepath.Join(t.TempDir(), "breached.txt")
+	err := os.WriteFile(breachedList, []byte("Password123!\n"+sha1Hex("Summer2022!")+":42\n\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passwordPolicy := newTestPasswordPolicy(func(cfg *configs.Config) {
+		cfg.Password.MinLength = 10
+		cfg.Password.RequireUpper = true
+		cfg.Password.RequireLower = true
+		cfg.Password.RequireDigit = true
+		cfg.Password.RequireSymbol = true
+		cfg.Password.BreachedList = breachedList
+	})
+
+	tests := []struct {
+		password string
+		reason   string
+	}{
+		{password: "Correct-Horse-1", reason: ""},
+		{password: "Ünïcödé-Pässwörd-1", reason: ""},
+		{password: "", reason: PasswordTooShort},
+		{password: "Short-1", reason: PasswordTooShort},
+		{password: "Aa1-" + strings.Repeat("x", 125), reason: PasswordTooLong},
+		{password: "correct-horse-1", reason: PasswordMissingUpper},
+		{password: "CORRECT-HORSE-1", reason: PasswordMissingLower},
+		{password: "Correct-Horse-X", reason: PasswordMissingDigit},
+		{password: "CorrectHorse12", reason: PasswordMissingSymbol},
+		{password: "Password123!", reason: PasswordBreached},
+		{password: "Summer2022!", reason: PasswordBreached},
+	}
+
+	for _, test := range tests {
+		t.Run(test.password, func(t *testing.T) {
+			if reason := passwordPolicy.Validate(test.password); reason != test.reason {
+				t.Errorf("Validate = %q, want %q", reason, test.reason)
+			}
+		})
+	}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		password, err := passwordPolicy.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reason := passwordPolicy.Validate(password); reason != "" {
+			t.Errorf("generated password %s breaks the policy: %s", password, reason)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package infras
+
+import (
+	"bytes"
+	"collapp/configs"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrStorageNotFound is returned by Get when no file is stored under the key
+var ErrStorageNotFound = errors.New("storage: key not found")
+
+// ErrStorageInvalidKey is returned for keys that are empty, absolute or leave the storage with ".."
+var ErrStorageInvalidKey = errors.New("storage: invalid key")
+
+// Storage keeps uploaded files under slash separated keys, the key is what the database stores. Deleting a key that
+// holds nothing is not an error.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStorage will build the storage selected by STORAGE.DRIVER, anything other than s3 or memory falls back to the
+// local disk
+func NewStorage(cfg *configs.Config) Storage {
+	switch cfg.Storage.Driver {
+	case "s3":
+		return &S3Storage{
+			Endpoint:  cfg.Storage.S3Endpoint,
+			Region:    cfg.Storage.S3Region,
+			Bucket:    cfg.Storage.S3Bucket,
+			AccessKey: cfg.Storage.S3AccessKey,
+			SecretKey: cfg.Storage.S3SecretKey,
+			PathStyle: cfg.Storage.S3PathStyle,
+		}
+	case "memory":
+		return NewMemoryStorage()
+	}
+
+	return &LocalStorage{
+		Dir: cfg.Storage.Dir,
+	}
+}
+
+// LocalStorage keeps the files under Dir, it only works while every replica shares that directory
+type LocalStorage struct {
+	Dir string
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	fileName, err := s.fileName(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(fileName), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, body)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	fileName, err := s.fileName(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return nil, ErrStorageNotFound
+	}
+
+	return file, err
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	fileName, err := s.fileName(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(fileName)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *LocalStorage) fileName(key string) (string, error) {
+	if !validStorageKey(key) {
+		return "", ErrStorageInvalidKey
+	}
+
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+// MemoryStorage keeps the files in memory, for tests and local runs without a disk or bucket
+type MemoryStorage struct {
+	mutex sync.RWMutex
+	files map[string][]byte
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		files: map[string][]byte{},
+	}
+}
+
+func (s *MemoryStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if !validStorageKey(key) {
+		return ErrStorageInvalidKey
+	}
+
+	content, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.files[key] = content
+
+	return nil
+}
+
+func (s *MemoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	content, ok := s.files[key]
+	if !ok {
+		return nil, ErrStorageNotFound
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.files, key)
+
+	return nil
+}
+
+// validStorageKey accepts relative slash separated keys that stay inside the storage
+func validStorageKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+
+	return path.Clean(key) == key && key != "." && !strings.HasPrefix(key, "../") && key != ".."
+}
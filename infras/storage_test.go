@@ -0,0 +1,103 @@
+package infras
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestValidStorageKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		isValid bool
+	}{
+		{key: "photo.png", isValid: true},
+		{key: "avatars/1/photo.png", isValid: true},
+		{key: "avatars/1/my photo (1).png", isValid: true},
+		{key: "..photo.png", isValid: true},
+		{key: ""},
+		{key: "."},
+		{key: ".."},
+		{key: "../photo.png"},
+		{key: "avatars/../../photo.png"},
+		{key: "avatars/./photo.png"},
+		{key: "avatars//photo.png"},
+		{key: "avatars/"},
+		{key: "/etc/passwd"},
+		{key: "avatars\\photo.png"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			if isValid := validStorageKey(test.key); isValid != test.isValid {
+				t.Errorf("validStorageKey = %v, want %v", isValid, test.isValid)
+			}
+		})
+	}
+}
+
+func TestStorage(t *testing.T) {
+	storages := []struct {
+		name    string
+		storage Storage
+	}{
+		{name: "local", storage: &LocalStorage{Dir: t.TempDir()}},
+		{name: "memory", storage: NewMemoryStorage()},
+	}
+
+	for _, storage := range storages {
+		t.Run(storage.name, func(t *testing.T) {
+			testStorage(t, storage.storage)
+		})
+	}
+}
+
+// testStorage runs the contract of Storage against one implementation
+func testStorage(t *testing.T, storage Storage) {
+	ctx := context.Background()
+	key := "avatars/1/my photo (1)+ü.png"
+
+	_, err := storage.Get(ctx, key)
+	if err != ErrStorageNotFound {
+		t.Fatalf("Get of a missing key error = %v, want %v", err, ErrStorageNotFound)
+	}
+
+	for _, content := range []string{"first", "second"} {
+		err = storage.Put(ctx, key, strings.NewReader(content), int64(len(content)), "image/png")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := storage.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(body)
+		body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("Get = %q, want %q", got, content)
+		}
+	}
+
+	err = storage.Delete(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = storage.Get(ctx, key)
+	if err != ErrStorageNotFound {
+		t.Errorf("Get of a deleted key error = %v, want %v", err, ErrStorageNotFound)
+	}
+	err = storage.Delete(ctx, key)
+	if err != nil {
+		t.Errorf("Delete of a missing key error = %v", err)
+	}
+
+	err = storage.Put(ctx, "../photo.png", strings.NewReader("x"), 1, "image/png")
+	if err != ErrStorageInvalidKey {
+		t.Errorf("Put of an invalid key error = %v, want %v", err, ErrStorageInvalidKey)
+	}
+}
@@ -0,0 +1,62 @@
+CREATE TABLE IF NOT EXISTS role (
+    role_id INT NOT NULL AUTO_INCREMENT,
+    role_code VARCHAR(100) NOT NULL,
+    role_name VARCHAR(255) NOT NULL,
+    created_by INT NULL,
+    created_at DATETIME NULL,
+    updated_by INT NULL,
+    updated_at DATETIME NULL,
+    PRIMARY KEY (role_id),
+    UNIQUE KEY role_code_unique (role_code)
+);
+
+CREATE TABLE IF NOT EXISTS permission (
+    permission_id INT NOT NULL AUTO_INCREMENT,
+    permission_code VARCHAR(100) NOT NULL,
+    permission_name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (permission_id),
+    UNIQUE KEY permission_code_unique (permission_code)
+);
+
+CREATE TABLE IF NOT EXISTS role_permission (
+    rolepermission_role_id INT NOT NULL,
+    rolepermission_permission_id INT NOT NULL,
+    PRIMARY KEY (rolepermission_role_id, rolepermission_permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_role (
+    userrole_user_id INT NOT NULL,
+    userrole_role_id INT NOT NULL,
+    PRIMARY KEY (userrole_user_id, userrole_role_id)
+);
+
+INSERT INTO permission (permission_code, permission_name) VALUES
+    ('users.read', 'Read users'),
+    ('users.create', 'Create users'),
+    ('users.update', 'Update users'),
+    ('users.delete', 'Delete users'),
+    ('langs.read', 'Read languages'),
+    ('langs.create', 'Create languages'),
+    ('langs.update', 'Update languages'),
+    ('langs.delete', 'Delete languages'),
+    ('translations.read', 'Read translations'),
+    ('translations.create', 'Create translations'),
+    ('translations.update', 'Update translations'),
+    ('translations.delete', 'Delete translations'),
+    ('roles.read', 'Read roles and permissions'),
+    ('roles.manage', 'Manage roles and user role assignments');
+
+INSERT INTO role (role_code, role_name, created_at) VALUES
+    ('admin', 'Administrator', NOW()),
+    ('viewer', 'Viewer', NOW());
+
+INSERT INTO role_permission (rolepermission_role_id, rolepermission_permission_id)
+SELECT r.role_id, p.permission_id FROM role r CROSS JOIN permission p WHERE r.role_code = 'admin';
+
+INSERT INTO role_permission (rolepermission_role_id, rolepermission_permission_id)
+SELECT r.role_id, p.permission_id FROM role r CROSS JOIN permission p
+WHERE r.role_code = 'viewer' AND p.permission_code IN ('users.read', 'langs.read', 'translations.read', 'roles.read');
+
+-- existing accounts keep read access only, the one administrator is chosen with ADMIN_EMAIL when the server starts
+INSERT INTO user_role (userrole_user_id, userrole_role_id)
+SELECT u.user_id, r.role_id FROM user u CROSS JOIN role r WHERE r.role_code = 'viewer' AND u.deleted_at IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE user ADD COLUMN user_token TEXT NULL, ADD COLUMN user_token_refresh TEXT NULL;
+
+DROP TABLE IF EXISTS user_session;
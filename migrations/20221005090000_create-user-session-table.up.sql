@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS user_session (
+    session_id INT NOT NULL AUTO_INCREMENT,
+    session_user_id INT NOT NULL,
+    session_user_agent VARCHAR(255) NULL,
+    session_ip VARCHAR(45) NULL,
+    session_token CHAR(64) NULL,
+    session_token_refresh CHAR(64) NULL,
+    session_created_at DATETIME NOT NULL,
+    session_last_seen_at DATETIME NULL,
+    session_revoked_at DATETIME NULL,
+    PRIMARY KEY (session_id),
+    KEY session_user_id_index (session_user_id),
+    KEY session_token_refresh_index (session_token_refresh)
+);
+
+ALTER TABLE user DROP COLUMN user_token, DROP COLUMN user_token_refresh;
@@ -0,0 +1,4 @@
+DELETE a FROM role_permission a JOIN permission b ON b.permission_id = a.rolepermission_permission_id WHERE b.permission_code = 'users.reset_password';
+DELETE FROM permission WHERE permission_code = 'users.reset_password';
+
+ALTER TABLE user DROP COLUMN user_must_change_password;
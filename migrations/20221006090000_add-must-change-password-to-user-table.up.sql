@@ -0,0 +1,6 @@
+ALTER TABLE user ADD COLUMN user_must_change_password TINYINT(1) NOT NULL DEFAULT 0 AFTER user_password;
+
+INSERT INTO permission (permission_code, permission_name) VALUES ('users.reset_password', 'Reset user passwords');
+
+INSERT INTO role_permission (rolepermission_role_id, rolepermission_permission_id)
+SELECT r.role_id, p.permission_id FROM role r CROSS JOIN permission p WHERE r.role_code = 'admin' AND p.permission_code = 'users.reset_password';
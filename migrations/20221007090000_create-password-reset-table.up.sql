@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS password_reset (
+    reset_id INT NOT NULL AUTO_INCREMENT,
+    reset_user_id INT NOT NULL,
+    reset_token CHAR(64) NOT NULL,
+    reset_expired_at DATETIME NOT NULL,
+    reset_used_at DATETIME NULL,
+    reset_created_at DATETIME NOT NULL,
+    PRIMARY KEY (reset_id),
+    UNIQUE KEY reset_token_unique (reset_token),
+    KEY reset_user_id_index (reset_user_id)
+);
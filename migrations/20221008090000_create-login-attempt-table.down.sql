@@ -0,0 +1,4 @@
+DELETE a FROM role_permission a JOIN permission b ON b.permission_id = a.rolepermission_permission_id WHERE b.permission_code = 'users.unlock';
+DELETE FROM permission WHERE permission_code = 'users.unlock';
+
+DROP TABLE IF EXISTS login_attempt;
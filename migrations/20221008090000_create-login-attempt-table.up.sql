@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS login_attempt (
+    attempt_key_type VARCHAR(10) NOT NULL,
+    attempt_key VARCHAR(200) NOT NULL,
+    attempt_count INT NOT NULL DEFAULT 0,
+    attempt_last_at DATETIME NOT NULL,
+    attempt_locked_until DATETIME NULL,
+    PRIMARY KEY (attempt_key_type, attempt_key)
+);
+
+INSERT INTO permission (permission_code, permission_name) VALUES ('users.unlock', 'Unlock locked user logins');
+
+INSERT INTO role_permission (rolepermission_role_id, rolepermission_permission_id)
+SELECT r.role_id, p.permission_id FROM role r CROSS JOIN permission p WHERE r.role_code = 'admin' AND p.permission_code = 'users.unlock';
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS user_totp (
+    totp_user_id INT NOT NULL,
+    totp_secret VARCHAR(64) NOT NULL,
+    totp_last_step BIGINT NOT NULL DEFAULT 0,
+    totp_confirmed_at DATETIME NULL,
+    totp_created_at DATETIME NOT NULL,
+    PRIMARY KEY (totp_user_id)
+);
+
+CREATE TABLE IF NOT EXISTS recovery_code (
+    recovery_id INT NOT NULL AUTO_INCREMENT,
+    recovery_user_id INT NOT NULL,
+    recovery_code CHAR(64) NOT NULL,
+    recovery_used_at DATETIME NULL,
+    recovery_created_at DATETIME NOT NULL,
+    PRIMARY KEY (recovery_id),
+    KEY recovery_user_id_index (recovery_user_id)
+);
@@ -0,0 +1,6 @@
+DELETE a FROM role_permission a JOIN permission b ON b.permission_id = a.rolepermission_permission_id WHERE b.permission_code = 'service_accounts.manage';
+DELETE FROM permission WHERE permission_code = 'service_accounts.manage';
+
+DROP TABLE IF EXISTS api_key;
+
+ALTER TABLE user DROP COLUMN user_is_service_account;
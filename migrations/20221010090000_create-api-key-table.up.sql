@@ -0,0 +1,23 @@
+ALTER TABLE user ADD COLUMN user_is_service_account TINYINT(1) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS api_key (
+    apikey_id INT NOT NULL AUTO_INCREMENT,
+    apikey_user_id INT NOT NULL,
+    apikey_name VARCHAR(100) NOT NULL,
+    apikey_prefix VARCHAR(20) NOT NULL,
+    apikey_key CHAR(64) NOT NULL,
+    apikey_scopes VARCHAR(2000) NOT NULL,
+    apikey_expired_at DATETIME NULL,
+    apikey_last_used_at DATETIME NULL,
+    apikey_revoked_at DATETIME NULL,
+    created_by INT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (apikey_id),
+    UNIQUE KEY apikey_key_unique (apikey_key),
+    KEY apikey_user_id_index (apikey_user_id)
+);
+
+INSERT INTO permission (permission_code, permission_name) VALUES ('service_accounts.manage', 'Manage service accounts and their API keys');
+
+INSERT INTO role_permission (rolepermission_role_id, rolepermission_permission_id)
+SELECT r.role_id, p.permission_id FROM role r CROSS JOIN permission p WHERE r.role_code = 'admin' AND p.permission_code = 'service_accounts.manage';
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS oidc_state (
+    state_id INT NOT NULL AUTO_INCREMENT,
+    state_key CHAR(64) NOT NULL,
+    state_provider VARCHAR(50) NOT NULL,
+    state_user_id INT NOT NULL DEFAULT 0,
+    state_nonce VARCHAR(100) NOT NULL,
+    state_code_verifier VARCHAR(128) NOT NULL,
+    state_expired_at DATETIME NOT NULL,
+    state_created_at DATETIME NOT NULL,
+    PRIMARY KEY (state_id),
+    UNIQUE KEY state_key_unique (state_key)
+);
+
+CREATE TABLE IF NOT EXISTS user_identity (
+    identity_id INT NOT NULL AUTO_INCREMENT,
+    identity_user_id INT NOT NULL,
+    identity_provider VARCHAR(50) NOT NULL,
+    identity_subject VARCHAR(255) NOT NULL,
+    identity_email VARCHAR(200) NULL,
+    identity_created_at DATETIME NOT NULL,
+    PRIMARY KEY (identity_id),
+    UNIQUE KEY identity_provider_subject_unique (identity_provider, identity_subject),
+    KEY identity_user_id_index (identity_user_id)
+);
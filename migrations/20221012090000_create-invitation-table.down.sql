@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS invitation;
+
+ALTER TABLE user DROP COLUMN user_status;
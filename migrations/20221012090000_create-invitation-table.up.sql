@@ -0,0 +1,15 @@
+ALTER TABLE user ADD COLUMN user_status VARCHAR(20) NOT NULL DEFAULT 'active';
+
+CREATE TABLE IF NOT EXISTS invitation (
+    invitation_id INT NOT NULL AUTO_INCREMENT,
+    invitation_user_id INT NOT NULL,
+    invitation_token CHAR(64) NOT NULL,
+    invitation_expired_at DATETIME NOT NULL,
+    invitation_accepted_at DATETIME NULL,
+    invitation_revoked_at DATETIME NULL,
+    created_by INT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (invitation_id),
+    UNIQUE KEY invitation_token_unique (invitation_token),
+    KEY invitation_user_id_index (invitation_user_id)
+);
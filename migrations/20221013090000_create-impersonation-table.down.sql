@@ -0,0 +1,5 @@
+DELETE a FROM role_permission a JOIN permission b ON b.permission_id = a.rolepermission_permission_id WHERE b.permission_code = 'users.impersonate';
+DELETE FROM permission WHERE permission_code = 'users.impersonate';
+
+DROP TABLE IF EXISTS impersonation_action;
+DROP TABLE IF EXISTS impersonation;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS impersonation (
+    impersonation_id INT NOT NULL AUTO_INCREMENT,
+    impersonation_actor_id INT NOT NULL,
+    impersonation_user_id INT NOT NULL,
+    impersonation_session_id INT NOT NULL,
+    impersonation_reason VARCHAR(255) NOT NULL DEFAULT '',
+    impersonation_started_at DATETIME NOT NULL,
+    impersonation_ended_at DATETIME NULL,
+    PRIMARY KEY (impersonation_id),
+    UNIQUE KEY impersonation_session_id_unique (impersonation_session_id),
+    KEY impersonation_actor_id_index (impersonation_actor_id),
+    KEY impersonation_user_id_index (impersonation_user_id)
+);
+
+CREATE TABLE IF NOT EXISTS impersonation_action (
+    action_id INT NOT NULL AUTO_INCREMENT,
+    action_impersonation_id INT NOT NULL,
+    action_method VARCHAR(10) NOT NULL,
+    action_path VARCHAR(255) NOT NULL,
+    action_created_at DATETIME NOT NULL,
+    PRIMARY KEY (action_id),
+    KEY action_impersonation_id_index (action_impersonation_id)
+);
+
+INSERT INTO permission (permission_code, permission_name) VALUES ('users.impersonate', 'Sign in as another user');
+
+INSERT INTO role_permission (rolepermission_role_id, rolepermission_permission_id)
+SELECT r.role_id, p.permission_id FROM role r CROSS JOIN permission p WHERE r.role_code = 'admin' AND p.permission_code = 'users.impersonate';
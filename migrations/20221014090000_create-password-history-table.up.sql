@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS password_history (
+    history_id INT NOT NULL AUTO_INCREMENT,
+    history_user_id INT NOT NULL,
+    history_password VARCHAR(255) NOT NULL,
+    history_created_at DATETIME NOT NULL,
+    PRIMARY KEY (history_id),
+    KEY history_user_id_index (history_user_id)
+);
@@ -0,0 +1,2 @@
+DELETE a FROM role_permission a JOIN permission b ON b.permission_id = a.rolepermission_permission_id WHERE b.permission_code = 'tokens.introspect';
+DELETE FROM permission WHERE permission_code = 'tokens.introspect';
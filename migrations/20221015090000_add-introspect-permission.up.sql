@@ -0,0 +1,4 @@
+INSERT INTO permission (permission_code, permission_name) VALUES ('tokens.introspect', 'Introspect tokens issued to users');
+
+INSERT INTO role_permission (rolepermission_role_id, rolepermission_permission_id)
+SELECT r.role_id, p.permission_id FROM role r CROSS JOIN permission p WHERE r.role_code = 'admin' AND p.permission_code = 'tokens.introspect';
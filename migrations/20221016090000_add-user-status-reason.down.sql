@@ -0,0 +1,7 @@
+DELETE a FROM role_permission a JOIN permission b ON b.permission_id = a.rolepermission_permission_id WHERE b.permission_code = 'users.status';
+DELETE FROM permission WHERE permission_code = 'users.status';
+
+UPDATE user SET user_status = 'active' WHERE user_status IN ('suspended', 'disabled');
+
+ALTER TABLE user DROP COLUMN user_suspended_until;
+ALTER TABLE user DROP COLUMN user_status_reason;
@@ -0,0 +1,7 @@
+ALTER TABLE user ADD COLUMN user_status_reason VARCHAR(255) NULL;
+ALTER TABLE user ADD COLUMN user_suspended_until DATETIME NULL;
+
+INSERT INTO permission (permission_code, permission_name) VALUES ('users.status', 'Suspend, disable and reactivate users');
+
+INSERT INTO role_permission (rolepermission_role_id, rolepermission_permission_id)
+SELECT r.role_id, p.permission_id FROM role r CROSS JOIN permission p WHERE r.role_code = 'admin' AND p.permission_code = 'users.status';
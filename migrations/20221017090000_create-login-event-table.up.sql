@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS login_event (
+    event_id INT NOT NULL AUTO_INCREMENT,
+    event_user_id INT NULL,
+    event_email VARCHAR(200) NOT NULL DEFAULT '',
+    event_type VARCHAR(20) NOT NULL,
+    event_is_success TINYINT(1) NOT NULL,
+    event_reason VARCHAR(50) NOT NULL DEFAULT '',
+    event_ip VARCHAR(45) NULL,
+    event_user_agent VARCHAR(255) NULL,
+    event_created_at DATETIME NOT NULL,
+    PRIMARY KEY (event_id),
+    KEY event_user_id_index (event_user_id, event_id)
+);
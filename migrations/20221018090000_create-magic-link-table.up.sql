@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS magic_link (
+    link_id INT NOT NULL AUTO_INCREMENT,
+    link_user_id INT NOT NULL,
+    link_token CHAR(64) NOT NULL,
+    link_expired_at DATETIME NOT NULL,
+    link_used_at DATETIME NULL,
+    link_created_at DATETIME NOT NULL,
+    PRIMARY KEY (link_id),
+    UNIQUE KEY link_token_unique (link_token),
+    KEY link_user_id_index (link_user_id, link_created_at)
+);
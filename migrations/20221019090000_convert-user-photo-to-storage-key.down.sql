@@ -0,0 +1 @@
+UPDATE user SET user_photo = SUBSTRING(user_photo, 12) WHERE user_photo LIKE 'user/photo/%';
@@ -0,0 +1,2 @@
+-- photos were stored as file names inside FILES.PHOTO, they become storage keys under the default FILES.PHOTO prefix
+UPDATE user SET user_photo = CONCAT('user/photo/', user_photo) WHERE user_photo IS NOT NULL AND user_photo <> '' AND user_photo NOT LIKE '%/%';
@@ -7,6 +7,7 @@ import (
 	"collapp/module/lang/service"
 	translationService "collapp/module/translation/service"
 	"database/sql"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -38,7 +39,7 @@ func (h *LangHandler) Create(context *gin.Context) {
 	langCreateRequest := model.LangCreateRequest{}
 	context.Bind(&langCreateRequest)
 
-	langCreateRequest.CreatedBy = payloadJwt.UserId
+	langCreateRequest.CreatedBy = payloadJwt.ActorId
 
 	currentTime := time.Now()
 	langCreateRequest.CreatedAt = currentTime.Format("2006-01-02 15:04:05")
@@ -73,7 +74,7 @@ func (h *LangHandler) Update(context *gin.Context) {
 	langUpdateRequest := model.LangUpdateRequest{}
 	context.Bind(&langUpdateRequest)
 
-	langUpdateRequest.UpdatedBy = payloadJwt.UserId
+	langUpdateRequest.UpdatedBy = payloadJwt.ActorId
 
 	currentTime := time.Now()
 	langUpdateRequest.UpdatedAt = currentTime.Format("2006-01-02 15:04:05")
@@ -182,25 +183,72 @@ func (h *LangHandler) FindById(context *gin.Context) {
 func (h *LangHandler) FindAll(context *gin.Context) {
 	payloadJwt := helper.PayloadJwt(context)
 
-	langResponses := h.LangService.FindAll(context)
+	listQuery, err := helper.NewListQuery(context, model.LangListSorts, model.LangListFilters, "lang_id")
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	langResponses, pagination := h.LangService.FindAll(context, listQuery)
 
 	if len(langResponses) > 0 {
 		webResponse := helper.WebResponse{
-			Code:   200,
-			Status: h.TranslationService.Translation(context, "success_get_language", payloadJwt.UserLangCode),
-			Data:   langResponses,
+			Code:       200,
+			Status:     h.TranslationService.Translation(context, "success_get_language", payloadJwt.UserLangCode),
+			Data:       langResponses,
+			Pagination: &pagination,
 		}
 
 		context.Writer.Header().Add("Content-Type", "application/json")
 		context.JSON(200, webResponse)
 	} else {
 		webResponse := helper.WebResponse{
-			Code:   http.StatusNotFound,
-			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
-			Data:   nil,
+			Code:       http.StatusNotFound,
+			Status:     h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:       nil,
+			Pagination: &pagination,
 		}
 
 		context.Writer.Header().Add("Content-Type", "application/json")
 		context.JSON(http.StatusNotFound, webResponse)
 	}
 }
+
+// Export streams the languages matching the filters of FindAll as CSV or NDJSON, chosen with ?format=
+func (h *LangHandler) Export(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	listQuery, err := helper.NewListQuery(context, model.LangListSorts, model.LangListFilters, "lang_id")
+	var exporter *helper.Exporter
+	if err == nil {
+		exporter, err = helper.NewExporter(context, "langs", model.LangResponse{})
+	}
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	err = h.LangService.Export(context, listQuery, func(lang model.LangResponse) error {
+		return exporter.Write(lang)
+	})
+	if err == nil {
+		err = exporter.Close()
+	}
+	if err != nil {
+		log.Println("Export of langs stopped.", err)
+	}
+}
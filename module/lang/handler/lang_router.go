@@ -10,10 +10,11 @@ func (h *LangHandler) Router(router *gin.RouterGroup, auth middleware.AuthMiddle
 	lang := router.Group("/lang")
 	lang.Use(auth.Auth())
 	{
-		lang.GET("/", h.FindAll)
-		lang.GET("/:langId", h.FindById)
-		lang.POST("/", h.Create)
-		lang.PUT("/:langId", h.Update)
-		lang.DELETE("/:langId", h.Delete)
+		lang.GET("/", auth.RequirePermission("langs.read"), h.FindAll)
+		lang.GET("/export", auth.RequirePermission("langs.read"), h.Export)
+		lang.GET("/:langId", auth.RequirePermission("langs.read"), h.FindById)
+		lang.POST("/", auth.RequirePermission("langs.create"), h.Create)
+		lang.PUT("/:langId", auth.RequirePermission("langs.update"), h.Update)
+		lang.DELETE("/:langId", auth.RequirePermission("langs.delete"), h.Delete)
 	}
 }
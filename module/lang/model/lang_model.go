@@ -2,6 +2,21 @@ package model
 
 import "database/sql"
 
+// LangListSorts and LangListFilters are the fields the language list may be sorted and filtered by, with their column
+var LangListSorts = map[string]string{
+	"lang_id":    "lang_id",
+	"lang_code":  "lang_code",
+	"lang_name":  "lang_name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+var LangListFilters = map[string]string{
+	"lang_code":  "lang_code",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
 // model Language
 type Lang struct {
 	LangId         int
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"collapp/helper"
 	"collapp/module/lang/model"
 	"context"
 	"database/sql"
@@ -11,5 +12,7 @@ type LangRepository interface {
 	Update(ctx context.Context, tx *sql.Tx, lang model.LangUpdateRequest) model.Lang
 	Delete(ctx context.Context, tx *sql.Tx, lang model.Lang)
 	FindById(ctx context.Context, tx *sql.Tx, langId int) (model.Lang, error)
-	FindAll(ctx context.Context, tx *sql.Tx) []model.Lang
+	FindAll(ctx context.Context, tx *sql.Tx, query helper.ListQuery) ([]model.Lang, helper.Pagination)
+	Export(ctx context.Context, tx *sql.Tx, query helper.ListQuery, write func(lang model.Lang) error) error
+	CheckLangCodeExist(ctx context.Context, tx *sql.Tx, langCode string) bool
 }
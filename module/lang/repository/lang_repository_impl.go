@@ -121,22 +121,95 @@ func (repository *LangRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx,
 	return lang, nil
 }
 
-func (repository *LangRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) []model.Lang {
+func (repository *LangRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx, query helper.ListQuery) ([]model.Lang, helper.Pagination) {
+	where, args := query.Where()
 	SQL := `SELECT 
+				COUNT(*) 
+			FROM 
+				lang 
+			` + where
+	var total int
+	err := tx.QueryRowContext(ctx, SQL, args...).Scan(&total)
+	helper.IfError(err)
+
+	where, args = query.PageWhere()
+	orderBy, orderArgs := query.OrderBy()
+	SQL = `SELECT 
 				lang_id, 
 				lang_code, 
 				lang_name, 
 				created_by,
 				created_at, 
 				updated_by, 
-				updated_at 
+				updated_at` + query.CursorColumns() + `
 			FROM 
-				lang`
-	rows, err := tx.QueryContext(ctx, SQL)
+				lang 
+			` + where + `
+			` + orderBy
+	rows, err := tx.QueryContext(ctx, SQL, append(args, orderArgs...)...)
 	helper.IfError(err)
 	defer rows.Close()
 
 	var langs []model.Lang
+	var lastKeys []interface{}
+	hasMore := false
+	for rows.Next() {
+		if len(langs) == query.Limit {
+			hasMore = true
+			break
+		}
+
+		lang := model.Lang{}
+		keys := query.CursorKeys()
+		err := rows.Scan(append([]interface{}{
+			&lang.LangId,
+			&lang.LangCode,
+			&lang.LangName,
+			&lang.CreatedByCheck,
+			&lang.CreatedAtCheck,
+			&lang.UpdatedByCheck,
+			&lang.UpdatedAtCheck}, keys...)...)
+		helper.IfError(err)
+
+		if lang.CreatedByCheck.Valid {
+			lang.CreatedBy = int(lang.CreatedByCheck.Int32)
+		}
+		if lang.CreatedAtCheck.Valid {
+			lang.CreatedAt = lang.CreatedAtCheck.String
+		}
+		if lang.UpdatedByCheck.Valid {
+			lang.UpdatedBy = int(lang.UpdatedByCheck.Int32)
+		}
+		if lang.UpdatedAtCheck.Valid {
+			lang.UpdatedAt = lang.UpdatedAtCheck.String
+		}
+
+		langs = append(langs, lang)
+		lastKeys = keys
+	}
+
+	return langs, query.Pagination(total, hasMore, lastKeys)
+}
+
+// Export passes the languages of the query to write one by one as they are read, it stops at the first error of write
+func (repository *LangRepositoryImpl) Export(ctx context.Context, tx *sql.Tx, query helper.ListQuery, write func(lang model.Lang) error) error {
+	where, args := query.Where()
+	SQL := `SELECT 
+				lang_id, 
+				lang_code, 
+				lang_name, 
+				created_by,
+				created_at, 
+				updated_by, 
+				updated_at
+			FROM 
+				lang 
+			` + where + `
+			` + query.Sort()
+	rows, err := tx.QueryContext(ctx, SQL, args...)
+	helper.IfError(err)
+	defer rows.Close()
+
 	for rows.Next() {
 		lang := model.Lang{}
 		err := rows.Scan(
@@ -162,8 +235,29 @@ func (repository *LangRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) [
 			lang.UpdatedAt = lang.UpdatedAtCheck.String
 		}
 
-		langs = append(langs, lang)
+		err = write(lang)
+		if err != nil {
+			return err
+		}
 	}
 
-	return langs
+	return rows.Err()
+}
+
+func (repository *LangRepositoryImpl) CheckLangCodeExist(ctx context.Context, tx *sql.Tx, langCode string) bool {
+	SQL := `SELECT
+				lang_id
+			FROM
+				lang
+			WHERE
+				lang_code = ?`
+	rows, err := tx.QueryContext(ctx, SQL, langCode)
+	helper.IfError(err)
+	defer rows.Close()
+
+	if rows.Next() {
+		return true
+	} else {
+		return false
+	}
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"collapp/helper"
 	"collapp/module/lang/model"
 	"context"
 )
@@ -10,5 +11,7 @@ type LangService interface {
 	Update(ctx context.Context, request model.LangUpdateRequest) model.LangResponse
 	Delete(ctx context.Context, langId int) model.LangResponse
 	FindById(ctx context.Context, langId int) model.LangResponse
-	FindAll(ctx context.Context) []model.LangResponse
+	FindAll(ctx context.Context, query helper.ListQuery) ([]model.LangResponse, helper.Pagination)
+	Export(ctx context.Context, query helper.ListQuery, write func(lang model.LangResponse) error) error
+	CheckLangCodeExist(ctx context.Context, langCode string) bool
 }
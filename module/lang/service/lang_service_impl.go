@@ -77,12 +77,33 @@ func (service *LangServiceImpl) FindById(ctx context.Context, langId int) model.
 	return model.ToLangResponse(langData)
 }
 
-func (service *LangServiceImpl) FindAll(ctx context.Context) []model.LangResponse {
+func (service *LangServiceImpl) FindAll(ctx context.Context, query helper.ListQuery) ([]model.LangResponse, helper.Pagination) {
 	tx, err := service.DB.Begin()
 	helper.IfError(err)
 	defer helper.CommitOrRollback(tx)
 
-	langsData := service.LangRepository.FindAll(ctx, tx)
+	langsData, pagination := service.LangRepository.FindAll(ctx, tx, query)
 
-	return model.ToLangResponses(langsData)
+	return model.ToLangResponses(langsData), pagination
+}
+
+// Export streams the languages of the query to write without keeping them in memory
+func (service *LangServiceImpl) Export(ctx context.Context, query helper.ListQuery, write func(lang model.LangResponse) error) error {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	return service.LangRepository.Export(ctx, tx, query, func(lang model.Lang) error {
+		return write(model.ToLangResponse(lang))
+	})
+}
+
+func (service *LangServiceImpl) CheckLangCodeExist(ctx context.Context, langCode string) bool {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	langIsExist := service.LangRepository.CheckLangCodeExist(ctx, tx, langCode)
+
+	return langIsExist
 }
@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"collapp/configs"
+	"collapp/helper"
+	"collapp/module/role/model"
+	"collapp/module/role/service"
+	translationService "collapp/module/translation/service"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type RoleHandler struct {
+	RoleService        service.RoleService
+	Validate           *validator.Validate
+	TranslationService translationService.TranslationService
+	config             *configs.Config
+}
+
+func NewRoleHandler(db *sql.DB, cfg *configs.Config, roleService service.RoleService, translationService translationService.TranslationService) RoleHandler {
+	validate := validator.New()
+	return RoleHandler{
+		RoleService:        roleService,
+		Validate:           validate,
+		TranslationService: translationService,
+		config:             cfg,
+	}
+}
+
+func (h *RoleHandler) Create(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	roleCreateRequest := model.RoleCreateRequest{}
+	context.Bind(&roleCreateRequest)
+
+	roleCreateRequest.CreatedBy = payloadJwt.ActorId
+
+	currentTime := time.Now()
+	roleCreateRequest.CreatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	err := h.Validate.Struct(roleCreateRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	codeIsExist := h.RoleService.CheckRoleCodeExist(context, roleCreateRequest.RoleCode)
+	if codeIsExist {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   h.TranslationService.Translation(context, "role_code_is_exist", payloadJwt.UserLangCode) + " (" + roleCreateRequest.RoleCode + ")",
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	roleResponse := h.RoleService.Create(context, roleCreateRequest)
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "success_create_role", payloadJwt.UserLangCode),
+		Data:   roleResponse,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+func (h *RoleHandler) Update(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	roleUpdateRequest := model.RoleUpdateRequest{}
+	context.Bind(&roleUpdateRequest)
+
+	roleUpdateRequest.UpdatedBy = payloadJwt.ActorId
+
+	currentTime := time.Now()
+	roleUpdateRequest.UpdatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	roleId := context.Param("roleId")
+	id, err := strconv.Atoi(roleId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	roleUpdateRequest.RoleId = id
+
+	err = h.Validate.Struct(roleUpdateRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	roleCheck := h.RoleService.FindById(context, id)
+	if roleCheck.RoleId != 0 && roleCheck.RoleCode != roleUpdateRequest.RoleCode && h.RoleService.CheckRoleCodeExist(context, roleUpdateRequest.RoleCode) {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   h.TranslationService.Translation(context, "role_code_is_exist", payloadJwt.UserLangCode) + " (" + roleUpdateRequest.RoleCode + ")",
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	roleResponse := h.RoleService.Update(context, roleUpdateRequest)
+
+	if roleResponse.RoleId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_update_role", payloadJwt.UserLangCode),
+			Data:   roleResponse,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+func (h *RoleHandler) Delete(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	roleId := context.Param("roleId")
+	id, err := strconv.Atoi(roleId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	roleResponse := h.RoleService.Delete(context, id)
+
+	if roleResponse.RoleId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_delete_role", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+func (h *RoleHandler) FindById(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	roleId := context.Param("roleId")
+	id, err := strconv.Atoi(roleId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	roleResponse := h.RoleService.FindById(context, id)
+
+	if roleResponse.RoleId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_get_role", payloadJwt.UserLangCode),
+			Data:   roleResponse,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+func (h *RoleHandler) FindAll(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	roleResponses := h.RoleService.FindAll(context)
+
+	if len(roleResponses) > 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_get_role", payloadJwt.UserLangCode),
+			Data:   roleResponses,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+func (h *RoleHandler) FindAllPermission(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	permissionResponses := h.RoleService.FindAllPermission(context)
+
+	if len(permissionResponses) > 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_get_permission", payloadJwt.UserLangCode),
+			Data:   permissionResponses,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+func (h *RoleHandler) FindByUserId(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	roleResponses := h.RoleService.FindByUserId(context, id)
+
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "success_get_role", payloadJwt.UserLangCode),
+		Data:   roleResponses,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+func (h *RoleHandler) UpdateUserRole(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userRoleUpdateRequest := model.UserRoleUpdateRequest{}
+	context.Bind(&userRoleUpdateRequest)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userRoleUpdateRequest.UserId = id
+
+	err = h.Validate.Struct(userRoleUpdateRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	for _, roleCode := range userRoleUpdateRequest.RoleCode {
+		if !h.RoleService.CheckRoleCodeExist(context, roleCode) {
+			webResponse := helper.WebResponse{
+				Code:   http.StatusBadRequest,
+				Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+				Data:   h.TranslationService.Translation(context, "role_not_found", payloadJwt.UserLangCode) + " (" + roleCode + ")",
+			}
+
+			context.Writer.Header().Add("Content-Type", "application/json")
+			context.JSON(http.StatusBadRequest, webResponse)
+			return
+		}
+	}
+
+	roleResponses := h.RoleService.UpdateUserRole(context, userRoleUpdateRequest)
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "success_update_user_role", payloadJwt.UserLangCode),
+		Data:   roleResponses,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"collapp/transport/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *RoleHandler) Router(router *gin.RouterGroup, auth middleware.AuthMiddleware) {
+	role := router.Group("/roles")
+	role.Use(auth.Auth())
+	{
+		role.GET("/", auth.RequirePermission("roles.read"), h.FindAll)
+		role.GET("/:roleId", auth.RequirePermission("roles.read"), h.FindById)
+		role.POST("/", auth.RequirePermission("roles.manage"), h.Create)
+		role.PUT("/:roleId", auth.RequirePermission("roles.manage"), h.Update)
+		role.DELETE("/:roleId", auth.RequirePermission("roles.manage"), h.Delete)
+	}
+
+	permission := router.Group("/permissions")
+	permission.Use(auth.Auth())
+	{
+		permission.GET("/", auth.RequirePermission("roles.read"), h.FindAllPermission)
+	}
+
+	userRole := router.Group("/users/:userId/roles")
+	userRole.Use(auth.Auth())
+	{
+		userRole.GET("", auth.RequirePermission("roles.read"), h.FindByUserId)
+		userRole.PUT("", auth.RequirePermission("roles.manage"), h.UpdateUserRole)
+	}
+}
@@ -0,0 +1,106 @@
+package model
+
+import "database/sql"
+
+// RoleCodeAdmin is the role that holds every permission, seeded by the migrations
+const RoleCodeAdmin = "admin"
+
+// model Role
+type Role struct {
+	RoleId         int
+	RoleCode       string
+	RoleName       string
+	RolePermission []Permission
+	CreatedBy      int
+	CreatedByCheck sql.NullInt32
+	CreatedAt      string
+	CreatedAtCheck sql.NullString
+	UpdatedBy      int
+	UpdatedByCheck sql.NullInt32
+	UpdatedAt      string
+	UpdatedAtCheck sql.NullString
+}
+
+type Permission struct {
+	PermissionId   int
+	PermissionCode string
+	PermissionName string
+}
+
+// request
+type RoleCreateRequest struct {
+	RoleCode       string   `validate:"required,min=1,max=100" json:"role_code"`
+	RoleName       string   `validate:"required,min=1,max=255" json:"role_name"`
+	RolePermission []string `json:"role_permission"`
+	CreatedBy      int      `validate:"required"`
+	CreatedAt      string   `validate:"required"`
+}
+
+type RoleUpdateRequest struct {
+	RoleId         int      `validate:"required"`
+	RoleCode       string   `validate:"required,min=1,max=100" json:"role_code"`
+	RoleName       string   `validate:"required,min=1,max=255" json:"role_name"`
+	RolePermission []string `json:"role_permission"`
+	UpdatedBy      int      `validate:"required"`
+	UpdatedAt      string   `validate:"required"`
+}
+
+type UserRoleUpdateRequest struct {
+	UserId   int      `validate:"required"`
+	RoleCode []string `json:"role_code"`
+}
+
+// rersponse
+type RoleResponse struct {
+	RoleId         int                  `json:"role_id"`
+	RoleCode       string               `json:"role_code"`
+	RoleName       string               `json:"role_name"`
+	RolePermission []PermissionResponse `json:"role_permission"`
+	CreatedBy      int                  `json:"created_by"`
+	CreatedAt      string               `json:"created_at"`
+	UpdatedBy      int                  `json:"updated_by"`
+	UpdatedAt      string               `json:"updated_at"`
+}
+
+type PermissionResponse struct {
+	PermissionId   int    `json:"permission_id"`
+	PermissionCode string `json:"permission_code"`
+	PermissionName string `json:"permission_name"`
+}
+
+func ToRoleResponse(role Role) RoleResponse {
+	return RoleResponse{
+		RoleId:         role.RoleId,
+		RoleCode:       role.RoleCode,
+		RoleName:       role.RoleName,
+		RolePermission: ToPermissionResponses(role.RolePermission),
+		CreatedBy:      role.CreatedBy,
+		CreatedAt:      role.CreatedAt,
+		UpdatedBy:      role.UpdatedBy,
+		UpdatedAt:      role.UpdatedAt,
+	}
+}
+
+func ToRoleResponses(roles []Role) []RoleResponse {
+	var roleResponses []RoleResponse
+	for _, role := range roles {
+		roleResponses = append(roleResponses, ToRoleResponse(role))
+	}
+	return roleResponses
+}
+
+func ToPermissionResponse(permission Permission) PermissionResponse {
+	return PermissionResponse{
+		PermissionId:   permission.PermissionId,
+		PermissionCode: permission.PermissionCode,
+		PermissionName: permission.PermissionName,
+	}
+}
+
+func ToPermissionResponses(permissions []Permission) []PermissionResponse {
+	var permissionResponses []PermissionResponse
+	for _, permission := range permissions {
+		permissionResponses = append(permissionResponses, ToPermissionResponse(permission))
+	}
+	return permissionResponses
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"collapp/module/role/model"
+	"context"
+	"database/sql"
+)
+
+type RoleRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, role model.RoleCreateRequest) model.Role
+	SavePermission(ctx context.Context, tx *sql.Tx, roleId int, permissionCode string) bool
+	Update(ctx context.Context, tx *sql.Tx, role model.RoleUpdateRequest) model.Role
+	Delete(ctx context.Context, tx *sql.Tx, role model.Role)
+	DeletePermission(ctx context.Context, tx *sql.Tx, roleId int)
+	FindById(ctx context.Context, tx *sql.Tx, roleId int) (model.Role, error)
+	PermissionFindByRoleId(ctx context.Context, tx *sql.Tx, roleId int) []model.Permission
+	FindAll(ctx context.Context, tx *sql.Tx) []model.Role
+	FindAllPermission(ctx context.Context, tx *sql.Tx) []model.Permission
+	FindByUserId(ctx context.Context, tx *sql.Tx, userId int) []model.Role
+	SaveUserRole(ctx context.Context, tx *sql.Tx, userId int, roleCode string) bool
+	DeleteUserRole(ctx context.Context, tx *sql.Tx, userId int)
+	HasPermission(ctx context.Context, tx *sql.Tx, roleCodes []string, permissionCode string) bool
+	PermissionCodeFindByRoleCodes(ctx context.Context, tx *sql.Tx, roleCodes []string) []string
+	CheckRoleCodeExist(ctx context.Context, tx *sql.Tx, roleCode string) bool
+	CheckRoleCodeAssigned(ctx context.Context, tx *sql.Tx, roleCode string) bool
+}
@@ -0,0 +1,434 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/role/model"
+	"context"
+	"database/sql"
+	"strings"
+)
+
+type RoleRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &RoleRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *RoleRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, role model.RoleCreateRequest) model.Role {
+
+	SQL := `INSERT INTO role
+			(
+				role_code,
+				role_name,
+				created_by,
+				created_at
+			) VALUES (
+				?,
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		role.RoleCode,
+		role.RoleName,
+		role.CreatedBy,
+		role.CreatedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	res := model.Role{}
+	res.RoleId = int(id)
+	return res
+}
+
+func (repository *RoleRepositoryImpl) SavePermission(ctx context.Context, tx *sql.Tx, roleId int, permissionCode string) bool {
+
+	SQL := `INSERT INTO role_permission
+			(
+				rolepermission_role_id,
+				rolepermission_permission_id
+			)
+			SELECT
+				?,
+				permission_id
+			FROM
+				permission
+			WHERE
+				permission_code = ?`
+	result, err := tx.ExecContext(ctx, SQL,
+		roleId,
+		permissionCode)
+	helper.IfError(err)
+
+	total, err := result.RowsAffected()
+	helper.IfError(err)
+
+	if total > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+func (repository *RoleRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, role model.RoleUpdateRequest) model.Role {
+	SQL := `UPDATE
+				role
+			SET
+				role_code = ?,
+				role_name = ?,
+				updated_by = ?,
+				updated_at = ?
+			WHERE
+				role_id = ?`
+	_, err := tx.ExecContext(ctx, SQL,
+		role.RoleCode,
+		role.RoleName,
+		role.UpdatedBy,
+		role.UpdatedAt,
+		role.RoleId)
+	helper.IfError(err)
+
+	res := model.Role{}
+	res.RoleId = role.RoleId
+	return res
+}
+
+func (repository *RoleRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, role model.Role) {
+	SQL := `DELETE FROM role WHERE role_id = ?`
+	_, err := tx.ExecContext(ctx, SQL, role.RoleId)
+	helper.IfError(err)
+
+	SQL = `DELETE FROM user_role WHERE userrole_role_id = ?`
+	_, err = tx.ExecContext(ctx, SQL, role.RoleId)
+	helper.IfError(err)
+}
+
+func (repository *RoleRepositoryImpl) DeletePermission(ctx context.Context, tx *sql.Tx, roleId int) {
+	SQL := `DELETE FROM role_permission WHERE rolepermission_role_id = ?`
+	_, err := tx.ExecContext(ctx, SQL, roleId)
+	helper.IfError(err)
+}
+
+func (repository *RoleRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, roleId int) (model.Role, error) {
+	SQL := `SELECT
+				role_id,
+				role_code,
+				role_name,
+				created_by,
+				created_at,
+				updated_by,
+				updated_at
+			FROM
+				role
+			WHERE
+				role_id = ?`
+	rows, err := tx.QueryContext(ctx, SQL, roleId)
+	helper.IfError(err)
+	defer rows.Close()
+
+	role := model.Role{}
+	if rows.Next() {
+		err := rows.Scan(
+			&role.RoleId,
+			&role.RoleCode,
+			&role.RoleName,
+			&role.CreatedByCheck,
+			&role.CreatedAtCheck,
+			&role.UpdatedByCheck,
+			&role.UpdatedAtCheck)
+		helper.IfError(err)
+	}
+
+	if role.CreatedByCheck.Valid {
+		role.CreatedBy = int(role.CreatedByCheck.Int32)
+	}
+	if role.CreatedAtCheck.Valid {
+		role.CreatedAt = role.CreatedAtCheck.String
+	}
+	if role.UpdatedByCheck.Valid {
+		role.UpdatedBy = int(role.UpdatedByCheck.Int32)
+	}
+	if role.UpdatedAtCheck.Valid {
+		role.UpdatedAt = role.UpdatedAtCheck.String
+	}
+
+	return role, nil
+}
+
+func (repository *RoleRepositoryImpl) PermissionFindByRoleId(ctx context.Context, tx *sql.Tx, roleId int) []model.Permission {
+	SQL := `SELECT
+				b.permission_id,
+				b.permission_code,
+				b.permission_name
+			FROM
+				role_permission a
+			JOIN
+				permission b ON b.permission_id = a.rolepermission_permission_id
+			WHERE
+				a.rolepermission_role_id = ?`
+	rows, err := tx.QueryContext(ctx, SQL, roleId)
+	helper.IfError(err)
+	defer rows.Close()
+
+	var permissions []model.Permission
+	for rows.Next() {
+		permission := model.Permission{}
+		err := rows.Scan(
+			&permission.PermissionId,
+			&permission.PermissionCode,
+			&permission.PermissionName)
+		helper.IfError(err)
+
+		permissions = append(permissions, permission)
+	}
+
+	return permissions
+}
+
+func (repository *RoleRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) []model.Role {
+	SQL := `SELECT
+				role_id,
+				role_code,
+				role_name,
+				created_by,
+				created_at,
+				updated_by,
+				updated_at
+			FROM
+				role`
+	rows, err := tx.QueryContext(ctx, SQL)
+	helper.IfError(err)
+	defer rows.Close()
+
+	var roles []model.Role
+	for rows.Next() {
+		role := model.Role{}
+		err := rows.Scan(
+			&role.RoleId,
+			&role.RoleCode,
+			&role.RoleName,
+			&role.CreatedByCheck,
+			&role.CreatedAtCheck,
+			&role.UpdatedByCheck,
+			&role.UpdatedAtCheck)
+		helper.IfError(err)
+
+		if role.CreatedByCheck.Valid {
+			role.CreatedBy = int(role.CreatedByCheck.Int32)
+		}
+		if role.CreatedAtCheck.Valid {
+			role.CreatedAt = role.CreatedAtCheck.String
+		}
+		if role.UpdatedByCheck.Valid {
+			role.UpdatedBy = int(role.UpdatedByCheck.Int32)
+		}
+		if role.UpdatedAtCheck.Valid {
+			role.UpdatedAt = role.UpdatedAtCheck.String
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles
+}
+
+func (repository *RoleRepositoryImpl) FindAllPermission(ctx context.Context, tx *sql.Tx) []model.Permission {
+	SQL := `SELECT
+				permission_id,
+				permission_code,
+				permission_name
+			FROM
+				permission`
+	rows, err := tx.QueryContext(ctx, SQL)
+	helper.IfError(err)
+	defer rows.Close()
+
+	var permissions []model.Permission
+	for rows.Next() {
+		permission := model.Permission{}
+		err := rows.Scan(
+			&permission.PermissionId,
+			&permission.PermissionCode,
+			&permission.PermissionName)
+		helper.IfError(err)
+
+		permissions = append(permissions, permission)
+	}
+
+	return permissions
+}
+
+func (repository *RoleRepositoryImpl) FindByUserId(ctx context.Context, tx *sql.Tx, userId int) []model.Role {
+	SQL := `SELECT
+				b.role_id,
+				b.role_code,
+				b.role_name
+			FROM
+				user_role a
+			JOIN
+				role b ON b.role_id = a.userrole_role_id
+			WHERE
+				a.userrole_user_id = ?`
+	rows, err := tx.QueryContext(ctx, SQL, userId)
+	helper.IfError(err)
+	defer rows.Close()
+
+	var roles []model.Role
+	for rows.Next() {
+		role := model.Role{}
+		err := rows.Scan(
+			&role.RoleId,
+			&role.RoleCode,
+			&role.RoleName)
+		helper.IfError(err)
+
+		roles = append(roles, role)
+	}
+
+	return roles
+}
+
+func (repository *RoleRepositoryImpl) SaveUserRole(ctx context.Context, tx *sql.Tx, userId int, roleCode string) bool {
+
+	SQL := `INSERT INTO user_role
+			(
+				userrole_user_id,
+				userrole_role_id
+			)
+			SELECT
+				?,
+				role_id
+			FROM
+				role
+			WHERE
+				role_code = ?`
+	result, err := tx.ExecContext(ctx, SQL,
+		userId,
+		roleCode)
+	helper.IfError(err)
+
+	total, err := result.RowsAffected()
+	helper.IfError(err)
+
+	if total > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+func (repository *RoleRepositoryImpl) DeleteUserRole(ctx context.Context, tx *sql.Tx, userId int) {
+	SQL := `DELETE FROM user_role WHERE userrole_user_id = ?`
+	_, err := tx.ExecContext(ctx, SQL, userId)
+	helper.IfError(err)
+}
+
+func (repository *RoleRepositoryImpl) HasPermission(ctx context.Context, tx *sql.Tx, roleCodes []string, permissionCode string) bool {
+	if len(roleCodes) == 0 {
+		return false
+	}
+
+	args := []interface{}{permissionCode}
+	for _, roleCode := range roleCodes {
+		args = append(args, roleCode)
+	}
+
+	SQL := `SELECT
+				a.rolepermission_role_id
+			FROM
+				role_permission a
+			JOIN
+				role b ON b.role_id = a.rolepermission_role_id
+			JOIN
+				permission c ON c.permission_id = a.rolepermission_permission_id
+			WHERE
+				c.permission_code = ?
+				AND b.role_code IN (?` + strings.Repeat(", ?", len(roleCodes)-1) + `)
+			LIMIT 1`
+	rows, err := tx.QueryContext(ctx, SQL, args...)
+	helper.IfError(err)
+	defer rows.Close()
+
+	if rows.Next() {
+		return true
+	} else {
+		return false
+	}
+}
+
+func (repository *RoleRepositoryImpl) PermissionCodeFindByRoleCodes(ctx context.Context, tx *sql.Tx, roleCodes []string) []string {
+	permissionCodes := []string{}
+	if len(roleCodes) == 0 {
+		return permissionCodes
+	}
+
+	args := []interface{}{}
+	for _, roleCode := range roleCodes {
+		args = append(args, roleCode)
+	}
+
+	SQL := `SELECT DISTINCT
+				c.permission_code
+			FROM
+				role_permission a
+			JOIN
+				role b ON b.role_id = a.rolepermission_role_id
+			JOIN
+				permission c ON c.permission_id = a.rolepermission_permission_id
+			WHERE
+				b.role_code IN (?` + strings.Repeat(", ?", len(roleCodes)-1) + `)`
+	rows, err := tx.QueryContext(ctx, SQL, args...)
+	helper.IfError(err)
+	defer rows.Close()
+
+	for rows.Next() {
+		var permissionCode string
+		err := rows.Scan(&permissionCode)
+		helper.IfError(err)
+
+		permissionCodes = append(permissionCodes, permissionCode)
+	}
+
+	return permissionCodes
+}
+
+func (repository *RoleRepositoryImpl) CheckRoleCodeExist(ctx context.Context, tx *sql.Tx, roleCode string) bool {
+	SQL := `SELECT
+				role_id
+			FROM
+				role
+			WHERE
+				role_code = ?`
+	rows, err := tx.QueryContext(ctx, SQL, roleCode)
+	helper.IfError(err)
+	defer rows.Close()
+
+	if rows.Next() {
+		return true
+	} else {
+		return false
+	}
+}
+
+// CheckRoleCodeAssigned tells whether any user holds the role
+func (repository *RoleRepositoryImpl) CheckRoleCodeAssigned(ctx context.Context, tx *sql.Tx, roleCode string) bool {
+	SQL := `SELECT
+				ur.userrole_user_id
+			FROM
+				user_role ur
+				JOIN role r ON r.role_id = ur.userrole_role_id
+			WHERE
+				r.role_code = ?
+			LIMIT 1`
+	rows, err := tx.QueryContext(ctx, SQL, roleCode)
+	helper.IfError(err)
+	defer rows.Close()
+
+	return rows.Next()
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"collapp/module/role/model"
+	"context"
+)
+
+type RoleService interface {
+	Create(ctx context.Context, request model.RoleCreateRequest) model.RoleResponse
+	Update(ctx context.Context, request model.RoleUpdateRequest) model.RoleResponse
+	Delete(ctx context.Context, roleId int) model.RoleResponse
+	FindById(ctx context.Context, roleId int) model.RoleResponse
+	FindAll(ctx context.Context) []model.RoleResponse
+	FindAllPermission(ctx context.Context) []model.PermissionResponse
+	FindByUserId(ctx context.Context, userId int) []model.RoleResponse
+	FindRoleCodesByUserId(ctx context.Context, userId int) []string
+	UpdateUserRole(ctx context.Context, request model.UserRoleUpdateRequest) []model.RoleResponse
+	HasPermission(ctx context.Context, roleCodes []string, permissionCode string) bool
+	HasAllPermissions(ctx context.Context, roleCodes []string, ofRoleCodes []string) bool
+	CheckRoleCodeExist(ctx context.Context, roleCode string) bool
+	GrantFirstAdmin(ctx context.Context, userId int) bool
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"collapp/helper"
+	"collapp/module/role/model"
+	"collapp/module/role/repository"
+	"context"
+	"database/sql"
+)
+
+type RoleServiceImpl struct {
+	RoleRepository repository.RoleRepository
+	DB             *sql.DB
+}
+
+func NewRoleService(DB *sql.DB, roleRepo repository.RoleRepository) RoleService {
+	return &RoleServiceImpl{
+		RoleRepository: roleRepo,
+		DB:             DB,
+	}
+}
+
+func (service *RoleServiceImpl) Create(ctx context.Context, request model.RoleCreateRequest) model.RoleResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	roleData := service.RoleRepository.Save(ctx, tx, request)
+	if roleData.RoleId > 0 {
+		for _, permissionCode := range request.RolePermission {
+			service.RoleRepository.SavePermission(ctx, tx, roleData.RoleId, permissionCode)
+		}
+
+		roleData, err := service.RoleRepository.FindById(ctx, tx, roleData.RoleId)
+		helper.IfError(err)
+
+		roleData.RolePermission = service.RoleRepository.PermissionFindByRoleId(ctx, tx, roleData.RoleId)
+		return model.ToRoleResponse(roleData)
+	} else {
+		return model.ToRoleResponse(roleData)
+	}
+}
+
+func (service *RoleServiceImpl) Update(ctx context.Context, request model.RoleUpdateRequest) model.RoleResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	roleData, err := service.RoleRepository.FindById(ctx, tx, request.RoleId)
+	if err == nil && roleData.RoleId != 0 {
+		roleData = service.RoleRepository.Update(ctx, tx, request)
+
+		service.RoleRepository.DeletePermission(ctx, tx, request.RoleId)
+		for _, permissionCode := range request.RolePermission {
+			service.RoleRepository.SavePermission(ctx, tx, request.RoleId, permissionCode)
+		}
+
+		roleData, err := service.RoleRepository.FindById(ctx, tx, roleData.RoleId)
+		helper.IfError(err)
+
+		roleData.RolePermission = service.RoleRepository.PermissionFindByRoleId(ctx, tx, roleData.RoleId)
+		return model.ToRoleResponse(roleData)
+	}
+
+	return model.ToRoleResponse(roleData)
+}
+
+func (service *RoleServiceImpl) Delete(ctx context.Context, roleId int) model.RoleResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	roleData, err := service.RoleRepository.FindById(ctx, tx, roleId)
+	if err == nil && roleData.RoleId != 0 {
+		service.RoleRepository.Delete(ctx, tx, roleData)
+		service.RoleRepository.DeletePermission(ctx, tx, roleId)
+	}
+
+	return model.ToRoleResponse(roleData)
+}
+
+func (service *RoleServiceImpl) FindById(ctx context.Context, roleId int) model.RoleResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	roleData, _ := service.RoleRepository.FindById(ctx, tx, roleId)
+	roleData.RolePermission = service.RoleRepository.PermissionFindByRoleId(ctx, tx, roleId)
+
+	return model.ToRoleResponse(roleData)
+}
+
+func (service *RoleServiceImpl) FindAll(ctx context.Context) []model.RoleResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	rolesData := service.RoleRepository.FindAll(ctx, tx)
+
+	for index, dt := range rolesData {
+		rolesData[index].RolePermission = service.RoleRepository.PermissionFindByRoleId(ctx, tx, dt.RoleId)
+	}
+
+	return model.ToRoleResponses(rolesData)
+}
+
+func (service *RoleServiceImpl) FindAllPermission(ctx context.Context) []model.PermissionResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	permissionsData := service.RoleRepository.FindAllPermission(ctx, tx)
+
+	return model.ToPermissionResponses(permissionsData)
+}
+
+func (service *RoleServiceImpl) FindByUserId(ctx context.Context, userId int) []model.RoleResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	rolesData := service.RoleRepository.FindByUserId(ctx, tx, userId)
+
+	return model.ToRoleResponses(rolesData)
+}
+
+func (service *RoleServiceImpl) FindRoleCodesByUserId(ctx context.Context, userId int) []string {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	roleCodes := []string{}
+	for _, role := range service.RoleRepository.FindByUserId(ctx, tx, userId) {
+		roleCodes = append(roleCodes, role.RoleCode)
+	}
+
+	return roleCodes
+}
+
+func (service *RoleServiceImpl) UpdateUserRole(ctx context.Context, request model.UserRoleUpdateRequest) []model.RoleResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	service.RoleRepository.DeleteUserRole(ctx, tx, request.UserId)
+	for _, roleCode := range request.RoleCode {
+		service.RoleRepository.SaveUserRole(ctx, tx, request.UserId, roleCode)
+	}
+
+	rolesData := service.RoleRepository.FindByUserId(ctx, tx, request.UserId)
+
+	return model.ToRoleResponses(rolesData)
+}
+
+func (service *RoleServiceImpl) HasPermission(ctx context.Context, roleCodes []string, permissionCode string) bool {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	hasPermission := service.RoleRepository.HasPermission(ctx, tx, roleCodes, permissionCode)
+
+	return hasPermission
+}
+
+// HasAllPermissions tells whether roleCodes grant every permission that ofRoleCodes grant, so that whoever holds
+// roleCodes gains nothing by acting as or creating a holder of ofRoleCodes
+func (service *RoleServiceImpl) HasAllPermissions(ctx context.Context, roleCodes []string, ofRoleCodes []string) bool {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	permissionCodes := map[string]bool{}
+	for _, permissionCode := range service.RoleRepository.PermissionCodeFindByRoleCodes(ctx, tx, roleCodes) {
+		permissionCodes[permissionCode] = true
+	}
+
+	for _, permissionCode := range service.RoleRepository.PermissionCodeFindByRoleCodes(ctx, tx, ofRoleCodes) {
+		if !permissionCodes[permissionCode] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (service *RoleServiceImpl) CheckRoleCodeExist(ctx context.Context, roleCode string) bool {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	roleIsExist := service.RoleRepository.CheckRoleCodeExist(ctx, tx, roleCode)
+
+	return roleIsExist
+}
+
+// GrantFirstAdmin gives the admin role to the user while no user holds it yet, so the configured account becomes the
+// first administrator once and an admin role taken away later is not handed back
+func (service *RoleServiceImpl) GrantFirstAdmin(ctx context.Context, userId int) bool {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	if service.RoleRepository.CheckRoleCodeAssigned(ctx, tx, model.RoleCodeAdmin) {
+		return false
+	}
+
+	return service.RoleRepository.SaveUserRole(ctx, tx, userId, model.RoleCodeAdmin)
+}
@@ -6,6 +6,7 @@ import (
 	"collapp/module/translation/model"
 	"collapp/module/translation/service"
 	"database/sql"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -35,7 +36,7 @@ func (h *TranslationHandler) Create(context *gin.Context) {
 	translationCreateRequest := model.TranslationCreateRequest{}
 	context.Bind(&translationCreateRequest)
 
-	translationCreateRequest.CreatedBy = payloadJwt.UserId
+	translationCreateRequest.CreatedBy = payloadJwt.ActorId
 
 	currentTime := time.Now()
 	translationCreateRequest.CreatedAt = currentTime.Format("2006-01-02 15:04:05")
@@ -83,7 +84,7 @@ func (h *TranslationHandler) Update(context *gin.Context) {
 	translationUpdateRequest := model.TranslationUpdateRequest{}
 	context.Bind(&translationUpdateRequest)
 
-	translationUpdateRequest.UpdatedBy = payloadJwt.UserId
+	translationUpdateRequest.UpdatedBy = payloadJwt.ActorId
 
 	currentTime := time.Now()
 	translationUpdateRequest.UpdatedAt = currentTime.Format("2006-01-02 15:04:05")
@@ -192,25 +193,72 @@ func (h *TranslationHandler) FindById(context *gin.Context) {
 func (h *TranslationHandler) FindAll(context *gin.Context) {
 	payloadJwt := helper.PayloadJwt(context)
 
-	translationResponses := h.TranslationService.FindAll(context)
+	listQuery, err := helper.NewListQuery(context, model.TranslationListSorts, model.TranslationListFilters, "translation_id")
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	translationResponses, pagination := h.TranslationService.FindAll(context, listQuery)
 
 	if len(translationResponses) > 0 {
 		webResponse := helper.WebResponse{
-			Code:   200,
-			Status: h.TranslationService.Translation(context, "success_get_translation", payloadJwt.UserLangCode),
-			Data:   translationResponses,
+			Code:       200,
+			Status:     h.TranslationService.Translation(context, "success_get_translation", payloadJwt.UserLangCode),
+			Data:       translationResponses,
+			Pagination: &pagination,
 		}
 
 		context.Writer.Header().Add("Content-Type", "application/json")
 		context.JSON(200, webResponse)
 	} else {
 		webResponse := helper.WebResponse{
-			Code:   http.StatusNotFound,
-			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
-			Data:   nil,
+			Code:       http.StatusNotFound,
+			Status:     h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:       nil,
+			Pagination: &pagination,
 		}
 
 		context.Writer.Header().Add("Content-Type", "application/json")
 		context.JSON(http.StatusNotFound, webResponse)
 	}
 }
+
+// Export streams the translation texts matching the filters of FindAll as CSV or NDJSON, chosen with ?format=
+func (h *TranslationHandler) Export(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	listQuery, err := helper.NewListQuery(context, model.TranslationListSorts, model.TranslationListFilters, "translation_id")
+	var exporter *helper.Exporter
+	if err == nil {
+		exporter, err = helper.NewExporter(context, "translations", model.TranslationExportResponse{})
+	}
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	err = h.TranslationService.Export(context, listQuery, func(translation model.TranslationExportResponse) error {
+		return exporter.Write(translation)
+	})
+	if err == nil {
+		err = exporter.Close()
+	}
+	if err != nil {
+		log.Println("Export of translations stopped.", err)
+	}
+}
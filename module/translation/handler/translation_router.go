@@ -10,11 +10,12 @@ func (h *TranslationHandler) Router(router *gin.RouterGroup, auth middleware.Aut
 	translation := router.Group("/translation")
 	translation.Use(auth.Auth())
 	{
-		translation.GET("/", h.FindAll)
-		translation.GET("/:translationId", h.FindById)
-		translation.POST("/", h.Create)
-		translation.PUT("/:translationId", h.Update)
-		translation.DELETE("/:translationId", h.Delete)
+		translation.GET("/", auth.RequirePermission("translations.read"), h.FindAll)
+		translation.GET("/export", auth.RequirePermission("translations.read"), h.Export)
+		translation.GET("/:translationId", auth.RequirePermission("translations.read"), h.FindById)
+		translation.POST("/", auth.RequirePermission("translations.create"), h.Create)
+		translation.PUT("/:translationId", auth.RequirePermission("translations.update"), h.Update)
+		translation.DELETE("/:translationId", auth.RequirePermission("translations.delete"), h.Delete)
 	}
 
 }
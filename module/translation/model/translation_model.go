@@ -4,6 +4,21 @@ import (
 	"database/sql"
 )
 
+// TranslationListSorts and TranslationListFilters are the fields the translation list may be sorted and filtered by,
+// with their column
+var TranslationListSorts = map[string]string{
+	"translation_id":   "lang_key.langkey_id",
+	"translation_code": "lang_key.langkey_key",
+	"created_at":       "lang_key.created_at",
+	"updated_at":       "lang_key.updated_at",
+}
+
+var TranslationListFilters = map[string]string{
+	"translation_code": "lang_key.langkey_key",
+	"created_at":       "lang_key.created_at",
+	"updated_at":       "lang_key.updated_at",
+}
+
 // model Translation
 type Translation struct {
 	TranslationId   int
@@ -68,6 +83,17 @@ type TranslationTextResponse struct {
 	TranslationTextLangText      string `json:"lang_text"`
 }
 
+// TranslationExportResponse is one text of a translation in an export, a translation without texts has one row with
+// an empty language
+type TranslationExportResponse struct {
+	TranslationId  int    `json:"translation_id"`
+	TranslationKey string `json:"translation_code"`
+	LangCode       string `json:"lang_code"`
+	LangText       string `json:"lang_text"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
 func ToTranslationResponse(translation Translation) TranslationResponse {
 	return TranslationResponse{
 		TranslationId:   translation.TranslationId,
@@ -103,3 +129,14 @@ func ToTranslationTextResponses(texts []TranslationText) []TranslationTextRespon
 	}
 	return textResponses
 }
+
+func ToTranslationExportResponse(translation Translation, text TranslationText) TranslationExportResponse {
+	return TranslationExportResponse{
+		TranslationId:  translation.TranslationId,
+		TranslationKey: translation.TranslationKey,
+		LangCode:       text.TranslationTextLangCode,
+		LangText:       text.TranslationTextLangText,
+		CreatedAt:      translation.CreatedAt,
+		UpdatedAt:      translation.UpdatedAt,
+	}
+}
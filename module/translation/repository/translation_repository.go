@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"collapp/helper"
 	"collapp/module/translation/model"
 	"context"
 	"database/sql"
@@ -14,7 +15,8 @@ type TranslationRepository interface {
 	DeleteText(ctx context.Context, tx *sql.Tx, translation model.TranslationTextDeleteRequest)
 	FindById(ctx context.Context, tx *sql.Tx, translationId int) (model.Translation, error)
 	TextFindById(ctx context.Context, tx *sql.Tx, translationId int) []model.TranslationText
-	FindAll(ctx context.Context, tx *sql.Tx) []model.Translation
+	FindAll(ctx context.Context, tx *sql.Tx, query helper.ListQuery) ([]model.Translation, helper.Pagination)
+	Export(ctx context.Context, tx *sql.Tx, query helper.ListQuery, write func(translation model.Translation, text model.TranslationText) error) error
 	Translation(ctx context.Context, tx *sql.Tx, key string, langCode string) string
 	CheckKeyTranslationExist(ctx context.Context, tx *sql.Tx, key string) bool
 }
@@ -176,30 +176,52 @@ func (repository *TranslationRepositoryImpl) TextFindById(ctx context.Context, t
 	return translations
 }
 
-func (repository *TranslationRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) []model.Translation {
+func (repository *TranslationRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx, query helper.ListQuery) ([]model.Translation, helper.Pagination) {
+	where, args := query.Where()
 	SQL := `SELECT 
+				COUNT(*) 
+			FROM 
+				lang_key 
+			` + where
+	var total int
+	err := tx.QueryRowContext(ctx, SQL, args...).Scan(&total)
+	helper.IfError(err)
+
+	where, args = query.PageWhere()
+	orderBy, orderArgs := query.OrderBy()
+	SQL = `SELECT 
 				langkey_id, 
 				langkey_key,
 				created_by,
 				created_at, 
 				updated_by, 
-				updated_at 
+				updated_at` + query.CursorColumns() + `
 			FROM 
-				lang_key`
-	rows, err := tx.QueryContext(ctx, SQL)
+				lang_key 
+			` + where + `
+			` + orderBy
+	rows, err := tx.QueryContext(ctx, SQL, append(args, orderArgs...)...)
 	helper.IfError(err)
 	defer rows.Close()
 
 	var translations []model.Translation
+	var lastKeys []interface{}
+	hasMore := false
 	for rows.Next() {
+		if len(translations) == query.Limit {
+			hasMore = true
+			break
+		}
+
 		translation := model.Translation{}
-		err := rows.Scan(
+		keys := query.CursorKeys()
+		err := rows.Scan(append([]interface{}{
 			&translation.TranslationId,
 			&translation.TranslationKey,
 			&translation.CreatedByCheck,
 			&translation.CreatedAtCheck,
 			&translation.UpdatedByCheck,
-			&translation.UpdatedAtCheck)
+			&translation.UpdatedAtCheck}, keys...)...)
 		helper.IfError(err)
 
 		if translation.CreatedByCheck.Valid {
@@ -216,9 +238,64 @@ func (repository *TranslationRepositoryImpl) FindAll(ctx context.Context, tx *sq
 		}
 
 		translations = append(translations, translation)
+		lastKeys = keys
 	}
 
-	return translations
+	return translations, query.Pagination(total, hasMore, lastKeys)
+}
+
+// Export passes every text of the translations of the query to write as they are read, a translation without texts is
+// passed once with an empty text. It stops at the first error of write.
+func (repository *TranslationRepositoryImpl) Export(ctx context.Context, tx *sql.Tx, query helper.ListQuery, write func(translation model.Translation, text model.TranslationText) error) error {
+	where, args := query.Where()
+	SQL := `SELECT 
+				langkey_id, 
+				langkey_key,
+				created_at, 
+				updated_at, 
+				langkeytext_lang_code, 
+				langkeytext_lang_text
+			FROM 
+				lang_key 
+			LEFT JOIN
+				lang_key_text ON langkeytext_langkey_id = langkey_id
+			` + where + `
+			` + query.Sort() + `, langkeytext_lang_code ASC`
+	rows, err := tx.QueryContext(ctx, SQL, args...)
+	helper.IfError(err)
+	defer rows.Close()
+
+	for rows.Next() {
+		translation := model.Translation{}
+		var langCode, langText sql.NullString
+		err := rows.Scan(
+			&translation.TranslationId,
+			&translation.TranslationKey,
+			&translation.CreatedAtCheck,
+			&translation.UpdatedAtCheck,
+			&langCode,
+			&langText)
+		helper.IfError(err)
+
+		if translation.CreatedAtCheck.Valid {
+			translation.CreatedAt = translation.CreatedAtCheck.String
+		}
+		if translation.UpdatedAtCheck.Valid {
+			translation.UpdatedAt = translation.UpdatedAtCheck.String
+		}
+
+		text := model.TranslationText{}
+		text.TranslationTextTranslationId = translation.TranslationId
+		text.TranslationTextLangCode = langCode.String
+		text.TranslationTextLangText = langText.String
+
+		err = write(translation, text)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
 
 func (repository *TranslationRepositoryImpl) Translation(ctx context.Context, tx *sql.Tx, key string, langCode string) string {
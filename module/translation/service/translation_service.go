@@ -1,6 +1,7 @@
 package service
 
 import (
+	"collapp/helper"
 	"collapp/module/translation/model"
 	"context"
 )
@@ -10,7 +11,8 @@ type TranslationService interface {
 	Update(ctx context.Context, request model.TranslationUpdateRequest) model.TranslationResponse
 	Delete(ctx context.Context, translationId int) model.TranslationResponse
 	FindById(ctx context.Context, translationId int) model.TranslationResponse
-	FindAll(ctx context.Context) []model.TranslationResponse
+	FindAll(ctx context.Context, query helper.ListQuery) ([]model.TranslationResponse, helper.Pagination)
+	Export(ctx context.Context, query helper.ListQuery, write func(translation model.TranslationExportResponse) error) error
 	Translation(ctx context.Context, key string, langCode string) string
 	CheckKeyTranslationExist(ctx context.Context, key string) bool
 }
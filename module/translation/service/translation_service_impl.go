@@ -117,18 +117,29 @@ func (service *TranslationServiceImpl) FindById(ctx context.Context, translation
 	return model.ToTranslationResponse(translationData)
 }
 
-func (service *TranslationServiceImpl) FindAll(ctx context.Context) []model.TranslationResponse {
+func (service *TranslationServiceImpl) FindAll(ctx context.Context, query helper.ListQuery) ([]model.TranslationResponse, helper.Pagination) {
 	tx, err := service.DB.Begin()
 	helper.IfError(err)
 	defer helper.CommitOrRollback(tx)
 
-	var translationsData = service.TranslationRepository.FindAll(ctx, tx)
+	translationsData, pagination := service.TranslationRepository.FindAll(ctx, tx, query)
 
 	for index, dt := range translationsData {
 		translationsData[index].TranslationText = service.TranslationRepository.TextFindById(ctx, tx, dt.TranslationId)
 	}
 
-	return model.ToTranslationResponses(translationsData)
+	return model.ToTranslationResponses(translationsData), pagination
+}
+
+// Export streams the texts of the translations of the query to write without keeping them in memory
+func (service *TranslationServiceImpl) Export(ctx context.Context, query helper.ListQuery, write func(translation model.TranslationExportResponse) error) error {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	return service.TranslationRepository.Export(ctx, tx, query, func(translation model.Translation, text model.TranslationText) error {
+		return write(model.ToTranslationExportResponse(translation, text))
+	})
 }
 
 func (service *TranslationServiceImpl) Translation(ctx context.Context, key string, langCode string) string {
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyPrefix marks the keys issued here so they are easy to spot in logs and secret scanners
+const apiKeyPrefix = "ck_"
+
+func (h *UserHandler) CreateServiceAccount(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userServiceAccountCreateRequest := model.UserServiceAccountCreateRequest{}
+	context.Bind(&userServiceAccountCreateRequest)
+
+	userServiceAccountCreateRequest.CreatedBy = payloadJwt.ActorId
+
+	currentTime := time.Now()
+	userServiceAccountCreateRequest.CreatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	err := h.Validate.Struct(userServiceAccountCreateRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userResponse := h.UserService.CreateServiceAccount(context, userServiceAccountCreateRequest)
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "success_create_service_account", payloadJwt.UserLangCode),
+		Data:   userResponse,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+func (h *UserHandler) CreateApiKey(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userApiKeyCreateRequest := model.UserApiKeyCreateRequest{}
+	context.Bind(&userApiKeyCreateRequest)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userApiKeyCreateRequest.ApiKeyUserId = id
+	userApiKeyCreateRequest.CreatedBy = payloadJwt.ActorId
+
+	currentTime := time.Now()
+	userApiKeyCreateRequest.CreatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	err = h.Validate.Struct(userApiKeyCreateRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	// scopes are permission codes, the key can never do more than the roles of its service account either
+	permissionCodes := map[string]bool{}
+	for _, permission := range h.RoleService.FindAllPermission(context) {
+		permissionCodes[permission.PermissionCode] = true
+	}
+	for _, scope := range userApiKeyCreateRequest.ApiKeyScopes {
+		if !permissionCodes[scope] {
+			webResponse := helper.WebResponse{
+				Code:   http.StatusBadRequest,
+				Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+				Data:   h.TranslationService.Translation(context, "permission_not_found", payloadJwt.UserLangCode) + " (" + scope + ")",
+			}
+
+			context.Writer.Header().Add("Content-Type", "application/json")
+			context.JSON(http.StatusBadRequest, webResponse)
+			return
+		}
+	}
+
+	// the key is shown once here, only its hash is stored
+	userApiKeyCreateRequest.ApiKey = apiKeyPrefix + helper.GenerateRandomToken(24)
+
+	userApiKeyResponse := h.UserService.CreateApiKey(context, userApiKeyCreateRequest)
+
+	if userApiKeyResponse.ApiKeyId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_create_api_key", payloadJwt.UserLangCode),
+			Data:   userApiKeyResponse,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "service_account_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+func (h *UserHandler) FindApiKey(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userApiKeyResponses := h.UserService.FindApiKeyByUserId(context, id)
+
+	if len(userApiKeyResponses) > 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_get_api_key", payloadJwt.UserLangCode),
+			Data:   userApiKeyResponses,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+func (h *UserHandler) RevokeApiKey(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	apiKeyId := context.Param("apiKeyId")
+	keyId, err := strconv.Atoi(apiKeyId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userApiKeyRevokeRequest := model.UserApiKeyRevokeRequest{}
+	userApiKeyRevokeRequest.ApiKeyId = keyId
+	userApiKeyRevokeRequest.ApiKeyUserId = id
+
+	currentTime := time.Now()
+	userApiKeyRevokeRequest.ApiKeyRevokedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	userApiKeyResponse := h.UserService.RevokeApiKey(context, userApiKeyRevokeRequest)
+
+	if userApiKeyResponse.ApiKeyId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_revoke_api_key", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
@@ -3,34 +3,50 @@ package handler
 import (
 	"collapp/configs"
 	"collapp/helper"
+	"collapp/infras"
+	langService "collapp/module/lang/service"
+	roleService "collapp/module/role/service"
 	translationService "collapp/module/translation/service"
 	"collapp/module/user/model"
 	"collapp/module/user/service"
 	"collapp/transport/http/middleware"
 	"database/sql"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserHandler struct {
 	UserService        service.UserService
 	Validate           *validator.Validate
 	TranslationService translationService.TranslationService
+	RoleService        roleService.RoleService
+	LangService        langService.LangService
+	Mailer             infras.Mailer
+	KeySet             *infras.KeySet
+	Oidc               *infras.Oidc
+	PasswordPolicy     *infras.PasswordPolicy
+	Storage            infras.Storage
 	config             *configs.Config
 }
 
-func NewUserHandler(db *sql.DB, cfg *configs.Config, userSvc service.UserService, translationSvc translationService.TranslationService) UserHandler {
+func NewUserHandler(db *sql.DB, cfg *configs.Config, userSvc service.UserService, translationSvc translationService.TranslationService, roleSvc roleService.RoleService, langSvc langService.LangService, mailer infras.Mailer, keySet *infras.KeySet, oidc *infras.Oidc, passwordPolicy *infras.PasswordPolicy, storage infras.Storage) UserHandler {
 	validate := validator.New()
 	return UserHandler{
 		UserService:        userSvc,
 		Validate:           validate,
 		TranslationService: translationSvc,
+		RoleService:        roleSvc,
+		LangService:        langSvc,
+		Mailer:             mailer,
+		KeySet:             keySet,
+		Oidc:               oidc,
+		PasswordPolicy:     passwordPolicy,
+		Storage:            storage,
 		config:             cfg,
 	}
 }
@@ -38,22 +54,18 @@ func NewUserHandler(db *sql.DB, cfg *configs.Config, userSvc service.UserService
 func (h *UserHandler) Create(context *gin.Context) {
 	payloadJwt := helper.PayloadJwt(context)
 
-	password := []byte(h.config.DefaultPassword)
-
 	userCreateRequest := model.UserCreateRequest{}
 	context.Bind(&userCreateRequest)
 
-	userCreateRequest.CreatedBy = payloadJwt.UserId
+	userCreateRequest.CreatedBy = payloadJwt.ActorId
 
 	currentTime := time.Now()
 	userCreateRequest.CreatedAt = currentTime.Format("2006-01-02 15:04:05")
 
-	hashedPassword, err := bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
-	helper.IfError(err)
-
-	userCreateRequest.UserPassword = string(hashedPassword)
+	// the user is invited and chooses a password when accepting
+	userCreateRequest.UserStatus = model.UserStatusPending
 
-	err = h.Validate.Struct(userCreateRequest)
+	err := h.Validate.Struct(userCreateRequest)
 	if err != nil {
 		webResponse := helper.WebResponse{
 			Code:   http.StatusBadRequest,
@@ -67,11 +79,8 @@ func (h *UserHandler) Create(context *gin.Context) {
 	}
 
 	if userCreateRequest.UserPhoto != nil {
-		var pathFile = h.config.Files.Photo
-		var requestFile = "user_photo"
-
-		fileName, err := helper.UploadFile(context, requestFile, pathFile)
-		userCreateRequest.UserPhotoName = fileName
+		photoKey, err := h.uploadPhoto(context, userCreateRequest.UserPhoto)
+		userCreateRequest.UserPhotoName = photoKey
 		if err != nil {
 			webResponse := helper.WebResponse{
 				Code:   http.StatusInternalServerError,
@@ -86,6 +95,22 @@ func (h *UserHandler) Create(context *gin.Context) {
 	}
 
 	userResponse := h.UserService.Create(context, userCreateRequest)
+	if userResponse.UserId != 0 {
+		_, err = h.sendInvitation(context, userResponse.UserId, payloadJwt.ActorId, currentTime)
+	}
+	// the user exists by now, the invitation can be resent once signing works again
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusInternalServerError,
+			Status: h.TranslationService.Translation(context, "internal_server_error", payloadJwt.UserLangCode) + " " + h.TranslationService.Translation(context, "invitation_send_failed", payloadJwt.UserLangCode),
+			Data:   userResponse,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusInternalServerError, webResponse)
+		return
+	}
+
 	webResponse := helper.WebResponse{
 		Code:   200,
 		Status: h.TranslationService.Translation(context, "success_create_user", payloadJwt.UserLangCode),
@@ -102,7 +127,7 @@ func (h *UserHandler) Update(context *gin.Context) {
 	userUpdateRequest := model.UserUpdateRequest{}
 	context.Bind(&userUpdateRequest)
 
-	userUpdateRequest.UpdatedBy = payloadJwt.UserId
+	userUpdateRequest.UpdatedBy = payloadJwt.ActorId
 
 	currentTime := time.Now()
 	userUpdateRequest.UpdatedAt = currentTime.Format("2006-01-02 15:04:05")
@@ -126,13 +151,9 @@ func (h *UserHandler) Update(context *gin.Context) {
 		return
 	}
 
-	var pathFile = h.config.Files.Photo
-
 	if userUpdateRequest.UserPhoto != nil {
-		var requestFile = "user_photo"
-
-		fileName, err := helper.UploadFile(context, requestFile, pathFile)
-		userUpdateRequest.UserPhotoName = fileName
+		photoKey, err := h.uploadPhoto(context, userUpdateRequest.UserPhoto)
+		userUpdateRequest.UserPhotoName = photoKey
 		if err != nil {
 			webResponse := helper.WebResponse{
 				Code:   http.StatusInternalServerError,
@@ -149,7 +170,7 @@ func (h *UserHandler) Update(context *gin.Context) {
 	userResponse, oldPhoto := h.UserService.Update(context, userUpdateRequest)
 
 	if userUpdateRequest.UserPhoto != nil && oldPhoto != "" {
-		helper.DeleteFile(oldPhoto, pathFile)
+		h.deletePhoto(context, oldPhoto)
 	}
 
 	if userResponse.UserId != 0 {
@@ -186,7 +207,7 @@ func (h *UserHandler) Delete(context *gin.Context) {
 	if userDeleteRequest.IsSoftDelete {
 		userDeleteRequest.UserId = id
 
-		userDeleteRequest.DeletedBy = payloadJwt.UserId
+		userDeleteRequest.DeletedBy = payloadJwt.ActorId
 
 		currentTime := time.Now()
 		userDeleteRequest.DeletedAt = currentTime.Format("2006-01-02 15:04:05")
@@ -216,8 +237,7 @@ func (h *UserHandler) Delete(context *gin.Context) {
 
 		if userResponse.UserId != 0 {
 			if userResponse.UserPhoto != "" {
-				var pathFile = h.config.Files.Photo
-				helper.DeleteFile(userResponse.UserPhoto, pathFile)
+				h.deletePhoto(context, userResponse.UserPhoto)
 			}
 			webResponse := helper.WebResponse{
 				Code:   200,
@@ -272,22 +292,37 @@ func (h *UserHandler) FindById(context *gin.Context) {
 func (h *UserHandler) FindAll(context *gin.Context) {
 	payloadJwt := helper.PayloadJwt(context)
 
-	userResponses := h.UserService.FindAll(context)
+	listQuery, err := helper.NewListQuery(context, model.UserListSorts, model.UserListFilters, "user_id")
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userResponses, pagination := h.UserService.FindAll(context, listQuery)
 
 	if len(userResponses) > 0 {
 		webResponse := helper.WebResponse{
-			Code:   200,
-			Status: h.TranslationService.Translation(context, "success_get_user", payloadJwt.UserLangCode),
-			Data:   userResponses,
+			Code:       200,
+			Status:     h.TranslationService.Translation(context, "success_get_user", payloadJwt.UserLangCode),
+			Data:       userResponses,
+			Pagination: &pagination,
 		}
 
 		context.Writer.Header().Add("Content-Type", "application/json")
 		context.JSON(200, webResponse)
 	} else {
 		webResponse := helper.WebResponse{
-			Code:   http.StatusNotFound,
-			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
-			Data:   nil,
+			Code:       http.StatusNotFound,
+			Status:     h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:       nil,
+			Pagination: &pagination,
 		}
 
 		context.Writer.Header().Add("Content-Type", "application/json")
@@ -295,8 +330,39 @@ func (h *UserHandler) FindAll(context *gin.Context) {
 	}
 }
 
+// Export streams the users, without passwords or tokens matching the filters of FindAll as CSV or NDJSON, chosen with ?format=
+func (h *UserHandler) Export(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	listQuery, err := helper.NewListQuery(context, model.UserListSorts, model.UserListFilters, "user_id")
+	var exporter *helper.Exporter
+	if err == nil {
+		exporter, err = helper.NewExporter(context, "users", model.UserExportResponse{})
+	}
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	err = h.UserService.Export(context, listQuery, func(user model.UserExportResponse) error {
+		return exporter.Write(user)
+	})
+	if err == nil {
+		err = exporter.Close()
+	}
+	if err != nil {
+		log.Println("Export of users stopped.", err)
+	}
+}
+
 func (h *UserHandler) Login(context *gin.Context) {
-	jwtKey := []byte(h.config.JWT.Key)
 	defaultLang := h.config.DefaultLang
 
 	currentTime := time.Now()
@@ -316,99 +382,59 @@ func (h *UserHandler) Login(context *gin.Context) {
 		return
 	}
 
-	userCheck := h.UserService.FindByEmail(context, userLoginRequest.UserEmail)
-
-	err = bcrypt.CompareHashAndPassword([]byte(userCheck.UserPassword), []byte(userLoginRequest.UserPassword))
-
-	if err == nil {
+	userLoginAttemptRequest := h.loginAttemptRequest(context, userLoginRequest.UserEmail, currentTime)
+	userLoginAttempt := h.UserService.FindLoginAttempt(context, userLoginAttemptRequest)
+	if userLoginAttempt.IsLocked {
+		h.recordLoginEvent(context, 0, userLoginRequest.UserEmail, model.LoginEventLogin, "account_locked", currentTime)
+		h.accountLockedResponse(context, defaultLang)
+		return
+	}
 
-		userResponse := h.UserService.FindById(context, userCheck.UserId)
+	time.Sleep(h.loginDelay(userLoginAttempt.Failures))
 
-		expired := h.config.JWT.Expired
-		expiredRefresh := h.config.JWT.ExpiredRefresh
+	userCheck := h.UserService.FindByEmail(context, userLoginRequest.UserEmail)
 
-		// start cretae JWT
-		expirationTime := time.Now().Add(expired)
-		claims := middleware.Claims{
-			UserId:       userResponse.UserId,
-			UserName:     userResponse.UserName,
-			UserEmail:    userResponse.UserEmail,
-			UserLangCode: userResponse.UserLangCode,
-			StandardClaims: jwt.StandardClaims{
-				ExpiresAt: expirationTime.Unix(),
-			},
-		}
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenString, err := token.SignedString(jwtKey)
+	// an invited user has no password before the invitation is accepted, so it fails here like an unknown email
+	isValid, needsRehash := h.PasswordPolicy.Verify(userCheck.UserPassword, userLoginRequest.UserPassword)
 
-		if err != nil {
-			webResponse := helper.WebResponse{
-				Code:   http.StatusInternalServerError,
-				Status: h.TranslationService.Translation(context, "internal_server_error", defaultLang),
-				Data:   err,
-			}
-
-			context.Writer.Header().Add("Content-Type", "application/json")
-			context.JSON(http.StatusInternalServerError, webResponse)
+	if isValid {
+		// a closed account is only revealed to someone who knows its password
+		reason := model.UserStatusReason(userCheck.UserStatus, userCheck.UserSuspendedUntil, currentTime.Format("2006-01-02 15:04:05"))
+		if reason != "" {
+			h.recordLoginEvent(context, userCheck.UserId, userLoginRequest.UserEmail, model.LoginEventLogin, reason, currentTime)
+			h.accountStatusResponse(context, reason, defaultLang)
 			return
 		}
 
-		expirationTimeRefresh := time.Now().Add(expiredRefresh)
-		claimsRefresh := middleware.Claims{
-			UserId:       userResponse.UserId,
-			UserName:     userResponse.UserName,
-			UserEmail:    userResponse.UserEmail,
-			UserLangCode: userResponse.UserLangCode,
-			StandardClaims: jwt.StandardClaims{
-				ExpiresAt: expirationTimeRefresh.Unix(),
-			},
-		}
-		tokenRefresgh := jwt.NewWithClaims(jwt.SigningMethodHS256, claimsRefresh)
-		tokenStringRefresh, err := tokenRefresgh.SignedString(jwtKey)
-
-		if err != nil {
-			webResponse := helper.WebResponse{
-				Code:   http.StatusInternalServerError,
-				Status: h.TranslationService.Translation(context, "internal_server_error", defaultLang),
-				Data:   err,
+		// the password is at hand only now, so this is where hashes of an older algorithm or cost get upgraded
+		if needsRehash {
+			// a failed rehash keeps the old hash, the login itself has succeeded and the next one tries again
+			hashedPassword, err := h.PasswordPolicy.Hash(userLoginRequest.UserPassword)
+			if err != nil {
+				log.Println("Password could not be rehashed.", err)
+			} else {
+				h.UserService.UpdatePasswordHash(context, userCheck.UserId, hashedPassword)
 			}
+		}
 
-			context.Writer.Header().Add("Content-Type", "application/json")
-			context.JSON(http.StatusInternalServerError, webResponse)
+		// with two factor enabled the password only earns a challenge, the email counter keeps running until the
+		// code is verified as well
+		if h.UserService.FindTotpByUserId(context, userCheck.UserId).TotpIsEnabled {
+			h.loginChallengeResponse(context, userCheck.UserId, defaultLang)
 			return
 		}
 
-		userResponse.UserToken = tokenString
-		userResponse.UserTokenRefresh = tokenStringRefresh
-
-		userData := model.UserUpdateTokenRequest{}
-
-		userData.UserId = userResponse.UserId
-		userData.UserToken = tokenString
-		userData.UserTokenRefresh = tokenStringRefresh
-		userData.UserLastLogin = currentTime.Format("2006-01-02 15:04:05")
-		userTokenUpdateResponse := h.UserService.UpdateToken(context, userData)
-		//end create JWT
-
-		if userTokenUpdateResponse.UserEmail != "" {
-			webResponse := helper.WebResponse{
-				Code:   200,
-				Status: h.TranslationService.Translation(context, "success_login", defaultLang),
-				Data:   userResponse,
-			}
-			context.Writer.Header().Add("Content-Type", "application/json")
-			context.JSON(200, webResponse)
-		} else {
-			webResponse := helper.WebResponse{
-				Code:   http.StatusInternalServerError,
-				Status: h.TranslationService.Translation(context, "internal_server_error", defaultLang),
-				Data:   err,
-			}
+		h.UserService.ResetLoginAttempt(context, userLoginAttemptRequest)
+		h.completeLogin(context, userCheck.UserId, currentTime)
+	} else {
+		h.recordLoginEvent(context, userCheck.UserId, userLoginRequest.UserEmail, model.LoginEventLogin, "invalid_credentials", currentTime)
 
-			context.Writer.Header().Add("Content-Type", "application/json")
-			context.JSON(http.StatusInternalServerError, webResponse)
+		userLoginAttempt = h.UserService.RecordLoginFailure(context, userLoginAttemptRequest)
+		if userLoginAttempt.IsLocked {
+			h.accountLockedResponse(context, defaultLang)
+			return
 		}
-	} else {
+
 		webResponse := helper.WebResponse{
 			Code:   http.StatusUnauthorized,
 			Status: h.TranslationService.Translation(context, "worng_email_or_password", defaultLang),
@@ -420,80 +446,70 @@ func (h *UserHandler) Login(context *gin.Context) {
 }
 
 func (h *UserHandler) RefreshToken(context *gin.Context) {
-	jwtKey := []byte(h.config.JWT.Key)
+	defaultLang := h.config.DefaultLang
 
 	currentTime := time.Now()
-	userRefreshToken := context.Param("userRefreshToken")
 
-	claims := &middleware.Claims{}
+	// the refresh token is only accepted from the body or the cookie, never from the url
+	userRefreshTokenRequest := model.UserRefreshTokenRequest{}
+	context.ShouldBindJSON(&userRefreshTokenRequest)
 
-	tkn, _ := jwt.ParseWithClaims(userRefreshToken, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+	userRefreshToken := userRefreshTokenRequest.UserTokenRefresh
+	if userRefreshToken == "" {
+		userRefreshToken, _ = context.Cookie(middleware.RefreshTokenCookie)
 
-	if !tkn.Valid {
-		webResponse := helper.WebResponse{
-			Code:   http.StatusUnauthorized,
-			Status: h.TranslationService.Translation(context, "unauthorized", claims.UserLangCode),
+		// a refresh by cookie is as forgeable as any other cookie authenticated request
+		if userRefreshToken != "" && h.config.JWT.AuthCookie && !middleware.ValidCsrfToken(context) {
+			webResponse := helper.WebResponse{
+				Code:   http.StatusForbidden,
+				Status: h.TranslationService.Translation(context, "forbidden", defaultLang),
+				Data:   h.TranslationService.Translation(context, middleware.CsrfTokenInvalid, defaultLang),
+			}
+
+			context.Writer.Header().Add("Content-Type", "application/json")
+			context.JSON(http.StatusForbidden, webResponse)
+			return
 		}
+	}
 
-		context.Writer.Header().Add("Content-Type", "application/json")
-		context.JSON(http.StatusUnauthorized, webResponse)
+	claims, reason := middleware.ParseToken(h.config, h.KeySet, userRefreshToken, middleware.TokenTypeRefresh)
+	if reason != "" {
+		h.unauthorizedResponse(context, reason, defaultLang)
 		return
 	}
 
-	userCheck := h.UserService.FindByTokenRefresh(context, userRefreshToken)
+	userRefreshTokenRequest.SessionId = claims.SessionId
+	userRefreshTokenRequest.UserId = claims.UserId
+	userRefreshTokenRequest.UserTokenRefresh = userRefreshToken
+	userRefreshTokenRequest.RevokedAt = currentTime.Format("2006-01-02 15:04:05")
 
-	if userCheck.UserId != 0 {
-
-		userResponse := h.UserService.FindById(context, userCheck.UserId)
+	userCheck, isReused := h.UserService.UseTokenRefresh(context, userRefreshTokenRequest)
 
-		expired := h.config.JWT.Expired
-		expiredRefresh := h.config.JWT.ExpiredRefresh
+	if isReused {
+		h.refreshTokenReusedResponse(context, claims.UserId, claims.UserLangCode, currentTime)
+		return
+	}
 
-		// start cretae JWT
-		expirationTime := time.Now().Add(expired)
-		claims := middleware.Claims{
-			UserId:       userResponse.UserId,
-			UserName:     userResponse.UserName,
-			UserEmail:    userResponse.UserEmail,
-			UserLangCode: userResponse.UserLangCode,
-			StandardClaims: jwt.StandardClaims{
-				ExpiresAt: expirationTime.Unix(),
-			},
-		}
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenString, err := token.SignedString(jwtKey)
+	if userCheck.UserId != 0 {
 
-		if err != nil {
-			webResponse := helper.WebResponse{
-				Code:   http.StatusInternalServerError,
-				Status: h.TranslationService.Translation(context, "internal_server_error", claims.UserLangCode),
-				Data:   err,
-			}
+		userResponse := h.UserService.FindById(context, userCheck.UserId)
 
-			context.Writer.Header().Add("Content-Type", "application/json")
-			context.JSON(http.StatusInternalServerError, webResponse)
+		reason := model.UserStatusReason(userResponse.UserStatus, userResponse.UserSuspendedUntil, currentTime.Format("2006-01-02 15:04:05"))
+		if reason != "" {
+			h.recordLoginEvent(context, userResponse.UserId, userResponse.UserEmail, model.LoginEventRefresh, reason, currentTime)
+			h.accountStatusResponse(context, reason, userResponse.UserLangCode)
 			return
 		}
 
-		expirationTimeRefresh := time.Now().Add(expiredRefresh)
-		claimsRefresh := middleware.Claims{
-			UserId:       userResponse.UserId,
-			UserName:     userResponse.UserName,
-			UserEmail:    userResponse.UserEmail,
-			UserLangCode: userResponse.UserLangCode,
-			StandardClaims: jwt.StandardClaims{
-				ExpiresAt: expirationTimeRefresh.Unix(),
-			},
-		}
-		tokenRefresgh := jwt.NewWithClaims(jwt.SigningMethodHS256, claimsRefresh)
-		tokenStringRefresh, err := tokenRefresgh.SignedString(jwtKey)
+		userRoles := h.RoleService.FindRoleCodesByUserId(context, userCheck.UserId)
+
+		// start cretae JWT
+		tokenString, tokenStringRefresh, err := h.generateToken(userResponse, userRoles, userCheck.SessionId)
 
 		if err != nil {
 			webResponse := helper.WebResponse{
 				Code:   http.StatusInternalServerError,
-				Status: h.TranslationService.Translation(context, "internal_server_error", claims.UserLangCode),
+				Status: h.TranslationService.Translation(context, "internal_server_error", userResponse.UserLangCode),
 				Data:   err,
 			}
 
@@ -508,24 +524,35 @@ func (h *UserHandler) RefreshToken(context *gin.Context) {
 		userData := model.UserUpdateTokenRequest{}
 
 		userData.UserId = userResponse.UserId
+		userData.SessionId = userCheck.SessionId
 		userData.UserToken = tokenString
 		userData.UserTokenRefresh = tokenStringRefresh
+		userData.UserTokenRefreshUsed = userRefreshToken
 		userData.UserLastLogin = currentTime.Format("2006-01-02 15:04:05")
-		userTokenUpdateResponse := h.UserService.UpdateToken(context, userData)
+		userTokenUpdateResponse, isReused := h.UserService.RotateToken(context, userData)
 		//end create JWT
 
+		// a concurrent refresh with the same token got there first
+		if isReused {
+			h.refreshTokenReusedResponse(context, claims.UserId, claims.UserLangCode, currentTime)
+			return
+		}
+
 		if userTokenUpdateResponse.UserEmail != "" {
+			h.recordLoginEvent(context, userResponse.UserId, userResponse.UserEmail, model.LoginEventRefresh, "", currentTime)
+			h.setTokenCookies(context, tokenString, tokenStringRefresh)
+
 			webResponse := helper.WebResponse{
 				Code:   200,
-				Status: h.TranslationService.Translation(context, "refresh_token_success", claims.UserLangCode),
-				Data:   userResponse,
+				Status: h.TranslationService.Translation(context, "refresh_token_success", userResponse.UserLangCode),
+				Data:   h.hideTokens(userResponse),
 			}
 			context.Writer.Header().Add("Content-Type", "application/json")
 			context.JSON(200, webResponse)
 		} else {
 			webResponse := helper.WebResponse{
 				Code:   http.StatusInternalServerError,
-				Status: h.TranslationService.Translation(context, "internal_server_error", claims.UserLangCode),
+				Status: h.TranslationService.Translation(context, "internal_server_error", userResponse.UserLangCode),
 				Data:   err,
 			}
 
@@ -533,28 +560,27 @@ func (h *UserHandler) RefreshToken(context *gin.Context) {
 			context.JSON(http.StatusInternalServerError, webResponse)
 		}
 	} else {
-		webResponse := helper.WebResponse{
-			Code:   http.StatusUnauthorized,
-			Status: h.TranslationService.Translation(context, "unauthorized", claims.UserLangCode),
-		}
-
-		context.Writer.Header().Add("Content-Type", "application/json")
-		context.JSON(http.StatusUnauthorized, webResponse)
+		h.recordLoginEvent(context, claims.UserId, "", model.LoginEventRefresh, middleware.TokenRevoked, currentTime)
+		h.unauthorizedResponse(context, middleware.TokenRevoked, claims.UserLangCode)
 	}
 }
 
 func (h *UserHandler) Logout(context *gin.Context) {
 	payloadJwt := helper.PayloadJwt(context)
 
-	userId := 0
-	value, ok := context.Get("user_id")
-	if ok {
-		userId = value.(int)
-	}
+	userLogoutRequest := model.UserSessionRevokeRequest{}
+	userLogoutRequest.SessionId = payloadJwt.SessionId
+	userLogoutRequest.SessionUserId = payloadJwt.UserId
+
+	currentTime := time.Now()
+	userLogoutRequest.SessionRevokedAt = currentTime.Format("2006-01-02 15:04:05")
 
-	userResponse := h.UserService.Logout(context, userId)
+	userResponse := h.UserService.Logout(context, userLogoutRequest)
 
 	if userResponse.UserId != 0 {
+		h.recordLoginEvent(context, payloadJwt.UserId, payloadJwt.UserEmail, model.LoginEventLogout, "", currentTime)
+		h.setTokenCookies(context, "", "")
+
 		webResponse := helper.WebResponse{
 			Code:   200,
 			Status: h.TranslationService.Translation(context, "success_logout", payloadJwt.UserLangCode),
@@ -573,3 +599,199 @@ func (h *UserHandler) Logout(context *gin.Context) {
 		context.JSON(http.StatusNotFound, webResponse)
 	}
 }
+
+// completeLogin opens a new session for the user and answers with its token pair, every login method ends here
+func (h *UserHandler) completeLogin(context *gin.Context, userId int, currentTime time.Time) {
+	defaultLang := h.config.DefaultLang
+
+	userResponse := h.UserService.FindById(context, userId)
+
+	reason := model.UserStatusReason(userResponse.UserStatus, userResponse.UserSuspendedUntil, currentTime.Format("2006-01-02 15:04:05"))
+	if reason != "" {
+		h.recordLoginEvent(context, userResponse.UserId, userResponse.UserEmail, model.LoginEventLogin, reason, currentTime)
+		h.accountStatusResponse(context, reason, defaultLang)
+		return
+	}
+
+	userRoles := h.RoleService.FindRoleCodesByUserId(context, userId)
+
+	userSessionRequest := model.UserSessionCreateRequest{}
+	userSessionRequest.SessionUserId = userId
+	userSessionRequest.SessionUserAgent = helper.Truncate(context.Request.UserAgent(), 255)
+	userSessionRequest.SessionIp = context.ClientIP()
+	userSessionRequest.SessionCreatedAt = currentTime.Format("2006-01-02 15:04:05")
+	userSession := h.UserService.CreateSession(context, userSessionRequest)
+
+	// start cretae JWT
+	tokenString, tokenStringRefresh, err := h.generateToken(userResponse, userRoles, userSession.SessionId)
+
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusInternalServerError,
+			Status: h.TranslationService.Translation(context, "internal_server_error", defaultLang),
+			Data:   err,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusInternalServerError, webResponse)
+		return
+	}
+
+	userResponse.UserToken = tokenString
+	userResponse.UserTokenRefresh = tokenStringRefresh
+
+	userData := model.UserUpdateTokenRequest{}
+
+	userData.UserId = userResponse.UserId
+	userData.SessionId = userSession.SessionId
+	userData.UserToken = tokenString
+	userData.UserTokenRefresh = tokenStringRefresh
+	userData.UserLastLogin = currentTime.Format("2006-01-02 15:04:05")
+	userTokenUpdateResponse := h.UserService.UpdateToken(context, userData)
+	//end create JWT
+
+	if userTokenUpdateResponse.UserEmail != "" {
+		loginEvent := h.recordLoginEvent(context, userResponse.UserId, userResponse.UserEmail, model.LoginEventLogin, "", currentTime)
+		if loginEvent.IsNewDevice {
+			h.sendMail(context, userResponse, "new_device_login_email_subject", "new_device_login_email_body", loginEvent.EventCreatedAt+"\n"+loginEvent.EventIp+"\n"+loginEvent.EventUserAgent)
+		}
+
+		h.setTokenCookies(context, tokenString, tokenStringRefresh)
+
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_login", defaultLang),
+			Data:   h.hideTokens(userResponse),
+		}
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusInternalServerError,
+			Status: h.TranslationService.Translation(context, "internal_server_error", defaultLang),
+			Data:   err,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusInternalServerError, webResponse)
+	}
+}
+
+// generateToken signs the access and refresh token pair of a session, every token gets its own jti
+func (h *UserHandler) generateToken(userResponse model.UserResponse, userRoles []string, sessionId int) (string, string, error) {
+	expirationTime := time.Now().Add(h.config.JWT.Expired)
+	claims := middleware.Claims{
+		UserId:         userResponse.UserId,
+		UserName:       userResponse.UserName,
+		UserEmail:      userResponse.UserEmail,
+		UserLangCode:   userResponse.UserLangCode,
+		UserRoles:      userRoles,
+		SessionId:      sessionId,
+		TokenType:      middleware.TokenTypeAccess,
+		StandardClaims: middleware.NewStandardClaims(h.config, expirationTime),
+	}
+	tokenString, err := h.KeySet.Sign(claims)
+	if err != nil {
+		return "", "", err
+	}
+
+	expirationTimeRefresh := time.Now().Add(h.config.JWT.ExpiredRefresh)
+	claimsRefresh := middleware.Claims{
+		UserId:         userResponse.UserId,
+		UserLangCode:   userResponse.UserLangCode,
+		SessionId:      sessionId,
+		TokenType:      middleware.TokenTypeRefresh,
+		StandardClaims: middleware.NewStandardClaims(h.config, expirationTimeRefresh),
+	}
+	tokenStringRefresh, err := h.KeySet.Sign(claimsRefresh)
+	if err != nil {
+		return "", "", err
+	}
+
+	return tokenString, tokenStringRefresh, nil
+}
+
+// unauthorizedResponse answers a refused token with a 401 whose data tells the client why
+func (h *UserHandler) unauthorizedResponse(context *gin.Context, reason string, langCode string) {
+	webResponse := helper.WebResponse{
+		Code:   http.StatusUnauthorized,
+		Status: h.TranslationService.Translation(context, "unauthorized", langCode),
+		Data:   h.TranslationService.Translation(context, reason, langCode),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(http.StatusUnauthorized, webResponse)
+}
+
+// refreshTokenReusedResponse answers a replayed refresh token, its session is already revoked by then
+func (h *UserHandler) refreshTokenReusedResponse(context *gin.Context, userId int, langCode string, currentTime time.Time) {
+	h.recordLoginEvent(context, userId, "", model.LoginEventRefresh, "refresh_token_reused", currentTime)
+
+	webResponse := helper.WebResponse{
+		Code:   http.StatusUnauthorized,
+		Status: h.TranslationService.Translation(context, "refresh_token_reused", langCode),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(http.StatusUnauthorized, webResponse)
+}
+
+// setTokenCookies hands the tokens to browsers as HttpOnly cookies. The refresh token cookie is scoped to the refresh
+// route and set with JWT.REFRESH_COOKIE or JWT.AUTH_COOKIE, the cookie auth mode adds the access token and a fresh
+// csrf token readable by scripts. Empty tokens expire the cookies.
+func (h *UserHandler) setTokenCookies(context *gin.Context, tokenString string, tokenStringRefresh string) {
+	if !h.config.JWT.RefreshCookie && !h.config.JWT.AuthCookie {
+		return
+	}
+
+	isSecure := h.config.Mode != "development"
+	maxAge := int(h.config.JWT.ExpiredRefresh.Seconds())
+	if tokenStringRefresh == "" {
+		maxAge = -1
+	}
+
+	context.SetSameSite(middleware.CookieSameSite(h.config))
+	context.SetCookie(middleware.RefreshTokenCookie, tokenStringRefresh, maxAge, "/api/v1/users/refresh-token", h.config.JWT.CookieDomain, isSecure, true)
+
+	if h.config.JWT.AuthCookie {
+		csrfToken := ""
+		if tokenString != "" {
+			csrfToken = helper.GenerateRandomToken(32)
+		}
+
+		context.SetCookie(middleware.AccessTokenCookie, tokenString, maxAge, "/", h.config.JWT.CookieDomain, isSecure, true)
+		context.SetCookie(middleware.CsrfTokenCookie, csrfToken, maxAge, "/", h.config.JWT.CookieDomain, isSecure, false)
+	}
+}
+
+// hideTokens keeps the tokens out of the body in the cookie auth mode, scripts must not be able to read them
+func (h *UserHandler) hideTokens(userResponse model.UserResponse) model.UserResponse {
+	if h.config.JWT.AuthCookie {
+		userResponse.UserToken = ""
+		userResponse.UserTokenRefresh = ""
+	}
+
+	return userResponse
+}
+
+// sendMail localizes the subject and body keys in the user language and sends them in the background, the link (or
+// any other detail the message needs) is appended below the body
+func (h *UserHandler) sendMail(context *gin.Context, userResponse model.UserResponse, subjectKey string, bodyKey string, link string) {
+	langCode := userResponse.UserLangCode
+	if langCode == "" {
+		langCode = h.config.DefaultLang
+	}
+
+	subject := h.TranslationService.Translation(context, subjectKey, langCode)
+	body := h.TranslationService.Translation(context, bodyKey, langCode)
+	if link != "" {
+		body += "\n\n" + link
+	}
+
+	go func(to string) {
+		err := h.Mailer.Send(to, subject, body)
+		if err != nil {
+			log.Println("Failed sending mail.", err)
+		}
+	}(userResponse.UserEmail)
+}
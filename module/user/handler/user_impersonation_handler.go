@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"collapp/transport/http/middleware"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Impersonate signs a short lived access token of the user for the signed in actor. The token carries the actor in
+// its act claim, gets no refresh token and belongs to a session of its own that StopImpersonation revokes.
+func (h *UserHandler) Impersonate(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userImpersonationCreateRequest := model.UserImpersonationCreateRequest{}
+	context.ShouldBindJSON(&userImpersonationCreateRequest)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	currentTime := time.Now()
+	userImpersonationCreateRequest.ImpersonationActorId = payloadJwt.UserId
+	userImpersonationCreateRequest.ImpersonationUserId = id
+	userImpersonationCreateRequest.SessionUserAgent = helper.Truncate(context.Request.UserAgent(), 255)
+	userImpersonationCreateRequest.SessionIp = context.ClientIP()
+	userImpersonationCreateRequest.ImpersonationStartedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	err = h.Validate.Struct(userImpersonationCreateRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	// no impersonating yourself, from within an impersonation, anyone who could impersonate in turn or anyone holding a
+	// permission the actor lacks
+	userRoles := h.RoleService.FindRoleCodesByUserId(context, id)
+	actorRoles := h.RoleService.FindRoleCodesByUserId(context, payloadJwt.UserId)
+	if id == payloadJwt.UserId || payloadJwt.IsImpersonated || payloadJwt.ApiKeyId != 0 || h.RoleService.HasPermission(context, userRoles, "users.impersonate") || !h.RoleService.HasAllPermissions(context, actorRoles, userRoles) {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusForbidden,
+			Status: h.TranslationService.Translation(context, "impersonation_not_allowed", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusForbidden, webResponse)
+		return
+	}
+
+	userImpersonationResponse := h.UserService.StartImpersonation(context, userImpersonationCreateRequest)
+	if userImpersonationResponse.ImpersonationId == 0 {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+		return
+	}
+
+	userResponse := h.UserService.FindById(context, id)
+
+	expirationTime := currentTime.Add(h.config.Impersonation.Expired)
+	claims := middleware.Claims{
+		UserId:       userResponse.UserId,
+		UserName:     userResponse.UserName,
+		UserEmail:    userResponse.UserEmail,
+		UserLangCode: userResponse.UserLangCode,
+		UserRoles:    userRoles,
+		SessionId:    userImpersonationResponse.ImpersonationSessionId,
+		TokenType:    middleware.TokenTypeAccess,
+		Act: &middleware.ActorClaims{
+			UserId:    payloadJwt.UserId,
+			UserName:  payloadJwt.UserName,
+			SessionId: payloadJwt.SessionId,
+		},
+		StandardClaims: middleware.NewStandardClaims(h.config, expirationTime),
+	}
+	tokenString, err := h.KeySet.Sign(claims)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusInternalServerError,
+			Status: h.TranslationService.Translation(context, "internal_server_error", payloadJwt.UserLangCode),
+			Data:   err,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusInternalServerError, webResponse)
+		return
+	}
+
+	h.UserService.UpdateImpersonationToken(context, userImpersonationResponse.ImpersonationSessionId, tokenString, currentTime.Format("2006-01-02 15:04:05"))
+
+	userImpersonationResponse.UserToken = tokenString
+	userImpersonationResponse.UserTokenExpiredAt = expirationTime.Format("2006-01-02 15:04:05")
+
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "success_start_impersonation", payloadJwt.UserLangCode),
+		Data:   userImpersonationResponse,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+// StopImpersonation is called with the impersonation token, it ends the impersonation and revokes its session. The
+// actor goes on with the tokens of their own session.
+func (h *UserHandler) StopImpersonation(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userImpersonationStopRequest := model.UserImpersonationStopRequest{}
+	userImpersonationStopRequest.ImpersonationActorId = payloadJwt.ActorId
+	userImpersonationStopRequest.ImpersonationSessionId = payloadJwt.SessionId
+
+	currentTime := time.Now()
+	userImpersonationStopRequest.ImpersonationEndedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	userImpersonationResponse := model.UserImpersonationResponse{}
+	if payloadJwt.IsImpersonated {
+		userImpersonationResponse = h.UserService.StopImpersonation(context, userImpersonationStopRequest)
+	}
+
+	if userImpersonationResponse.ImpersonationId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_stop_impersonation", payloadJwt.UserLangCode),
+			Data:   userImpersonationResponse,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "not_impersonating", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+	}
+}
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// userImportMaxRows bounds one import, larger onboardings are split over several files
+const userImportMaxRows = 1000
+
+// userImportColumns maps the header of the CSV file to the fields of UserCreateRequest, role is optional
+var userImportColumns = map[string]string{
+	"name":     "UserName",
+	"email":    "UserEmail",
+	"language": "UserLangCode",
+	"role":     "RoleCode",
+}
+
+// Import creates users from an uploaded CSV file with a name,email,language[,role] header. Every row is validated like
+// Create and reported with its errors, the valid rows are created in one transaction and invited unless dry_run is set.
+// A role is only accepted from callers who may assign roles anyway.
+func (h *UserHandler) Import(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	isDryRun, _ := strconv.ParseBool(context.Query("dry_run"))
+	currentTime := time.Now()
+
+	userImportRequests, userImportResponse, err := h.readImport(context, payloadJwt.ActorId, h.canAssignRoles(context, payloadJwt), currentTime)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userImportResponse.IsDryRun = isDryRun
+	status := "success_validate_user_import"
+
+	if !isDryRun && len(userImportRequests) > 0 {
+		userResponses := h.UserService.Import(context, userImportRequests)
+
+		// the users are committed by now, a failed invitation is reported on its row and can be resent later
+		index := 0
+		for rowIndex, row := range userImportResponse.Rows {
+			if len(row.Errors) == 0 {
+				userImportResponse.Rows[rowIndex].UserId = userResponses[index].UserId
+				index++
+
+				_, err = h.sendInvitation(context, userImportResponse.Rows[rowIndex].UserId, payloadJwt.ActorId, currentTime)
+				if err != nil {
+					log.Println("Invitation of imported user failed.", err)
+					userImportResponse.Rows[rowIndex].Errors = append(userImportResponse.Rows[rowIndex].Errors, model.UserImportInvitationFailed)
+					userImportResponse.Uninvited++
+				}
+			}
+		}
+		userImportResponse.Imported = len(userResponses)
+
+		status = "success_import_user"
+	}
+
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, status, payloadJwt.UserLangCode),
+		Data:   userImportResponse,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+// readImport reads and checks the rows of the uploaded file, it returns the requests of the valid rows and the report
+// of every row. An error means the file itself can not be read.
+func (h *UserHandler) readImport(context *gin.Context, createdBy int, canAssignRoles bool, currentTime time.Time) ([]model.UserImportRequest, model.UserImportResponse, error) {
+	var userImportRequests []model.UserImportRequest
+	userImportResponse := model.UserImportResponse{}
+
+	fileHeader, err := context.FormFile("file")
+	if err != nil {
+		return nil, userImportResponse, err
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, userImportResponse, err
+	}
+	defer file.Close()
+
+	csvReader := csv.NewReader(file)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, userImportResponse, errors.New("the file has no header")
+	}
+
+	columns := map[string]int{}
+	for index, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if _, ok := userImportColumns[column]; !ok {
+			return nil, userImportResponse, errors.New("unknown column " + column)
+		}
+		columns[column] = index
+	}
+	for _, column := range []string{"name", "email", "language"} {
+		if _, ok := columns[column]; !ok {
+			return nil, userImportResponse, errors.New("missing column " + column)
+		}
+	}
+
+	value := func(record []string, column string) string {
+		index, ok := columns[column]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	emails := map[string]bool{}
+	langs := map[string]bool{}
+	roles := map[string]bool{}
+	for row := 2; ; row++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, userImportResponse, err
+		}
+		if row > userImportMaxRows+1 {
+			return nil, userImportResponse, errors.New("the file has more than " + strconv.Itoa(userImportMaxRows) + " rows")
+		}
+
+		userImportRequest := model.UserImportRequest{}
+		userImportRequest.UserName = value(record, "name")
+		userImportRequest.UserEmail = value(record, "email")
+		userImportRequest.UserLangCode = value(record, "language")
+		userImportRequest.RoleCode = value(record, "role")
+		userImportRequest.UserStatus = model.UserStatusPending
+		userImportRequest.CreatedBy = createdBy
+		userImportRequest.CreatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+		userImportRowResponse := model.UserImportRowResponse{
+			Row:       row,
+			UserEmail: userImportRequest.UserEmail,
+		}
+
+		err = h.Validate.Struct(userImportRequest.UserCreateRequest)
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			for _, fieldError := range validationErrors {
+				userImportRowResponse.Errors = append(userImportRowResponse.Errors, userImportColumn(fieldError.Field())+" "+fieldError.Tag())
+			}
+		}
+
+		email := strings.ToLower(userImportRequest.UserEmail)
+		if email != "" {
+			if emails[email] {
+				userImportRowResponse.Errors = append(userImportRowResponse.Errors, model.UserImportDuplicateEmail)
+			} else if h.UserService.FindByEmail(context, userImportRequest.UserEmail).UserId != 0 {
+				userImportRowResponse.Errors = append(userImportRowResponse.Errors, model.UserImportEmailTaken)
+			}
+			emails[email] = true
+		}
+
+		if langCode := userImportRequest.UserLangCode; langCode != "" {
+			if _, ok := langs[langCode]; !ok {
+				langs[langCode] = h.LangService.CheckLangCodeExist(context, langCode)
+			}
+			if !langs[langCode] {
+				userImportRowResponse.Errors = append(userImportRowResponse.Errors, model.UserImportUnknownLanguage)
+			}
+		}
+
+		if roleCode := userImportRequest.RoleCode; roleCode != "" && !canAssignRoles {
+			userImportRowResponse.Errors = append(userImportRowResponse.Errors, model.UserImportRoleNotAllowed)
+		} else if roleCode != "" {
+			if _, ok := roles[roleCode]; !ok {
+				roles[roleCode] = h.RoleService.CheckRoleCodeExist(context, roleCode)
+			}
+			if !roles[roleCode] {
+				userImportRowResponse.Errors = append(userImportRowResponse.Errors, model.UserImportUnknownRole)
+			}
+		}
+
+		if len(userImportRowResponse.Errors) == 0 {
+			userImportRequests = append(userImportRequests, userImportRequest)
+			userImportResponse.Valid++
+		} else {
+			userImportResponse.Invalid++
+		}
+		userImportResponse.Rows = append(userImportResponse.Rows, userImportRowResponse)
+		userImportResponse.Total++
+	}
+
+	if userImportResponse.Total == 0 {
+		return nil, userImportResponse, errors.New("the file has no rows")
+	}
+
+	return userImportRequests, userImportResponse, nil
+}
+
+// canAssignRoles tells whether the caller holds roles.manage, the permission PUT /users/:userId/roles asks for. An API
+// key needs it among its scopes as well.
+func (h *UserHandler) canAssignRoles(context *gin.Context, payloadJwt model.User) bool {
+	if !h.RoleService.HasPermission(context, payloadJwt.UserRoles, "roles.manage") {
+		return false
+	}
+	if payloadJwt.ApiKeyId == 0 {
+		return true
+	}
+
+	for _, scope := range payloadJwt.ApiKeyScopes {
+		if scope == "roles.manage" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// userImportColumn names a field of UserCreateRequest by its column in the file
+func userImportColumn(field string) string {
+	for column, name := range userImportColumns {
+		if name == field {
+			return column
+		}
+	}
+
+	return field
+}
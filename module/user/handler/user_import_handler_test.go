@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"bytes"
+	langService "collapp/module/lang/service"
+	roleService "collapp/module/role/service"
+	"collapp/module/user/model"
+	"collapp/module/user/service"
+	"context"
+	"mime/multipart"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// the import only looks up users, languages and roles, the embedded interfaces panic on any other call
+
+type importTestUserService struct {
+	service.UserService
+}
+
+func (s importTestUserService) FindByEmail(ctx context.Context, userEmail string) model.UserLoginResponse {
+	if strings.EqualFold(userEmail, "taken@example.com") {
+		return model.UserLoginResponse{UserId: 1}
+	}
+
+	return model.UserLoginResponse{}
+}
+
+type importTestLangService struct {
+	langService.LangService
+}
+
+func (s importTestLangService) CheckLangCodeExist(ctx context.Context, langCode string) bool {
+	return langCode == "en" || langCode == "id"
+}
+
+type importTestRoleService struct {
+	roleService.RoleService
+}
+
+func (s importTestRoleService) CheckRoleCodeExist(ctx context.Context, roleCode string) bool {
+	return roleCode == "admin"
+}
+
+func newImportTestHandler() *UserHandler {
+	return &UserHandler{
+		UserService: importTestUserService{},
+		Validate:    validator.New(),
+		LangService: importTestLangService{},
+		RoleService: importTestRoleService{},
+	}
+}
+
+// newImportTestContext uploads content as the file field of a multipart form, an empty content sends no file
+func newImportTestContext(t *testing.T, content string) *gin.Context {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if content != "" {
+		part, err := writer.CreateFormFile("file", "users.csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(content))
+	}
+	writer.Close()
+
+	gin.SetMode(gin.TestMode)
+	context, _ := gin.CreateTestContext(httptest.NewRecorder())
+	context.Request = httptest.NewRequest("POST", "/users/import", body)
+	context.Request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return context
+}
+
+func TestUserImportReport(t *testing.T) {
+	content := "\ufeffName, EMAIL ,language,role\n" +
+		"Alice,alice@example.com,en,\n" +
+		"Bob,bob@example.com,xx,\n" +
+		",carol@example.com,en,\n" +
+		"Dan,not-an-email,en,\n" +
+		"Eve,ALICE@example.com,en,\n" +
+		"Fay,taken@example.com,en,\n" +
+		"Gus,gus@example.com,en,admin\n" +
+		"Hal,hal@example.com,id,ghost\n" +
+		"Ivy,ivy@example.com,,\n" +
+		"  Joe  ,  joe@example.com,  id  ,\n"
+
+	tests := []struct {
+		name           string
+		canAssignRoles bool
+		gusErrors      []string
+		halErrors      []string
+	}{
+		{name: "may assign roles", canAssignRoles: true, halErrors: []string{model.UserImportUnknownRole}},
+		{name: "may not assign roles", gusErrors: []string{model.UserImportRoleNotAllowed}, halErrors: []string{model.UserImportRoleNotAllowed}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			currentTime := time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)
+			userImportRequests, userImportResponse, err := newImportTestHandler().readImport(newImportTestContext(t, content), 5, test.canAssignRoles, currentTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedRows := []model.UserImportRowResponse{
+				{Row: 2, UserEmail: "alice@example.com"},
+				{Row: 3, UserEmail: "bob@example.com", Errors: []string{model.UserImportUnknownLanguage}},
+				{Row: 4, UserEmail: "carol@example.com", Errors: []string{"name required"}},
+				{Row: 5, UserEmail: "not-an-email", Errors: []string{"email email"}},
+				{Row: 6, UserEmail: "ALICE@example.com", Errors: []string{model.UserImportDuplicateEmail}},
+				{Row: 7, UserEmail: "taken@example.com", Errors: []string{model.UserImportEmailTaken}},
+				{Row: 8, UserEmail: "gus@example.com", Errors: test.gusErrors},
+				{Row: 9, UserEmail: "hal@example.com", Errors: test.halErrors},
+				{Row: 10, UserEmail: "ivy@example.com", Errors: []string{"language required"}},
+				{Row: 11, UserEmail: "joe@example.com"},
+			}
+			if !reflect.DeepEqual(userImportResponse.Rows, expectedRows) {
+				t.Errorf("Rows = %+v, want %+v", userImportResponse.Rows, expectedRows)
+			}
+
+			valid := 2
+			if test.canAssignRoles {
+				valid = 3
+			}
+			if userImportResponse.Total != 10 || userImportResponse.Valid != valid || userImportResponse.Invalid != 10-valid {
+				t.Errorf("Total, Valid, Invalid = %d, %d, %d", userImportResponse.Total, userImportResponse.Valid, userImportResponse.Invalid)
+			}
+
+			if len(userImportRequests) != valid {
+				t.Fatalf("%d requests, want %d", len(userImportRequests), valid)
+			}
+			joe := userImportRequests[len(userImportRequests)-1]
+			if joe.UserName != "Joe" || joe.UserLangCode != "id" || joe.UserStatus != model.UserStatusPending || joe.CreatedBy != 5 || joe.CreatedAt != "2022-10-01 08:00:00" {
+				t.Errorf("request = %+v", joe)
+			}
+			if test.canAssignRoles && userImportRequests[1].RoleCode != "admin" {
+				t.Errorf("request of gus has role %q", userImportRequests[1].RoleCode)
+			}
+		})
+	}
+}
+
+func TestUserImportRejectsFile(t *testing.T) {
+	tooManyRows := "name,email,language\n" + strings.Repeat("Alice,alice@example.com,en\n", userImportMaxRows+1)
+
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "no file", content: "", err: "http: no such file"},
+		{name: "no header", content: "\n", err: "the file has no header"},
+		{name: "unknown column", content: "name,email,language,password\n", err: "unknown column password"},
+		{name: "missing column", content: "name,email\n", err: "missing column language"},
+		{name: "no rows", content: "name,email,language\n", err: "the file has no rows"},
+		{name: "malformed row", content: "name,email,language\nAlice,alice@example.com\n", err: "record on line 2: wrong number of fields"},
+		{name: "too many rows", content: tooManyRows, err: "the file has more than 1000 rows"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := newImportTestHandler().readImport(newImportTestContext(t, test.content), 5, true, time.Now())
+			if err == nil || err.Error() != test.err {
+				t.Errorf("readImport error = %v, want %s", err, test.err)
+			}
+		})
+	}
+}
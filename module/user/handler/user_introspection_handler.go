@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"collapp/transport/http/middleware"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Introspect answers RFC 7662 token introspection for other backend services, so they neither need the signing key
+// nor miss a logout or a revoked session. Only service accounts may call it, with an API key scoped to
+// tokens.introspect. The answer is the bare RFC 7662 object, an unknown, expired or revoked token is {"active":false}.
+func (h *UserHandler) Introspect(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	if payloadJwt.ApiKeyId == 0 {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusForbidden,
+			Status: h.TranslationService.Translation(context, "forbidden", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusForbidden, webResponse)
+		return
+	}
+
+	userIntrospectionRequest := model.UserIntrospectionRequest{}
+	context.ShouldBind(&userIntrospectionRequest)
+
+	err := h.Validate.Struct(userIntrospectionRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	// the hint only decides which type is tried first
+	tokenTypes := []string{middleware.TokenTypeAccess, middleware.TokenTypeRefresh}
+	if userIntrospectionRequest.TokenTypeHint == model.IntrospectionRefreshToken {
+		tokenTypes = []string{middleware.TokenTypeRefresh, middleware.TokenTypeAccess}
+	}
+
+	currentTime := time.Now()
+	introspectionResponse := model.UserIntrospectionResponse{}
+	for _, tokenType := range tokenTypes {
+		claims, reason := middleware.ParseToken(h.config, h.KeySet, userIntrospectionRequest.Token, tokenType)
+		if reason != "" {
+			continue
+		}
+
+		isRefresh := tokenType == middleware.TokenTypeRefresh
+		userResponse := h.UserService.IntrospectSession(context, claims.SessionId, claims.UserId, userIntrospectionRequest.Token, isRefresh)
+		if userResponse.UserId == 0 || model.UserStatusReason(userResponse.UserStatus, userResponse.UserSuspendedUntil, currentTime.Format("2006-01-02 15:04:05")) != "" {
+			break
+		}
+
+		// scope lists the current role codes of the user, the same codes an access token carries in user_roles
+		introspectionResponse.Active = true
+		introspectionResponse.Scope = strings.Join(h.RoleService.FindRoleCodesByUserId(context, userResponse.UserId), " ")
+		introspectionResponse.Username = userResponse.UserEmail
+		introspectionResponse.TokenType = model.IntrospectionAccessToken
+		if isRefresh {
+			introspectionResponse.TokenType = model.IntrospectionRefreshToken
+		}
+		introspectionResponse.Exp = claims.ExpiresAt
+		introspectionResponse.Iat = claims.IssuedAt
+		introspectionResponse.Nbf = claims.NotBefore
+		introspectionResponse.Sub = strconv.Itoa(userResponse.UserId)
+		introspectionResponse.Aud = claims.Audience
+		introspectionResponse.Iss = claims.Issuer
+		introspectionResponse.Jti = claims.Id
+		if claims.Act != nil {
+			introspectionResponse.Act = &model.UserIntrospectionActor{
+				Sub:      strconv.Itoa(claims.Act.UserId),
+				Username: claims.Act.UserName,
+			}
+		}
+		break
+	}
+
+	context.Header("Cache-Control", "no-store")
+	context.JSON(200, introspectionResponse)
+}
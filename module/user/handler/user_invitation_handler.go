@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"collapp/transport/http/middleware"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *UserHandler) ResendInvitation(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userResponse, err := h.sendInvitation(context, id, payloadJwt.ActorId, time.Now())
+	if err != nil {
+		log.Println("Invitation could not be sent.", err)
+
+		webResponse := helper.WebResponse{
+			Code:   http.StatusInternalServerError,
+			Status: h.TranslationService.Translation(context, "internal_server_error", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusInternalServerError, webResponse)
+		return
+	}
+
+	if userResponse.UserId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_send_invitation", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "invitation_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+func (h *UserHandler) RevokeInvitation(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userInvitationRevokeRequest := model.UserInvitationRevokeRequest{}
+	userInvitationRevokeRequest.UserId = id
+	userInvitationRevokeRequest.UpdatedBy = payloadJwt.ActorId
+
+	currentTime := time.Now()
+	userInvitationRevokeRequest.RevokedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	userResponse := h.UserService.RevokeInvitation(context, userInvitationRevokeRequest)
+
+	if userResponse.UserId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_revoke_invitation", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "invitation_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+// AcceptInvitation lets the invited user choose a password, the signed token of the link names the user and the
+// stored id of the token makes sure only the latest, unrevoked invitation is accepted once
+func (h *UserHandler) AcceptInvitation(context *gin.Context) {
+	defaultLang := h.config.DefaultLang
+
+	userInvitationAcceptRequest := model.UserInvitationAcceptRequest{}
+	context.Bind(&userInvitationAcceptRequest)
+
+	err := h.Validate.Struct(userInvitationAcceptRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", defaultLang),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userResponse := model.UserResponse{}
+	claims, reason := middleware.ParseToken(h.config, h.KeySet, userInvitationAcceptRequest.Token, middleware.TokenTypeInvitation)
+	if reason == "" {
+		passwordReason := h.PasswordPolicy.Validate(userInvitationAcceptRequest.NewPassword)
+		if passwordReason != "" {
+			h.passwordRejectedResponse(context, passwordReason, claims.UserLangCode)
+			return
+		}
+
+		hashedPassword, err := h.PasswordPolicy.Hash(userInvitationAcceptRequest.NewPassword)
+		if err != nil {
+			log.Println("Password could not be hashed.", err)
+
+			webResponse := helper.WebResponse{
+				Code:   http.StatusInternalServerError,
+				Status: h.TranslationService.Translation(context, "internal_server_error", claims.UserLangCode),
+			}
+
+			context.Writer.Header().Add("Content-Type", "application/json")
+			context.JSON(http.StatusInternalServerError, webResponse)
+			return
+		}
+
+		userInvitationAcceptRequest.UserId = claims.UserId
+		userInvitationAcceptRequest.InvitationToken = claims.Id
+		userInvitationAcceptRequest.UserPassword = string(hashedPassword)
+
+		currentTime := time.Now()
+		userInvitationAcceptRequest.InvitationAcceptedAt = currentTime.Format("2006-01-02 15:04:05")
+
+		userResponse = h.UserService.AcceptInvitation(context, userInvitationAcceptRequest)
+	}
+
+	if userResponse.UserId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_accept_invitation", userResponse.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "invalid_or_expired_token", defaultLang),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+	}
+}
+
+// sendInvitation signs a new invitation link for a pending user, stores the id of its token and emails it. The
+// returned user is empty when the user is not pending.
+func (h *UserHandler) sendInvitation(context *gin.Context, userId int, createdBy int, currentTime time.Time) (model.UserResponse, error) {
+	expirationTime := currentTime.Add(h.config.Invitation.Expired)
+	claims := middleware.Claims{
+		UserId:         userId,
+		TokenType:      middleware.TokenTypeInvitation,
+		StandardClaims: middleware.NewStandardClaims(h.config, expirationTime),
+	}
+	tokenString, err := h.KeySet.Sign(claims)
+	if err != nil {
+		return model.UserResponse{}, err
+	}
+
+	userInvitationCreateRequest := model.UserInvitationCreateRequest{}
+	userInvitationCreateRequest.UserId = userId
+	userInvitationCreateRequest.InvitationToken = claims.Id
+	userInvitationCreateRequest.InvitationExpiredAt = expirationTime.Format("2006-01-02 15:04:05")
+	userInvitationCreateRequest.CreatedBy = createdBy
+	userInvitationCreateRequest.CreatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	userResponse := h.UserService.CreateInvitation(context, userInvitationCreateRequest)
+
+	if userResponse.UserId != 0 {
+		link := h.config.AppUrl + "/accept-invitation?token=" + tokenString
+		h.sendMail(context, userResponse, "invitation_email_subject", "invitation_email_body", link)
+	}
+
+	return userResponse, nil
+}
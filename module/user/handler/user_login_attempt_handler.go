@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *UserHandler) Unlock(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userResponse := h.UserService.UnlockLogin(context, id)
+
+	if userResponse.UserId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_unlock_user", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+// loginAttemptRequest fills the brute-force thresholds of the login configuration for the email and client ip
+func (h *UserHandler) loginAttemptRequest(context *gin.Context, userEmail string, currentTime time.Time) model.UserLoginAttemptRequest {
+	loginAttemptRequest := model.UserLoginAttemptRequest{}
+	loginAttemptRequest.UserEmail = userEmail
+	loginAttemptRequest.Ip = context.ClientIP()
+	loginAttemptRequest.CurrentTime = currentTime.Format("2006-01-02 15:04:05")
+	loginAttemptRequest.WindowStart = currentTime.Add(-h.config.Login.LockDuration).Format("2006-01-02 15:04:05")
+	loginAttemptRequest.LockedUntil = currentTime.Add(h.config.Login.LockDuration).Format("2006-01-02 15:04:05")
+	loginAttemptRequest.MaxAttempts = h.config.Login.MaxAttempts
+	loginAttemptRequest.MaxAttemptsIp = h.config.Login.MaxAttemptsIp
+
+	return loginAttemptRequest
+}
+
+// loginDelay grows by one DelayStep for every recent failure and is capped at MaxDelay
+func (h *UserHandler) loginDelay(failures int) time.Duration {
+	delay := h.config.Login.DelayStep * time.Duration(failures)
+	if h.config.Login.MaxDelay > 0 && delay > h.config.Login.MaxDelay {
+		delay = h.config.Login.MaxDelay
+	}
+
+	return delay
+}
+
+func (h *UserHandler) accountLockedResponse(context *gin.Context, langCode string) {
+	webResponse := helper.WebResponse{
+		Code:   http.StatusLocked,
+		Status: h.TranslationService.Translation(context, "account_locked", langCode),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(http.StatusLocked, webResponse)
+}
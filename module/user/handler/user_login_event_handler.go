@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *UserHandler) FindMyLoginEvent(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	h.findLoginEvent(context, payloadJwt.UserId, payloadJwt.UserLangCode)
+}
+
+func (h *UserHandler) FindLoginEvent(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	h.findLoginEvent(context, id, payloadJwt.UserLangCode)
+}
+
+// findLoginEvent pages through the login events of the user with the list query, sort=-event_id shows the newest first
+func (h *UserHandler) findLoginEvent(context *gin.Context, userId int, langCode string) {
+	listQuery, err := helper.NewListQuery(context, model.UserLoginEventListSorts, model.UserLoginEventListFilters, "event_id")
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", langCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	eventResponses, pagination := h.UserService.FindLoginEventByUserId(context, userId, listQuery)
+
+	if len(eventResponses) > 0 {
+		webResponse := helper.WebResponse{
+			Code:       200,
+			Status:     h.TranslationService.Translation(context, "success_get_login_event", langCode),
+			Data:       eventResponses,
+			Pagination: &pagination,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:       http.StatusNotFound,
+			Status:     h.TranslationService.Translation(context, "data_not_found", langCode),
+			Data:       nil,
+			Pagination: &pagination,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+// recordLoginEvent stores a login, refresh or logout of the client, an empty reason marks it successful
+func (h *UserHandler) recordLoginEvent(context *gin.Context, userId int, userEmail string, eventType string, reason string, currentTime time.Time) model.UserLoginEventResponse {
+	userLoginEventCreateRequest := model.UserLoginEventCreateRequest{}
+	userLoginEventCreateRequest.EventUserId = userId
+	userLoginEventCreateRequest.EventEmail = helper.Truncate(userEmail, 200)
+	userLoginEventCreateRequest.EventType = eventType
+	userLoginEventCreateRequest.EventIsSuccess = reason == ""
+	userLoginEventCreateRequest.EventReason = reason
+	userLoginEventCreateRequest.EventIp = context.ClientIP()
+	userLoginEventCreateRequest.EventUserAgent = helper.Truncate(context.Request.UserAgent(), 255)
+	userLoginEventCreateRequest.EventCreatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	return h.UserService.RecordLoginEvent(context, userLoginEventCreateRequest)
+}
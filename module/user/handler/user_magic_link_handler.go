@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"collapp/transport/http/middleware"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MagicLinkLogin emails a signed single-use login link to the owner of the email. Unknown, closed and rate limited
+// emails get the same answer and no mail, so the endpoint can not be used to probe accounts.
+func (h *UserHandler) MagicLinkLogin(context *gin.Context) {
+	defaultLang := h.config.DefaultLang
+
+	userMagicLinkRequest := model.UserMagicLinkRequest{}
+	context.Bind(&userMagicLinkRequest)
+
+	err := h.Validate.Struct(userMagicLinkRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", defaultLang),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	currentTime := time.Now()
+	userCheck := h.UserService.FindByEmail(context, userMagicLinkRequest.UserEmail)
+
+	if userCheck.UserId != 0 && model.UserStatusReason(userCheck.UserStatus, userCheck.UserSuspendedUntil, currentTime.Format("2006-01-02 15:04:05")) == "" {
+		expirationTime := currentTime.Add(h.config.MagicLink.Expired)
+		claims := middleware.Claims{
+			UserId:         userCheck.UserId,
+			UserLangCode:   userCheck.UserLangCode,
+			TokenType:      middleware.TokenTypeMagicLink,
+			StandardClaims: middleware.NewStandardClaims(h.config, expirationTime),
+		}
+		tokenString, err := h.KeySet.Sign(claims)
+		if err != nil {
+			log.Println("Magic link could not be signed.", err)
+
+			webResponse := helper.WebResponse{
+				Code:   http.StatusInternalServerError,
+				Status: h.TranslationService.Translation(context, "internal_server_error", defaultLang),
+			}
+
+			context.Writer.Header().Add("Content-Type", "application/json")
+			context.JSON(http.StatusInternalServerError, webResponse)
+			return
+		}
+
+		userMagicLinkCreateRequest := model.UserMagicLinkCreateRequest{}
+		userMagicLinkCreateRequest.UserId = userCheck.UserId
+		userMagicLinkCreateRequest.LinkToken = claims.Id
+		userMagicLinkCreateRequest.LinkExpiredAt = expirationTime.Format("2006-01-02 15:04:05")
+		userMagicLinkCreateRequest.LinkCreatedAt = currentTime.Format("2006-01-02 15:04:05")
+		userMagicLinkCreateRequest.MaxRequests = h.config.MagicLink.MaxRequests
+		userMagicLinkCreateRequest.WindowStart = currentTime.Add(-h.config.MagicLink.Window).Format("2006-01-02 15:04:05")
+
+		if h.UserService.CreateMagicLink(context, userMagicLinkCreateRequest) {
+			userResponse := h.UserService.FindById(context, userCheck.UserId)
+			link := h.config.AppUrl + "/magic-link?token=" + tokenString
+			h.sendMail(context, userResponse, "magic_link_email_subject", "magic_link_email_body", link)
+		}
+	}
+
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "magic_link_sent", defaultLang),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+// ConsumeMagicLink burns the link and signs the user in like Login does, two factor users get a challenge first
+func (h *UserHandler) ConsumeMagicLink(context *gin.Context) {
+	defaultLang := h.config.DefaultLang
+
+	userMagicLinkConsumeRequest := model.UserMagicLinkConsumeRequest{}
+	context.Bind(&userMagicLinkConsumeRequest)
+
+	err := h.Validate.Struct(userMagicLinkConsumeRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", defaultLang),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	currentTime := time.Now()
+
+	isUsed := false
+	claims, reason := middleware.ParseToken(h.config, h.KeySet, userMagicLinkConsumeRequest.Token, middleware.TokenTypeMagicLink)
+	if reason == "" {
+		userMagicLinkConsumeRequest.UserId = claims.UserId
+		userMagicLinkConsumeRequest.LinkToken = claims.Id
+		userMagicLinkConsumeRequest.LinkUsedAt = currentTime.Format("2006-01-02 15:04:05")
+
+		isUsed = h.UserService.UseMagicLink(context, userMagicLinkConsumeRequest)
+	}
+
+	if !isUsed {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "invalid_or_expired_token", defaultLang),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	if h.UserService.FindTotpByUserId(context, claims.UserId).TotpIsEnabled {
+		h.loginChallengeResponse(context, claims.UserId, defaultLang)
+		return
+	}
+
+	h.completeLogin(context, claims.UserId, currentTime)
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OidcLogin starts a single sign-on with the provider, the client sends the browser to the returned authorization url
+// and hands the code and state the provider redirects back with to OidcCallback
+func (h *UserHandler) OidcLogin(context *gin.Context) {
+	h.startOidc(context, 0, h.config.DefaultLang)
+}
+
+// OidcLink starts a single sign-on that links the identity to the signed in user instead of signing in, it is the only
+// way an existing account gets a provider identity
+func (h *UserHandler) OidcLink(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	if payloadJwt.IsImpersonated || payloadJwt.ApiKeyId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusForbidden,
+			Status: h.TranslationService.Translation(context, "forbidden", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusForbidden, webResponse)
+		return
+	}
+
+	h.startOidc(context, payloadJwt.UserId, payloadJwt.UserLangCode)
+}
+
+// startOidc stores the state of a new sign-on with the provider and answers with its authorization url, userId is the
+// user the identity gets linked to or 0 for a login
+func (h *UserHandler) startOidc(context *gin.Context, userId int, langCode string) {
+	provider, err := h.Oidc.Provider(context.Param("provider"))
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "oidc_provider_not_found", langCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+		return
+	}
+
+	currentTime := time.Now()
+	userOidcState := model.UserOidcState{}
+	userOidcState.StateKey = helper.GenerateRandomToken(32)
+	userOidcState.StateProvider = provider.Name
+	userOidcState.StateUserId = userId
+	userOidcState.StateNonce = helper.GenerateRandomToken(16)
+	userOidcState.StateCodeVerifier = helper.GenerateRandomToken(32)
+	userOidcState.StateExpiredAt = currentTime.Add(h.config.Oidc.StateExpired).Format("2006-01-02 15:04:05")
+	userOidcState.StateCreatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	authorizationUrl, err := provider.AuthorizationUrl(context, userOidcState.StateKey, userOidcState.StateNonce, userOidcState.StateCodeVerifier)
+	if err != nil {
+		log.Println("Failed loading OIDC provider.", provider.Name, err)
+
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadGateway,
+			Status: h.TranslationService.Translation(context, "oidc_provider_unavailable", langCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadGateway, webResponse)
+		return
+	}
+
+	h.UserService.CreateOidcState(context, userOidcState)
+
+	userOidcAuthorizationResponse := model.UserOidcAuthorizationResponse{}
+	userOidcAuthorizationResponse.AuthorizationUrl = authorizationUrl
+
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "success_oidc_authorization", langCode),
+		Data:   userOidcAuthorizationResponse,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+// OidcCallback redeems the authorization code with the PKCE verifier of its state, verifies the ID token and signs the
+// user of the identity in like Login does. A state started with OidcLink links the identity instead.
+func (h *UserHandler) OidcCallback(context *gin.Context) {
+	defaultLang := h.config.DefaultLang
+
+	currentTime := time.Now()
+	userOidcCallbackRequest := model.UserOidcCallbackRequest{}
+	context.Bind(&userOidcCallbackRequest)
+
+	err := h.Validate.Struct(userOidcCallbackRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", defaultLang),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	provider, err := h.Oidc.Provider(context.Param("provider"))
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "oidc_provider_not_found", defaultLang),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+		return
+	}
+
+	userOidcState := h.UserService.UseOidcState(context, userOidcCallbackRequest.State, provider.Name, currentTime.Format("2006-01-02 15:04:05"))
+	if userOidcState.StateId == 0 {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusUnauthorized,
+			Status: h.TranslationService.Translation(context, "oidc_invalid_state", defaultLang),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusUnauthorized, webResponse)
+		return
+	}
+
+	identity, err := provider.Exchange(context, userOidcCallbackRequest.Code, userOidcState.StateCodeVerifier, userOidcState.StateNonce)
+	if err != nil {
+		log.Println("Failed OIDC code exchange.", provider.Name, err)
+
+		webResponse := helper.WebResponse{
+			Code:   http.StatusUnauthorized,
+			Status: h.TranslationService.Translation(context, "oidc_invalid_identity", defaultLang),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusUnauthorized, webResponse)
+		return
+	}
+
+	if userOidcState.StateUserId != 0 {
+		userOidcLinkRequest := model.UserOidcLinkRequest{}
+		userOidcLinkRequest.UserId = userOidcState.StateUserId
+		userOidcLinkRequest.IdentityProvider = provider.Name
+		userOidcLinkRequest.IdentitySubject = identity.Subject
+		userOidcLinkRequest.IdentityEmail = identity.Email
+		userOidcLinkRequest.IdentityCreatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+		userResponse := h.UserService.LinkOidc(context, userOidcLinkRequest)
+		if userResponse.UserId == 0 {
+			webResponse := helper.WebResponse{
+				Code:   http.StatusConflict,
+				Status: h.TranslationService.Translation(context, "oidc_identity_taken", defaultLang),
+			}
+
+			context.Writer.Header().Add("Content-Type", "application/json")
+			context.JSON(http.StatusConflict, webResponse)
+			return
+		}
+
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_link_oidc", userResponse.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+		return
+	}
+
+	userOidcLoginRequest := model.UserOidcLoginRequest{}
+	userOidcLoginRequest.IdentityProvider = provider.Name
+	userOidcLoginRequest.IdentitySubject = identity.Subject
+	userOidcLoginRequest.IdentityEmail = identity.Email
+	userOidcLoginRequest.EmailVerified = identity.EmailVerified
+	userOidcLoginRequest.UserName = identity.Name
+	userOidcLoginRequest.UserLangCode = defaultLang
+	userOidcLoginRequest.AutoCreate = provider.AutoCreate()
+	userOidcLoginRequest.IdentityCreatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	userResponse := h.UserService.LoginOidc(context, userOidcLoginRequest)
+	if userResponse.UserId == 0 {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusUnauthorized,
+			Status: h.TranslationService.Translation(context, "oidc_account_not_linked", defaultLang),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusUnauthorized, webResponse)
+		return
+	}
+
+	// the provider replaces the password, not the second factor
+	if h.UserService.FindTotpByUserId(context, userResponse.UserId).TotpIsEnabled {
+		h.loginChallengeResponse(context, userResponse.UserId, defaultLang)
+		return
+	}
+
+	h.completeLogin(context, userResponse.UserId, currentTime)
+}
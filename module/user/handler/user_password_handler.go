@@ -0,0 +1,345 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/infras"
+	"collapp/module/user/model"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *UserHandler) UpdatePassword(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userPasswordUpdateRequest := model.UserPasswordUpdateRequest{}
+	context.Bind(&userPasswordUpdateRequest)
+
+	userPasswordUpdateRequest.UserId = payloadJwt.UserId
+	userPasswordUpdateRequest.SessionId = payloadJwt.SessionId
+	userPasswordUpdateRequest.UpdatedBy = payloadJwt.ActorId
+
+	currentTime := time.Now()
+	userPasswordUpdateRequest.UpdatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	err := h.Validate.Struct(userPasswordUpdateRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userCheck := h.UserService.FindPasswordById(context, payloadJwt.UserId)
+
+	isValid, _ := h.PasswordPolicy.Verify(userCheck.UserPassword, userPasswordUpdateRequest.CurrentPassword)
+	if !isValid {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "wrong_current_password", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	reason := h.checkPassword(context, payloadJwt.UserId, userPasswordUpdateRequest.NewPassword)
+	if reason != "" {
+		h.passwordRejectedResponse(context, reason, payloadJwt.UserLangCode)
+		return
+	}
+
+	hashedPassword, err := h.PasswordPolicy.Hash(userPasswordUpdateRequest.NewPassword)
+	if err != nil {
+		log.Println("Password could not be hashed.", err)
+
+		webResponse := helper.WebResponse{
+			Code:   http.StatusInternalServerError,
+			Status: h.TranslationService.Translation(context, "internal_server_error", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusInternalServerError, webResponse)
+		return
+	}
+
+	userPasswordUpdateRequest.UserPassword = string(hashedPassword)
+
+	userResponse := h.UserService.UpdatePassword(context, userPasswordUpdateRequest)
+
+	if userResponse.UserId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_update_password", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+func (h *UserHandler) ResetPassword(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userPasswordResetRequest := model.UserPasswordResetRequest{}
+	context.ShouldBindJSON(&userPasswordResetRequest)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userPasswordResetRequest.UserId = id
+	userPasswordResetRequest.UpdatedBy = payloadJwt.ActorId
+
+	currentTime := time.Now()
+	userPasswordResetRequest.UpdatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	err = h.Validate.Struct(userPasswordResetRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	// without a password in the body a temporary one is generated and handed back once
+	passwordResetResponse := model.UserPasswordResetResponse{}
+	if userPasswordResetRequest.NewPassword == "" {
+		userPasswordResetRequest.NewPassword, err = h.PasswordPolicy.Generate()
+		if err != nil {
+			log.Println("Temporary password could not be generated.", err)
+
+			webResponse := helper.WebResponse{
+				Code:   http.StatusInternalServerError,
+				Status: h.TranslationService.Translation(context, "internal_server_error", payloadJwt.UserLangCode),
+			}
+
+			context.Writer.Header().Add("Content-Type", "application/json")
+			context.JSON(http.StatusInternalServerError, webResponse)
+			return
+		}
+		passwordResetResponse.TemporaryPassword = userPasswordResetRequest.NewPassword
+	} else {
+		reason := h.checkPassword(context, id, userPasswordResetRequest.NewPassword)
+		if reason != "" {
+			h.passwordRejectedResponse(context, reason, payloadJwt.UserLangCode)
+			return
+		}
+	}
+
+	hashedPassword, err := h.PasswordPolicy.Hash(userPasswordResetRequest.NewPassword)
+	if err != nil {
+		log.Println("Password could not be hashed.", err)
+
+		webResponse := helper.WebResponse{
+			Code:   http.StatusInternalServerError,
+			Status: h.TranslationService.Translation(context, "internal_server_error", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusInternalServerError, webResponse)
+		return
+	}
+
+	userPasswordResetRequest.UserPassword = string(hashedPassword)
+
+	userResponse := h.UserService.ResetPassword(context, userPasswordResetRequest)
+
+	if userResponse.UserId != 0 {
+		passwordResetResponse.UserId = userResponse.UserId
+
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_reset_password", payloadJwt.UserLangCode),
+			Data:   passwordResetResponse,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+func (h *UserHandler) ForgotPassword(context *gin.Context) {
+	defaultLang := h.config.DefaultLang
+
+	userPasswordForgotRequest := model.UserPasswordForgotRequest{}
+	context.Bind(&userPasswordForgotRequest)
+
+	err := h.Validate.Struct(userPasswordForgotRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", defaultLang),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	currentTime := time.Now()
+	userPasswordForgotRequest.ResetToken = helper.GenerateRandomToken(32)
+	userPasswordForgotRequest.ResetExpiredAt = currentTime.Add(h.config.PasswordReset.Expired).Format("2006-01-02 15:04:05")
+	userPasswordForgotRequest.ResetCreatedAt = currentTime.Format("2006-01-02 15:04:05")
+	userPasswordForgotRequest.MaxRequests = h.config.PasswordReset.MaxRequests
+	userPasswordForgotRequest.WindowStart = currentTime.Add(-h.config.PasswordReset.Window).Format("2006-01-02 15:04:05")
+
+	userResponse := h.UserService.CreatePasswordReset(context, userPasswordForgotRequest)
+
+	if userResponse.UserId != 0 {
+		link := h.config.AppUrl + "/reset-password?token=" + userPasswordForgotRequest.ResetToken
+		h.sendMail(context, userResponse, "password_reset_email_subject", "password_reset_email_body", link)
+	}
+
+	// same answer for known and unknown emails so the endpoint can not be used to probe accounts
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "password_reset_sent", defaultLang),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+func (h *UserHandler) ResetPasswordByToken(context *gin.Context) {
+	defaultLang := h.config.DefaultLang
+
+	userPasswordResetTokenRequest := model.UserPasswordResetTokenRequest{}
+	context.Bind(&userPasswordResetTokenRequest)
+
+	err := h.Validate.Struct(userPasswordResetTokenRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", defaultLang),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	currentTime := time.Now()
+	userPasswordResetTokenRequest.ResetUsedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	// the owner of the token is only looked up for the policy, the token is consumed when the password is stored
+	userCheck := h.UserService.FindPasswordResetUser(context, userPasswordResetTokenRequest.ResetToken, userPasswordResetTokenRequest.ResetUsedAt)
+	if userCheck.UserId != 0 {
+		reason := h.checkPassword(context, userCheck.UserId, userPasswordResetTokenRequest.NewPassword)
+		if reason != "" {
+			h.passwordRejectedResponse(context, reason, userCheck.UserLangCode)
+			return
+		}
+	}
+
+	hashedPassword, err := h.PasswordPolicy.Hash(userPasswordResetTokenRequest.NewPassword)
+	if err != nil {
+		log.Println("Password could not be hashed.", err)
+
+		webResponse := helper.WebResponse{
+			Code:   http.StatusInternalServerError,
+			Status: h.TranslationService.Translation(context, "internal_server_error", defaultLang),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusInternalServerError, webResponse)
+		return
+	}
+
+	userPasswordResetTokenRequest.UserPassword = string(hashedPassword)
+
+	userResponse := h.UserService.ResetPasswordByToken(context, userPasswordResetTokenRequest)
+
+	if userResponse.UserId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_reset_password", userResponse.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "invalid_or_expired_token", defaultLang),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+	}
+}
+
+// checkPassword returns why the new password of the user is refused by the policy, or an empty string. Reuse is
+// checked against the current password and the last PASSWORD.HISTORY ones.
+func (h *UserHandler) checkPassword(context *gin.Context, userId int, password string) string {
+	reason := h.PasswordPolicy.Validate(password)
+	if reason != "" {
+		return reason
+	}
+
+	if h.PasswordPolicy.History() > 0 {
+		for _, hashedPassword := range h.UserService.FindPasswordHistory(context, userId, h.PasswordPolicy.History()) {
+			isValid, _ := h.PasswordPolicy.Verify(hashedPassword, password)
+			if isValid {
+				return infras.PasswordReused
+			}
+		}
+	}
+
+	return ""
+}
+
+func (h *UserHandler) passwordRejectedResponse(context *gin.Context, reason string, langCode string) {
+	webResponse := helper.WebResponse{
+		Code:   http.StatusBadRequest,
+		Status: h.TranslationService.Translation(context, "bad_request", langCode),
+		Data:   h.TranslationService.Translation(context, reason, langCode),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(http.StatusBadRequest, webResponse)
+}
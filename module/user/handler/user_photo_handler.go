@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"collapp/helper"
+	"log"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// uploadPhoto puts an uploaded photo into the storage and returns its key, the key is what user_photo stores
+func (h *UserHandler) uploadPhoto(context *gin.Context, fileHeader *multipart.FileHeader) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	currentTime := time.Now()
+	key := h.config.Files.Photo + currentTime.Format("20060102150405") + helper.GenerateRandomToken(8) + strings.ToLower(filepath.Ext(fileHeader.Filename))
+
+	err = h.Storage.Put(context, key, file, fileHeader.Size, fileHeader.Header.Get("Content-Type"))
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// deletePhoto removes a replaced or deleted photo, a failure only leaves an orphan file behind so it is logged
+func (h *UserHandler) deletePhoto(context *gin.Context, key string) {
+	err := h.Storage.Delete(context, key)
+	if err != nil {
+		log.Println("Failed deleting photo.", key, err)
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *UserHandler) FindMe(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userResponse := h.UserService.FindById(context, payloadJwt.UserId)
+
+	if userResponse.UserId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_get_user", payloadJwt.UserLangCode),
+			Data:   userResponse,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+// UpdateMe updates the name, language and photo of the signed in user. Name and language are claims of the token,
+// so when one of them changes the token pair of the session is reissued and returned with the user.
+func (h *UserHandler) UpdateMe(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userProfileUpdateRequest := model.UserProfileUpdateRequest{}
+	context.Bind(&userProfileUpdateRequest)
+
+	userProfileUpdateRequest.UserId = payloadJwt.UserId
+	userProfileUpdateRequest.UpdatedBy = payloadJwt.ActorId
+
+	currentTime := time.Now()
+	userProfileUpdateRequest.UpdatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	err := h.Validate.Struct(userProfileUpdateRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	if userProfileUpdateRequest.UserPhoto != nil {
+		photoKey, err := h.uploadPhoto(context, userProfileUpdateRequest.UserPhoto)
+		userProfileUpdateRequest.UserPhotoName = photoKey
+		if err != nil {
+			webResponse := helper.WebResponse{
+				Code:   http.StatusInternalServerError,
+				Status: h.TranslationService.Translation(context, "internal_server_error", payloadJwt.UserLangCode) + " " + h.TranslationService.Translation(context, "file_upload_failed", payloadJwt.UserLangCode),
+				Data:   nil,
+			}
+
+			context.Writer.Header().Add("Content-Type", "application/json")
+			context.JSON(http.StatusInternalServerError, webResponse)
+			return
+		}
+	}
+
+	userResponse, oldPhoto := h.UserService.UpdateProfile(context, userProfileUpdateRequest)
+
+	if oldPhoto != "" {
+		h.deletePhoto(context, oldPhoto)
+	}
+
+	if userResponse.UserId == 0 {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+		return
+	}
+
+	// API keys carry no token, they read the profile on every request, and an impersonation token is never reissued
+	isClaimChanged := userResponse.UserLangCode != payloadJwt.UserLangCode || userResponse.UserName != payloadJwt.UserName
+	if isClaimChanged && payloadJwt.SessionId != 0 && !payloadJwt.IsImpersonated {
+		userRoles := h.RoleService.FindRoleCodesByUserId(context, userResponse.UserId)
+
+		tokenString, tokenStringRefresh, err := h.generateToken(userResponse, userRoles, payloadJwt.SessionId)
+		if err != nil {
+			webResponse := helper.WebResponse{
+				Code:   http.StatusInternalServerError,
+				Status: h.TranslationService.Translation(context, "internal_server_error", userResponse.UserLangCode),
+				Data:   err,
+			}
+
+			context.Writer.Header().Add("Content-Type", "application/json")
+			context.JSON(http.StatusInternalServerError, webResponse)
+			return
+		}
+
+		userData := model.UserUpdateTokenRequest{}
+		userData.UserId = userResponse.UserId
+		userData.SessionId = payloadJwt.SessionId
+		userData.UserToken = tokenString
+		userData.UserTokenRefresh = tokenStringRefresh
+		userData.UserLastLogin = currentTime.Format("2006-01-02 15:04:05")
+		h.UserService.UpdateToken(context, userData)
+
+		h.setTokenCookies(context, tokenString, tokenStringRefresh)
+
+		userResponse.UserToken = tokenString
+		userResponse.UserTokenRefresh = tokenStringRefresh
+	}
+
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "success_update_user", userResponse.UserLangCode),
+		Data:   h.hideTokens(userResponse),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
@@ -10,17 +10,62 @@ func (h *UserHandler) Router(router *gin.RouterGroup, auth middleware.AuthMiddle
 	users := router.Group("/users")
 
 	users.POST("/login", h.Login)
-	users.GET("/refresh-token/:userRefreshToken", h.RefreshToken)
+	users.POST("/login/2fa", h.VerifyTotp)
+	users.POST("/refresh-token", h.RefreshToken)
+	users.POST("/password/forgot", h.ForgotPassword)
+	users.POST("/password/reset", h.ResetPasswordByToken)
+	users.POST("/invitation/accept", h.AcceptInvitation)
+	users.GET("/oidc/:provider", h.OidcLogin)
+	users.POST("/oidc/:provider/callback", h.OidcCallback)
+	if h.config.MagicLink.Enabled {
+		users.POST("/login/magic-link", h.MagicLinkLogin)
+		users.POST("/login/magic-link/consume", h.ConsumeMagicLink)
+	}
 
 	usersAuth := users.Group("")
 	usersAuth.Use(auth.Auth())
 	{
-		usersAuth.GET("/", h.FindAll)
-		usersAuth.GET("/:userId", h.FindById)
-		usersAuth.POST("/", h.Create)
-		usersAuth.PUT("/:userId", h.Update)
-		usersAuth.DELETE("/:userId", h.Delete)
+		usersAuth.GET("/", auth.RequirePermission("users.read"), h.FindAll)
+		usersAuth.GET("/export", auth.RequirePermission("users.read"), h.Export)
+		usersAuth.GET("/trash", auth.RequirePermission("users.delete"), h.FindAllDeleted)
+		usersAuth.PUT("/trash/:userId/restore", auth.RequirePermission("users.delete"), h.Restore)
+		usersAuth.GET("/:userId", auth.RequirePermission("users.read"), h.FindById)
+		usersAuth.POST("/", auth.RequirePermission("users.create"), h.Create)
+		usersAuth.POST("/import", auth.RequirePermission("users.create"), h.Import)
+		usersAuth.PUT("/:userId", auth.RequirePermission("users.update"), h.Update)
+		usersAuth.DELETE("/:userId", auth.RequirePermission("users.delete"), h.Delete)
+		usersAuth.PUT("/:userId/password/reset", auth.RequirePermission("users.reset_password"), h.ResetPassword)
+		usersAuth.PUT("/:userId/unlock", auth.RequirePermission("users.unlock"), h.Unlock)
+		usersAuth.PUT("/:userId/suspend", auth.RequirePermission("users.status"), h.Suspend)
+		usersAuth.PUT("/:userId/reactivate", auth.RequirePermission("users.status"), h.Reactivate)
+		usersAuth.PUT("/:userId/disable", auth.RequirePermission("users.status"), h.Disable)
+		usersAuth.GET("/:userId/login-events", auth.RequirePermission("users.read"), h.FindLoginEvent)
+		usersAuth.POST("/:userId/impersonate", auth.RequirePermission("users.impersonate"), h.Impersonate)
+		usersAuth.POST("/impersonate/stop", h.StopImpersonation)
+		usersAuth.POST("/:userId/invitation", auth.RequirePermission("users.create"), h.ResendInvitation)
+		usersAuth.DELETE("/:userId/invitation", auth.RequirePermission("users.create"), h.RevokeInvitation)
+		usersAuth.POST("/service-accounts", auth.RequirePermission("service_accounts.manage"), h.CreateServiceAccount)
+		usersAuth.GET("/:userId/api-keys", auth.RequirePermission("service_accounts.manage"), h.FindApiKey)
+		usersAuth.POST("/:userId/api-keys", auth.RequirePermission("service_accounts.manage"), h.CreateApiKey)
+		usersAuth.DELETE("/:userId/api-keys/:apiKeyId", auth.RequirePermission("service_accounts.manage"), h.RevokeApiKey)
 		usersAuth.PUT("/logout", h.Logout)
+		usersAuth.GET("/me", h.FindMe)
+		usersAuth.PUT("/me", h.UpdateMe)
+		usersAuth.PUT("/me/password", h.UpdatePassword)
+		usersAuth.GET("/me/sessions", h.FindSession)
+		usersAuth.GET("/me/login-events", h.FindMyLoginEvent)
+		usersAuth.GET("/me/oidc/:provider", h.OidcLink)
+		usersAuth.DELETE("/me/sessions/:sessionId", h.RevokeSession)
+		usersAuth.GET("/me/2fa", h.FindTotp)
+		usersAuth.POST("/me/2fa/enroll", h.EnrollTotp)
+		usersAuth.POST("/me/2fa/confirm", h.ConfirmTotp)
+		usersAuth.POST("/me/2fa/disable", h.DisableTotp)
+	}
+
+	oauth := router.Group("/oauth")
+	oauth.Use(auth.Auth())
+	{
+		oauth.POST("/introspect", auth.RequirePermission("tokens.introspect"), h.Introspect)
 	}
 
 }
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *UserHandler) FindSession(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	sessionResponses := h.UserService.FindSessionByUserId(context, payloadJwt.UserId)
+
+	for index, dt := range sessionResponses {
+		sessionResponses[index].SessionIsCurrent = dt.SessionId == payloadJwt.SessionId
+	}
+
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "success_get_session", payloadJwt.UserLangCode),
+		Data:   sessionResponses,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+func (h *UserHandler) RevokeSession(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	sessionId := context.Param("sessionId")
+	id, err := strconv.Atoi(sessionId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	sessionRevokeRequest := model.UserSessionRevokeRequest{}
+	sessionRevokeRequest.SessionId = id
+	sessionRevokeRequest.SessionUserId = payloadJwt.UserId
+
+	currentTime := time.Now()
+	sessionRevokeRequest.SessionRevokedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	sessionResponse := h.UserService.RevokeSession(context, sessionRevokeRequest)
+
+	if sessionResponse.SessionId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_revoke_session", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
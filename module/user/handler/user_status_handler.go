@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Suspend closes the account until it is reactivated or, with an until date, until that date has passed
+func (h *UserHandler) Suspend(context *gin.Context) {
+	h.updateStatus(context, model.UserStatusSuspended, "success_suspend_user")
+}
+
+func (h *UserHandler) Reactivate(context *gin.Context) {
+	h.updateStatus(context, model.UserStatusActive, "success_reactivate_user")
+}
+
+func (h *UserHandler) Disable(context *gin.Context) {
+	h.updateStatus(context, model.UserStatusDisabled, "success_disable_user")
+}
+
+func (h *UserHandler) updateStatus(context *gin.Context, userStatus string, successKey string) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userStatusUpdateRequest := model.UserStatusUpdateRequest{}
+	context.ShouldBindJSON(&userStatusUpdateRequest)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userStatusUpdateRequest.UserId = id
+	userStatusUpdateRequest.UserStatus = userStatus
+	userStatusUpdateRequest.UpdatedBy = payloadJwt.ActorId
+
+	currentTime := time.Now()
+	userStatusUpdateRequest.UpdatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	err = h.Validate.Struct(userStatusUpdateRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	// nobody locks themselves out
+	if id == payloadJwt.ActorId {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusForbidden,
+			Status: h.TranslationService.Translation(context, "forbidden", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusForbidden, webResponse)
+		return
+	}
+
+	if userStatusUpdateRequest.UserSuspendedUntil != "" && userStatusUpdateRequest.UserSuspendedUntil <= userStatusUpdateRequest.UpdatedAt {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   h.TranslationService.Translation(context, "suspension_end_in_past", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userCheck := h.UserService.FindById(context, id)
+	if userCheck.UserId == 0 {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+		return
+	}
+
+	if !model.CanChangeUserStatus(userCheck.UserStatus, userStatus) {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "invalid_status_transition", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userResponse := h.UserService.UpdateStatus(context, userStatusUpdateRequest)
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, successKey, payloadJwt.UserLangCode),
+		Data:   userResponse,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+// accountStatusResponse refuses a user whose account is not active, the reason is the translation key of the status
+func (h *UserHandler) accountStatusResponse(context *gin.Context, reason string, langCode string) {
+	webResponse := helper.WebResponse{
+		Code:   http.StatusForbidden,
+		Status: h.TranslationService.Translation(context, reason, langCode),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(http.StatusForbidden, webResponse)
+}
@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"collapp/transport/http/middleware"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *UserHandler) FindTotp(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userTotpResponse := h.UserService.FindTotpByUserId(context, payloadJwt.UserId)
+	userTotpResponse.UserId = payloadJwt.UserId
+
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "success_get_two_factor", payloadJwt.UserLangCode),
+		Data:   userTotpResponse,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+func (h *UserHandler) EnrollTotp(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userTotpEnrollRequest := model.UserTotpEnrollRequest{}
+	userTotpEnrollRequest.UserId = payloadJwt.UserId
+	userTotpEnrollRequest.TotpSecret = helper.GenerateTotpSecret()
+
+	currentTime := time.Now()
+	userTotpEnrollRequest.TotpCreatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	userTotpResponse := h.UserService.EnrollTotp(context, userTotpEnrollRequest)
+
+	if userTotpResponse.TotpIsEnabled {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusConflict,
+			Status: h.TranslationService.Translation(context, "two_factor_already_enabled", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusConflict, webResponse)
+		return
+	}
+
+	userTotpEnrollResponse := model.UserTotpEnrollResponse{}
+	userTotpEnrollResponse.TotpSecret = userTotpResponse.TotpSecret
+	userTotpEnrollResponse.TotpUri = helper.TotpUri(h.config.TwoFactor.Issuer, payloadJwt.UserEmail, userTotpResponse.TotpSecret)
+
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "success_enroll_two_factor", payloadJwt.UserLangCode),
+		Data:   userTotpEnrollResponse,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+func (h *UserHandler) ConfirmTotp(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userTotpConfirmRequest := model.UserTotpConfirmRequest{}
+	context.Bind(&userTotpConfirmRequest)
+
+	userTotpConfirmRequest.UserId = payloadJwt.UserId
+
+	currentTime := time.Now()
+	userTotpConfirmRequest.CurrentTime = currentTime
+	userTotpConfirmRequest.TotpConfirmedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	err := h.Validate.Struct(userTotpConfirmRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	// the recovery codes are shown once here, only their hashes are stored
+	for i := 0; i < h.config.TwoFactor.RecoveryCodes; i++ {
+		userTotpConfirmRequest.RecoveryCodes = append(userTotpConfirmRequest.RecoveryCodes, helper.GenerateRandomToken(5))
+	}
+
+	userTotpResponse := h.UserService.ConfirmTotp(context, userTotpConfirmRequest)
+
+	if userTotpResponse.TotpIsEnabled {
+		userTotpConfirmResponse := model.UserTotpConfirmResponse{}
+		userTotpConfirmResponse.RecoveryCodes = userTotpConfirmRequest.RecoveryCodes
+
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_enable_two_factor", payloadJwt.UserLangCode),
+			Data:   userTotpConfirmResponse,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "invalid_two_factor_code", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+	}
+}
+
+func (h *UserHandler) DisableTotp(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userTotpDisableRequest := model.UserTotpDisableRequest{}
+	context.Bind(&userTotpDisableRequest)
+
+	userTotpDisableRequest.UserId = payloadJwt.UserId
+	userTotpDisableRequest.CurrentTime = time.Now()
+
+	err := h.Validate.Struct(userTotpDisableRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userCheck := h.UserService.FindPasswordById(context, payloadJwt.UserId)
+
+	isValid, _ := h.PasswordPolicy.Verify(userCheck.UserPassword, userTotpDisableRequest.CurrentPassword)
+	if !isValid {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "wrong_current_password", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userTotpVerifyRequest := model.UserTotpVerifyRequest{}
+	userTotpVerifyRequest.UserId = payloadJwt.UserId
+	userTotpVerifyRequest.Code = userTotpDisableRequest.Code
+	userTotpVerifyRequest.CurrentTime = userTotpDisableRequest.CurrentTime
+
+	if !h.UserService.VerifyTotp(context, userTotpVerifyRequest) {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "invalid_two_factor_code", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	h.UserService.DisableTotp(context, payloadJwt.UserId)
+
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "success_disable_two_factor", payloadJwt.UserLangCode),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
+
+// VerifyTotp is the second login step, it trades the challenge token of Login and a code for the token pair. Wrong
+// codes count as failed logins of the user email so the lockout covers this step too.
+func (h *UserHandler) VerifyTotp(context *gin.Context) {
+	defaultLang := h.config.DefaultLang
+
+	currentTime := time.Now()
+	userTotpVerifyRequest := model.UserTotpVerifyRequest{}
+	context.Bind(&userTotpVerifyRequest)
+
+	err := h.Validate.Struct(userTotpVerifyRequest)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", defaultLang),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	claims, reason := middleware.ParseToken(h.config, h.KeySet, userTotpVerifyRequest.ChallengeToken, middleware.TokenTypeChallenge)
+	if reason != "" {
+		h.unauthorizedResponse(context, reason, defaultLang)
+		return
+	}
+
+	userResponse := h.UserService.FindById(context, claims.UserId)
+
+	userLoginAttemptRequest := h.loginAttemptRequest(context, userResponse.UserEmail, currentTime)
+	userLoginAttempt := h.UserService.FindLoginAttempt(context, userLoginAttemptRequest)
+	if userLoginAttempt.IsLocked {
+		h.accountLockedResponse(context, defaultLang)
+		return
+	}
+
+	time.Sleep(h.loginDelay(userLoginAttempt.Failures))
+
+	userTotpVerifyRequest.UserId = userResponse.UserId
+	userTotpVerifyRequest.CurrentTime = currentTime
+
+	if userResponse.UserId != 0 && h.UserService.VerifyTotp(context, userTotpVerifyRequest) {
+		h.UserService.ResetLoginAttempt(context, userLoginAttemptRequest)
+		h.completeLogin(context, userResponse.UserId, currentTime)
+	} else {
+		userLoginAttempt = h.UserService.RecordLoginFailure(context, userLoginAttemptRequest)
+		if userLoginAttempt.IsLocked {
+			h.accountLockedResponse(context, defaultLang)
+			return
+		}
+
+		webResponse := helper.WebResponse{
+			Code:   http.StatusUnauthorized,
+			Status: h.TranslationService.Translation(context, "invalid_two_factor_code", defaultLang),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusUnauthorized, webResponse)
+	}
+}
+
+// loginChallengeResponse answers a correct password of a two factor user with a short lived challenge token, the
+// token can only be redeemed at VerifyTotp
+func (h *UserHandler) loginChallengeResponse(context *gin.Context, userId int, langCode string) {
+	expirationTime := time.Now().Add(h.config.TwoFactor.ChallengeExpired)
+	claims := middleware.Claims{
+		UserId:         userId,
+		TokenType:      middleware.TokenTypeChallenge,
+		StandardClaims: middleware.NewStandardClaims(h.config, expirationTime),
+	}
+	tokenString, err := h.KeySet.Sign(claims)
+
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusInternalServerError,
+			Status: h.TranslationService.Translation(context, "internal_server_error", langCode),
+			Data:   err,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusInternalServerError, webResponse)
+		return
+	}
+
+	userLoginChallengeResponse := model.UserLoginChallengeResponse{}
+	userLoginChallengeResponse.TwoFactorRequired = true
+	userLoginChallengeResponse.ChallengeToken = tokenString
+
+	webResponse := helper.WebResponse{
+		Code:   200,
+		Status: h.TranslationService.Translation(context, "two_factor_required", langCode),
+		Data:   userLoginChallengeResponse,
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(200, webResponse)
+}
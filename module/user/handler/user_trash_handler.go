@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FindAllDeleted lists the soft deleted users that are not purged yet
+func (h *UserHandler) FindAllDeleted(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	listQuery, err := helper.NewListQuery(context, model.UserTrashListSorts, model.UserTrashListFilters, "user_id")
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userResponses, pagination := h.UserService.FindAllDeleted(context, listQuery)
+
+	if len(userResponses) > 0 {
+		webResponse := helper.WebResponse{
+			Code:       200,
+			Status:     h.TranslationService.Translation(context, "success_get_user", payloadJwt.UserLangCode),
+			Data:       userResponses,
+			Pagination: &pagination,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:       http.StatusNotFound,
+			Status:     h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:       nil,
+			Pagination: &pagination,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
+
+// Restore takes a soft deleted user out of the trash, unless another user signs in with the same email meanwhile
+func (h *UserHandler) Restore(context *gin.Context) {
+	payloadJwt := helper.PayloadJwt(context)
+
+	userId := context.Param("userId")
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: h.TranslationService.Translation(context, "bad_request", payloadJwt.UserLangCode),
+			Data:   err.Error(),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusBadRequest, webResponse)
+		return
+	}
+
+	userDeleted := h.UserService.FindDeletedById(context, id)
+	if userDeleted.UserId == 0 {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+		return
+	}
+
+	if !userDeleted.UserIsServiceAccount && h.UserService.FindByEmail(context, userDeleted.UserEmail).UserId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusConflict,
+			Status: h.TranslationService.Translation(context, "user_email_taken", payloadJwt.UserLangCode),
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusConflict, webResponse)
+		return
+	}
+
+	userRestoreRequest := model.UserRestoreRequest{}
+	userRestoreRequest.UserId = id
+	userRestoreRequest.UpdatedBy = payloadJwt.ActorId
+
+	currentTime := time.Now()
+	userRestoreRequest.UpdatedAt = currentTime.Format("2006-01-02 15:04:05")
+
+	userResponse := h.UserService.Restore(context, userRestoreRequest)
+
+	if userResponse.UserId != 0 {
+		webResponse := helper.WebResponse{
+			Code:   200,
+			Status: h.TranslationService.Translation(context, "success_restore_user", payloadJwt.UserLangCode),
+			Data:   userResponse,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(200, webResponse)
+	} else {
+		webResponse := helper.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: h.TranslationService.Translation(context, "data_not_found", payloadJwt.UserLangCode),
+			Data:   nil,
+		}
+
+		context.Writer.Header().Add("Content-Type", "application/json")
+		context.JSON(http.StatusNotFound, webResponse)
+	}
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// ApiKeyPrefixLength is how much of a key is kept in clear so users can tell their keys apart
+const ApiKeyPrefixLength = 10
+
+// model UserApiKey, a credential of a service account, the key itself is only kept as a hash
+type UserApiKey struct {
+	ApiKeyId              int
+	ApiKeyUserId          int
+	ApiKeyName            string
+	ApiKeyPrefix          string
+	ApiKey                string
+	ApiKeyScopes          []string
+	ApiKeyScopesText      string
+	ApiKeyExpiredAt       string
+	ApiKeyExpiredAtCheck  sql.NullString
+	ApiKeyLastUsedAt      string
+	ApiKeyLastUsedAtCheck sql.NullString
+	ApiKeyRevokedAt       string
+	ApiKeyRevokedAtCheck  sql.NullString
+	CreatedBy             int
+	CreatedByCheck        sql.NullInt32
+	CreatedAt             string
+}
+
+// request
+type UserServiceAccountCreateRequest struct {
+	UserName     string `validate:"required,min=1,max=200" json:"user_name"`
+	UserEmail    string `validate:"required,min=1,max=200,email" json:"user_email"`
+	UserLangCode string `validate:"required,min=1" json:"user_lang_code"`
+	CreatedBy    int    `validate:"required"`
+	CreatedAt    string `validate:"required"`
+}
+
+type UserApiKeyCreateRequest struct {
+	ApiKeyName      string   `validate:"required,min=1,max=100" json:"name"`
+	ApiKeyScopes    []string `validate:"required,min=1,dive,min=1" json:"scopes"`
+	ApiKeyExpiredAt string   `validate:"omitempty,datetime=2006-01-02 15:04:05" json:"expired_at"`
+	ApiKeyUserId    int      `validate:"required" json:"-"`
+	ApiKey          string   `json:"-"`
+	CreatedBy       int      `validate:"required" json:"-"`
+	CreatedAt       string   `validate:"required" json:"-"`
+}
+
+type UserApiKeyRevokeRequest struct {
+	ApiKeyId        int    `validate:"required"`
+	ApiKeyUserId    int    `validate:"required"`
+	ApiKeyRevokedAt string `validate:"required"`
+}
+
+// rersponse
+type UserApiKeyResponse struct {
+	ApiKeyId         int      `json:"api_key_id"`
+	ApiKeyUserId     int      `json:"user_id"`
+	ApiKeyName       string   `json:"name"`
+	ApiKeyPrefix     string   `json:"prefix"`
+	ApiKey           string   `json:"api_key,omitempty"`
+	ApiKeyScopes     []string `json:"scopes"`
+	ApiKeyExpiredAt  string   `json:"expired_at"`
+	ApiKeyLastUsedAt string   `json:"last_used_at"`
+	ApiKeyRevokedAt  string   `json:"revoked_at"`
+	CreatedBy        int      `json:"created_by"`
+	CreatedAt        string   `json:"created_at"`
+}
+
+func ToUserApiKeyResponse(apiKey UserApiKey) UserApiKeyResponse {
+	return UserApiKeyResponse{
+		ApiKeyId:         apiKey.ApiKeyId,
+		ApiKeyUserId:     apiKey.ApiKeyUserId,
+		ApiKeyName:       apiKey.ApiKeyName,
+		ApiKeyPrefix:     apiKey.ApiKeyPrefix,
+		ApiKey:           apiKey.ApiKey,
+		ApiKeyScopes:     apiKey.ApiKeyScopes,
+		ApiKeyExpiredAt:  apiKey.ApiKeyExpiredAt,
+		ApiKeyLastUsedAt: apiKey.ApiKeyLastUsedAt,
+		ApiKeyRevokedAt:  apiKey.ApiKeyRevokedAt,
+		CreatedBy:        apiKey.CreatedBy,
+		CreatedAt:        apiKey.CreatedAt,
+	}
+}
+
+func ToUserApiKeyResponses(apiKeys []UserApiKey) []UserApiKeyResponse {
+	var apiKeyResponses []UserApiKeyResponse
+	for _, apiKey := range apiKeys {
+		apiKeyResponses = append(apiKeyResponses, ToUserApiKeyResponse(apiKey))
+	}
+	return apiKeyResponses
+}
+
+// ApiKeyScopesFromText splits the comma separated scopes column
+func ApiKeyScopesFromText(scopesText string) []string {
+	scopes := []string{}
+	for _, scope := range strings.Split(scopesText, ",") {
+		if scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
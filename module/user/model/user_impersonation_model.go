@@ -0,0 +1,72 @@
+package model
+
+import "database/sql"
+
+// model UserImpersonation, one row per impersonation session for the audit trail
+type UserImpersonation struct {
+	ImpersonationId           int
+	ImpersonationActorId      int
+	ImpersonationUserId       int
+	ImpersonationSessionId    int
+	ImpersonationReason       string
+	ImpersonationStartedAt    string
+	ImpersonationEndedAt      string
+	ImpersonationEndedAtCheck sql.NullString
+}
+
+// model UserImpersonationAction, a write request made while impersonating
+type UserImpersonationAction struct {
+	ActionId              int
+	ActionImpersonationId int
+	ActionMethod          string
+	ActionPath            string
+	ActionCreatedAt       string
+}
+
+// request
+type UserImpersonationCreateRequest struct {
+	ImpersonationReason    string `validate:"max=255" json:"reason"`
+	ImpersonationActorId   int    `validate:"required" json:"-"`
+	ImpersonationUserId    int    `validate:"required" json:"-"`
+	SessionUserAgent       string `json:"-"`
+	SessionIp              string `json:"-"`
+	ImpersonationStartedAt string `validate:"required" json:"-"`
+}
+
+type UserImpersonationStopRequest struct {
+	ImpersonationActorId   int    `validate:"required"`
+	ImpersonationSessionId int    `validate:"required"`
+	ImpersonationEndedAt   string `validate:"required"`
+}
+
+type UserImpersonationActionRequest struct {
+	ImpersonationSessionId int
+	ActionMethod           string
+	ActionPath             string
+	ActionCreatedAt        string
+}
+
+// rersponse
+type UserImpersonationResponse struct {
+	ImpersonationId        int    `json:"impersonation_id"`
+	ImpersonationActorId   int    `json:"actor_id"`
+	ImpersonationUserId    int    `json:"user_id"`
+	ImpersonationSessionId int    `json:"-"`
+	ImpersonationReason    string `json:"reason"`
+	ImpersonationStartedAt string `json:"started_at"`
+	ImpersonationEndedAt   string `json:"ended_at"`
+	UserToken              string `json:"user_token,omitempty"`
+	UserTokenExpiredAt     string `json:"user_token_expired_at,omitempty"`
+}
+
+func ToUserImpersonationResponse(impersonation UserImpersonation) UserImpersonationResponse {
+	return UserImpersonationResponse{
+		ImpersonationId:        impersonation.ImpersonationId,
+		ImpersonationActorId:   impersonation.ImpersonationActorId,
+		ImpersonationUserId:    impersonation.ImpersonationUserId,
+		ImpersonationSessionId: impersonation.ImpersonationSessionId,
+		ImpersonationReason:    impersonation.ImpersonationReason,
+		ImpersonationStartedAt: impersonation.ImpersonationStartedAt,
+		ImpersonationEndedAt:   impersonation.ImpersonationEndedAt,
+	}
+}
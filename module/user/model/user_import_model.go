@@ -0,0 +1,38 @@
+package model
+
+// Errors of an import row that are not validation rules of UserCreateRequest
+const (
+	UserImportDuplicateEmail  = "duplicate_email"
+	UserImportEmailTaken      = "email_taken"
+	UserImportUnknownLanguage = "unknown_language"
+	UserImportUnknownRole     = "unknown_role"
+	UserImportRoleNotAllowed  = "role_not_allowed"
+
+	// UserImportInvitationFailed marks an imported user whose invitation could not be sent, it can be resent later
+	UserImportInvitationFailed = "invitation_failed"
+)
+
+// request
+type UserImportRequest struct {
+	UserCreateRequest
+	RoleCode string
+}
+
+// rersponse
+type UserImportResponse struct {
+	IsDryRun  bool                    `json:"dry_run"`
+	Total     int                     `json:"total"`
+	Valid     int                     `json:"valid"`
+	Invalid   int                     `json:"invalid"`
+	Imported  int                     `json:"imported"`
+	Uninvited int                     `json:"uninvited"`
+	Rows      []UserImportRowResponse `json:"rows"`
+}
+
+// UserImportRowResponse reports one data row of the file, Row counts the records of the file with the header as row 1
+type UserImportRowResponse struct {
+	Row       int      `json:"row"`
+	UserEmail string   `json:"user_email"`
+	UserId    int      `json:"user_id,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,35 @@
+package model
+
+// Token type names of RFC 7662, used as token_type_hint and token_type
+const (
+	IntrospectionAccessToken  = "access_token"
+	IntrospectionRefreshToken = "refresh_token"
+)
+
+// request
+type UserIntrospectionRequest struct {
+	Token         string `validate:"required,min=1" form:"token" json:"token"`
+	TokenTypeHint string `validate:"omitempty,oneof=access_token refresh_token" form:"token_type_hint" json:"token_type_hint"`
+}
+
+// rersponse, the members of RFC 7662 that are known for a token of this service, an inactive token only has active
+type UserIntrospectionResponse struct {
+	Active    bool                    `json:"active"`
+	Scope     string                  `json:"scope,omitempty"`
+	Username  string                  `json:"username,omitempty"`
+	TokenType string                  `json:"token_type,omitempty"`
+	Exp       int64                   `json:"exp,omitempty"`
+	Iat       int64                   `json:"iat,omitempty"`
+	Nbf       int64                   `json:"nbf,omitempty"`
+	Sub       string                  `json:"sub,omitempty"`
+	Aud       string                  `json:"aud,omitempty"`
+	Iss       string                  `json:"iss,omitempty"`
+	Jti       string                  `json:"jti,omitempty"`
+	Act       *UserIntrospectionActor `json:"act,omitempty"`
+}
+
+// UserIntrospectionActor is the act member of an impersonation token, as in RFC 8693
+type UserIntrospectionActor struct {
+	Sub      string `json:"sub"`
+	Username string `json:"username,omitempty"`
+}
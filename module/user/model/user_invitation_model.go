@@ -0,0 +1,37 @@
+package model
+
+// model UserInvitation, the link carries a signed token whose id is only kept as a hash
+type UserInvitation struct {
+	InvitationId         int
+	InvitationUserId     int
+	InvitationToken      string
+	InvitationExpiredAt  string
+	InvitationAcceptedAt string
+	InvitationRevokedAt  string
+	CreatedBy            int
+	CreatedAt            string
+}
+
+// request
+type UserInvitationCreateRequest struct {
+	UserId              int    `validate:"required"`
+	InvitationToken     string `validate:"required"`
+	InvitationExpiredAt string `validate:"required"`
+	CreatedBy           int    `validate:"required"`
+	CreatedAt           string `validate:"required"`
+}
+
+type UserInvitationRevokeRequest struct {
+	UserId    int    `validate:"required"`
+	UpdatedBy int    `validate:"required"`
+	RevokedAt string `validate:"required"`
+}
+
+type UserInvitationAcceptRequest struct {
+	Token                string `validate:"required,min=1" json:"token"`
+	NewPassword          string `validate:"required,min=1" json:"new_password"`
+	UserId               int    `json:"-"`
+	InvitationToken      string `json:"-"`
+	UserPassword         string `json:"-"`
+	InvitationAcceptedAt string `json:"-"`
+}
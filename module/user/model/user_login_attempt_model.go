@@ -0,0 +1,36 @@
+package model
+
+import "database/sql"
+
+const (
+	LoginAttemptKeyEmail = "email"
+	LoginAttemptKeyIp    = "ip"
+)
+
+// model UserLoginAttempt, failed logins counted per email and per client ip
+type UserLoginAttempt struct {
+	AttemptKeyType          string
+	AttemptKey              string
+	AttemptCount            int
+	AttemptLastAt           string
+	AttemptLockedUntil      string
+	AttemptLockedUntilCheck sql.NullString
+}
+
+// request
+type UserLoginAttemptRequest struct {
+	UserEmail     string
+	Ip            string
+	CurrentTime   string
+	WindowStart   string
+	LockedUntil   string
+	MaxAttempts   int
+	MaxAttemptsIp int
+}
+
+// rersponse
+type UserLoginAttemptResponse struct {
+	Failures    int
+	IsLocked    bool
+	LockedUntil string
+}
@@ -0,0 +1,88 @@
+package model
+
+import "database/sql"
+
+// Types of login events
+const (
+	LoginEventLogin   = "login"
+	LoginEventRefresh = "refresh"
+	LoginEventLogout  = "logout"
+)
+
+// UserLoginEventListSorts and UserLoginEventListFilters are the fields the login events may be sorted and filtered by
+var UserLoginEventListSorts = map[string]string{
+	"event_id":   "event_id",
+	"type":       "event_type",
+	"created_at": "event_created_at",
+}
+
+var UserLoginEventListFilters = map[string]string{
+	"type":       "event_type",
+	"created_at": "event_created_at",
+}
+
+// model UserLoginEvent, one row per sign in, token refresh or sign out, successful or not. A failed login of an
+// unknown email has no user.
+type UserLoginEvent struct {
+	EventId             int
+	EventUserId         int
+	EventUserIdCheck    sql.NullInt32
+	EventEmail          string
+	EventType           string
+	EventIsSuccess      bool
+	EventReason         string
+	EventIp             string
+	EventIpCheck        sql.NullString
+	EventUserAgent      string
+	EventUserAgentCheck sql.NullString
+	EventCreatedAt      string
+}
+
+// request
+type UserLoginEventCreateRequest struct {
+	EventUserId    int
+	EventEmail     string `validate:"max=200"`
+	EventType      string `validate:"required,oneof=login refresh logout"`
+	EventIsSuccess bool
+	EventReason    string `validate:"max=50"`
+	EventIp        string `validate:"max=45"`
+	EventUserAgent string `validate:"max=255"`
+	EventCreatedAt string `validate:"required"`
+}
+
+// rersponse
+type UserLoginEventResponse struct {
+	EventId        int    `json:"event_id"`
+	EventUserId    int    `json:"user_id"`
+	EventEmail     string `json:"email"`
+	EventType      string `json:"type"`
+	EventIsSuccess bool   `json:"is_success"`
+	EventReason    string `json:"reason"`
+	EventIp        string `json:"ip"`
+	EventUserAgent string `json:"user_agent"`
+	EventCreatedAt string `json:"created_at"`
+	// IsNewDevice tells that a successful login came from a user agent or an ip the user never signed in from before
+	IsNewDevice bool `json:"-"`
+}
+
+func ToUserLoginEventResponse(event UserLoginEvent) UserLoginEventResponse {
+	return UserLoginEventResponse{
+		EventId:        event.EventId,
+		EventUserId:    event.EventUserId,
+		EventEmail:     event.EventEmail,
+		EventType:      event.EventType,
+		EventIsSuccess: event.EventIsSuccess,
+		EventReason:    event.EventReason,
+		EventIp:        event.EventIp,
+		EventUserAgent: event.EventUserAgent,
+		EventCreatedAt: event.EventCreatedAt,
+	}
+}
+
+func ToUserLoginEventResponses(events []UserLoginEvent) []UserLoginEventResponse {
+	var eventResponses []UserLoginEventResponse
+	for _, event := range events {
+		eventResponses = append(eventResponses, ToUserLoginEventResponse(event))
+	}
+	return eventResponses
+}
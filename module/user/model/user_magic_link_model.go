@@ -0,0 +1,32 @@
+package model
+
+// model UserMagicLink, the link carries a signed token whose id is only kept as a hash
+type UserMagicLink struct {
+	LinkId        int
+	LinkUserId    int
+	LinkToken     string
+	LinkExpiredAt string
+	LinkUsedAt    string
+	LinkCreatedAt string
+}
+
+// request
+type UserMagicLinkRequest struct {
+	UserEmail string `validate:"required,min=1,email" json:"email"`
+}
+
+type UserMagicLinkCreateRequest struct {
+	UserId        int    `validate:"required"`
+	LinkToken     string `validate:"required"`
+	LinkExpiredAt string `validate:"required"`
+	LinkCreatedAt string `validate:"required"`
+	MaxRequests   int
+	WindowStart   string `validate:"required"`
+}
+
+type UserMagicLinkConsumeRequest struct {
+	Token      string `validate:"required,min=1" json:"token"`
+	UserId     int    `json:"-"`
+	LinkToken  string `json:"-"`
+	LinkUsedAt string `json:"-"`
+}
@@ -5,54 +5,156 @@ import (
 	"mime/multipart"
 )
 
+// Account status of a user, an invited user stays pending until the invitation is accepted. A suspension may end at
+// user_suspended_until, a disabled account stays closed until it is reactivated.
+const (
+	UserStatusActive    = "active"
+	UserStatusPending   = "pending"
+	UserStatusSuspended = "suspended"
+	UserStatusDisabled  = "disabled"
+)
+
+// CanChangeUserStatus tells whether an admin may move a user from one status to another, a suspension may be changed
+// while it runs and a pending user only becomes active by accepting the invitation
+func CanChangeUserStatus(from string, to string) bool {
+	switch to {
+	case UserStatusSuspended, UserStatusDisabled:
+		return from == UserStatusActive || from == UserStatusSuspended
+	case UserStatusActive:
+		return from == UserStatusSuspended || from == UserStatusDisabled
+	}
+
+	return false
+}
+
+// UserStatusReason returns the translation key of the message refusing a user of that status at currentTime, or an
+// empty string when the user may sign in. A suspension whose end date has passed no longer counts.
+func UserStatusReason(userStatus string, suspendedUntil string, currentTime string) string {
+	switch userStatus {
+	case UserStatusPending:
+		return "account_pending"
+	case UserStatusSuspended:
+		if suspendedUntil != "" && suspendedUntil <= currentTime {
+			return ""
+		}
+		return "account_suspended"
+	case UserStatusDisabled:
+		return "account_disabled"
+	}
+
+	return ""
+}
+
+// UserListSorts and UserListFilters are the fields the user list may be sorted and filtered by, with their column
+var UserListSorts = map[string]string{
+	"user_id":         "a.user_id",
+	"user_name":       "a.user_name",
+	"user_email":      "a.user_email",
+	"user_status":     "a.user_status",
+	"user_lang_code":  "a.user_lang_code",
+	"user_last_login": "a.user_last_login",
+	"created_at":      "a.created_at",
+	"updated_at":      "a.updated_at",
+}
+
+var UserListFilters = map[string]string{
+	"user_email":         "a.user_email",
+	"user_status":        "a.user_status",
+	"user_lang_code":     "a.user_lang_code",
+	"user_last_login_at": "a.user_last_login",
+	"created_at":         "a.created_at",
+	"updated_at":         "a.updated_at",
+}
+
+// UserTrashListSorts and UserTrashListFilters are the fields the list of deleted users may be sorted and filtered by
+var UserTrashListSorts = map[string]string{
+	"user_id":    "a.user_id",
+	"user_name":  "a.user_name",
+	"user_email": "a.user_email",
+	"deleted_at": "a.deleted_at",
+}
+
+var UserTrashListFilters = map[string]string{
+	"user_email": "a.user_email",
+	"deleted_at": "a.deleted_at",
+}
+
 // model User
 type User struct {
-	UserId                int
-	UserName              string
-	UserEmail             string
-	UserPassword          string
-	UserToken             string
-	UserTokenCheck        sql.NullString
-	UserTokenRefresh      string
-	UserTokenRefreshCheck sql.NullString
-	UserLangCode          string
-	UserLastLogin         string
-	UserLastLoginCheck    sql.NullString
-	UserPhoto             string
-	UserPhotoCheck        sql.NullString
-	CreatedBy             int
-	CreatedByCheck        sql.NullInt32
-	CreatedByName         string
-	CreatedByNameCheck    sql.NullString
-	CreatedAt             string
-	CreatedAtCheck        sql.NullString
-	UpdatedBy             int
-	UpdatedByCheck        sql.NullInt32
-	UpdatedByName         string
-	UpdatedByNameCheck    sql.NullString
-	UpdatedAt             string
-	UpdatedAtCheck        sql.NullString
-	DeletedBy             int
-	DeletedByName         string
-	DeletedAt             string
+	UserId                  int
+	UserName                string
+	UserEmail               string
+	UserPassword            string
+	UserMustChangePassword  bool
+	UserIsServiceAccount    bool
+	UserStatus              string
+	UserStatusReason        string
+	UserStatusReasonCheck   sql.NullString
+	UserSuspendedUntil      string
+	UserSuspendedUntilCheck sql.NullString
+	UserToken               string
+	UserTokenRefresh        string
+	SessionId               int
+	ActorId                 int
+	ActorName               string
+	ActorSessionId          int
+	IsImpersonated          bool
+	ApiKeyId                int
+	ApiKeyScopes            []string
+	UserLangCode            string
+	UserRoles               []string
+	UserLastLogin           string
+	UserLastLoginCheck      sql.NullString
+	UserPhoto               string
+	UserPhotoCheck          sql.NullString
+	CreatedBy               int
+	CreatedByCheck          sql.NullInt32
+	CreatedByName           string
+	CreatedByNameCheck      sql.NullString
+	CreatedAt               string
+	CreatedAtCheck          sql.NullString
+	UpdatedBy               int
+	UpdatedByCheck          sql.NullInt32
+	UpdatedByName           string
+	UpdatedByNameCheck      sql.NullString
+	UpdatedAt               string
+	UpdatedAtCheck          sql.NullString
+	DeletedBy               int
+	DeletedByCheck          sql.NullInt32
+	DeletedByName           string
+	DeletedByNameCheck      sql.NullString
+	DeletedAt               string
 }
 
 // request
 type UserCreateRequest struct {
+	UserName               string                `validate:"required,min=1,max=200" form:"user_name"`
+	UserEmail              string                `validate:"required,min=1,max=200,email" form:"user_email"`
+	UserPassword           string                `form:"-"`
+	UserMustChangePassword bool                  `form:"-"`
+	UserIsServiceAccount   bool                  `form:"-"`
+	UserStatus             string                `form:"-"`
+	UserLangCode           string                `validate:"required,min=1" form:"user_lang_code"`
+	UserPhoto              *multipart.FileHeader `form:"user_photo"`
+	UserPhotoName          string                `form:"-"`
+	CreatedBy              int                   `validate:"required"`
+	CreatedAt              string                `validate:"required"`
+}
+
+type UserUpdateRequest struct {
+	UserId        int                   `validate:"required"`
 	UserName      string                `validate:"required,min=1,max=200" form:"user_name"`
 	UserEmail     string                `validate:"required,min=1,max=200,email" form:"user_email"`
-	UserPassword  string                `validate:"required,min=1"`
 	UserLangCode  string                `validate:"required,min=1" form:"user_lang_code"`
 	UserPhoto     *multipart.FileHeader `form:"user_photo"`
 	UserPhotoName string                `form:"-"`
-	CreatedBy     int                   `validate:"required"`
-	CreatedAt     string                `validate:"required"`
+	UpdatedBy     int                   `validate:"required"`
+	UpdatedAt     string                `validate:"required"`
 }
 
-type UserUpdateRequest struct {
+type UserProfileUpdateRequest struct {
 	UserId        int                   `validate:"required"`
 	UserName      string                `validate:"required,min=1,max=200" form:"user_name"`
-	UserEmail     string                `validate:"required,min=1,max=200,email" form:"user_email"`
 	UserLangCode  string                `validate:"required,min=1" form:"user_lang_code"`
 	UserPhoto     *multipart.FileHeader `form:"user_photo"`
 	UserPhotoName string                `form:"-"`
@@ -67,59 +169,143 @@ type UserDeleteRequest struct {
 	IsSoftDelete bool   `validate:"required" json:"is_soft_delete"`
 }
 
+type UserRestoreRequest struct {
+	UserId    int    `validate:"required"`
+	UpdatedBy int    `validate:"required"`
+	UpdatedAt string `validate:"required"`
+}
+
+type UserStatusUpdateRequest struct {
+	UserId             int    `validate:"required"`
+	UserStatus         string `validate:"required,oneof=active suspended disabled"`
+	UserStatusReason   string `validate:"required_if=UserStatus suspended,max=255" json:"reason"`
+	UserSuspendedUntil string `validate:"omitempty,datetime=2006-01-02 15:04:05" json:"until"`
+	UpdatedBy          int    `validate:"required"`
+	UpdatedAt          string `validate:"required"`
+}
+
+type UserPasswordUpdateRequest struct {
+	UserId          int    `validate:"required"`
+	SessionId       int    `json:"-"`
+	CurrentPassword string `validate:"required,min=1" json:"current_password"`
+	NewPassword     string `validate:"required,min=1" json:"new_password"`
+	UserPassword    string `json:"-"`
+	UpdatedBy       int    `validate:"required"`
+	UpdatedAt       string `validate:"required"`
+}
+
+type UserPasswordResetRequest struct {
+	UserId       int    `validate:"required"`
+	NewPassword  string `json:"new_password"`
+	UserPassword string `json:"-"`
+	UpdatedBy    int    `validate:"required"`
+	UpdatedAt    string `validate:"required"`
+}
+
 type UserLoginRequest struct {
 	UserEmail    string `validate:"required,min=1,email" json:"email"`
 	UserPassword string `validate:"required,min=1" json:"password"`
 }
 
+type UserRefreshTokenRequest struct {
+	UserTokenRefresh string `json:"refresh_token"`
+	UserId           int    `json:"-"`
+	SessionId        int    `json:"-"`
+	RevokedAt        string `json:"-"`
+}
+
 type UserUpdateTokenRequest struct {
-	UserId           int    `validate:"required"`
-	UserToken        string `validate:"required"`
-	UserTokenRefresh string `validate:"required"`
-	UserLastLogin    string `validate:"required"`
+	UserId               int    `validate:"required"`
+	SessionId            int    `validate:"required"`
+	UserToken            string `validate:"required"`
+	UserTokenRefresh     string `validate:"required"`
+	UserTokenRefreshUsed string
+	UserLastLogin        string `validate:"required"`
 }
 
 // rersponse
 type UserLoginResponse struct {
-	UserId       int    `json:"user_id"`
-	UserName     int    `json:"user_name"`
-	UserPassword string `json:"user_password"`
-	UserLangCode string `json:"user_lang_code"`
+	UserId             int    `json:"user_id"`
+	UserName           int    `json:"user_name"`
+	UserPassword       string `json:"user_password"`
+	UserLangCode       string `json:"user_lang_code"`
+	UserStatus         string `json:"user_status"`
+	UserSuspendedUntil string `json:"-"`
+	SessionId          int    `json:"-"`
 }
 
 type UserResponse struct {
-	UserId           int    `json:"user_id"`
-	UserName         string `json:"user_name"`
-	UserEmail        string `json:"user_email"`
-	UserToken        string `json:"user_token"`
-	UserTokenRefresh string `json:"user_token_refresh"`
-	UserLangCode     string `json:"user_lang_code"`
-	UserLastLogin    string `json:"user_last_login"`
-	UserPhoto        string `json:"user_photo"`
-	CreatedBy        int    `json:"created_by"`
-	CreatedByName    string `json:"created_by_name"`
-	CreatedAt        string `json:"created_at"`
-	UpdatedBy        int    `json:"updated_by"`
-	UpdatedByName    string `json:"updated_by_name"`
-	UpdatedAt        string `json:"updated_at"`
+	UserId                 int    `json:"user_id"`
+	UserName               string `json:"user_name"`
+	UserEmail              string `json:"user_email"`
+	UserToken              string `json:"user_token"`
+	UserTokenRefresh       string `json:"user_token_refresh"`
+	UserLangCode           string `json:"user_lang_code"`
+	UserMustChangePassword bool   `json:"user_must_change_password"`
+	UserIsServiceAccount   bool   `json:"user_is_service_account"`
+	UserStatus             string `json:"user_status"`
+	UserStatusReason       string `json:"user_status_reason"`
+	UserSuspendedUntil     string `json:"user_suspended_until"`
+	UserLastLogin          string `json:"user_last_login"`
+	UserPhoto              string `json:"user_photo"`
+	CreatedBy              int    `json:"created_by"`
+	CreatedByName          string `json:"created_by_name"`
+	CreatedAt              string `json:"created_at"`
+	UpdatedBy              int    `json:"updated_by"`
+	UpdatedByName          string `json:"updated_by_name"`
+	UpdatedAt              string `json:"updated_at"`
+	DeletedBy              int    `json:"deleted_by,omitempty"`
+	DeletedByName          string `json:"deleted_by_name,omitempty"`
+	DeletedAt              string `json:"deleted_at,omitempty"`
+}
+
+// UserExportResponse is a user row of an export, it leaves out passwords and tokens
+type UserExportResponse struct {
+	UserId               int    `json:"user_id"`
+	UserName             string `json:"user_name"`
+	UserEmail            string `json:"user_email"`
+	UserIsServiceAccount bool   `json:"user_is_service_account"`
+	UserStatus           string `json:"user_status"`
+	UserStatusReason     string `json:"user_status_reason"`
+	UserSuspendedUntil   string `json:"user_suspended_until"`
+	UserLangCode         string `json:"user_lang_code"`
+	UserLastLogin        string `json:"user_last_login"`
+	UserPhoto            string `json:"user_photo"`
+	CreatedBy            int    `json:"created_by"`
+	CreatedAt            string `json:"created_at"`
+	UpdatedBy            int    `json:"updated_by"`
+	UpdatedAt            string `json:"updated_at"`
+}
+
+type UserPasswordResetResponse struct {
+	UserId            int    `json:"user_id"`
+	TemporaryPassword string `json:"temporary_password,omitempty"`
 }
 
 func ToUserResponse(user User) UserResponse {
 	return UserResponse{
-		UserId:           user.UserId,
-		UserName:         user.UserName,
-		UserEmail:        user.UserEmail,
-		UserToken:        user.UserToken,
-		UserTokenRefresh: user.UserTokenRefresh,
-		UserLangCode:     user.UserLangCode,
-		UserLastLogin:    user.UserLastLogin,
-		UserPhoto:        user.UserPhoto,
-		CreatedBy:        user.CreatedBy,
-		CreatedByName:    user.CreatedByName,
-		CreatedAt:        user.CreatedAt,
-		UpdatedBy:        user.UpdatedBy,
-		UpdatedByName:    user.UpdatedByName,
-		UpdatedAt:        user.UpdatedAt,
+		UserId:                 user.UserId,
+		UserName:               user.UserName,
+		UserEmail:              user.UserEmail,
+		UserToken:              user.UserToken,
+		UserTokenRefresh:       user.UserTokenRefresh,
+		UserLangCode:           user.UserLangCode,
+		UserMustChangePassword: user.UserMustChangePassword,
+		UserIsServiceAccount:   user.UserIsServiceAccount,
+		UserStatus:             user.UserStatus,
+		UserStatusReason:       user.UserStatusReason,
+		UserSuspendedUntil:     user.UserSuspendedUntil,
+		UserLastLogin:          user.UserLastLogin,
+		UserPhoto:              user.UserPhoto,
+		CreatedBy:              user.CreatedBy,
+		CreatedByName:          user.CreatedByName,
+		CreatedAt:              user.CreatedAt,
+		UpdatedBy:              user.UpdatedBy,
+		UpdatedByName:          user.UpdatedByName,
+		UpdatedAt:              user.UpdatedAt,
+		DeletedBy:              user.DeletedBy,
+		DeletedByName:          user.DeletedByName,
+		DeletedAt:              user.DeletedAt,
 	}
 }
 
@@ -131,9 +317,31 @@ func ToUserResponses(users []User) []UserResponse {
 	return userResponses
 }
 
+func ToUserExportResponse(user User) UserExportResponse {
+	return UserExportResponse{
+		UserId:               user.UserId,
+		UserName:             user.UserName,
+		UserEmail:            user.UserEmail,
+		UserIsServiceAccount: user.UserIsServiceAccount,
+		UserStatus:           user.UserStatus,
+		UserStatusReason:     user.UserStatusReason,
+		UserSuspendedUntil:   user.UserSuspendedUntil,
+		UserLangCode:         user.UserLangCode,
+		UserLastLogin:        user.UserLastLogin,
+		UserPhoto:            user.UserPhoto,
+		CreatedBy:            user.CreatedBy,
+		CreatedAt:            user.CreatedAt,
+		UpdatedBy:            user.UpdatedBy,
+		UpdatedAt:            user.UpdatedAt,
+	}
+}
+
 func ToUserLoginResponse(user User) UserLoginResponse {
 	return UserLoginResponse{
-		UserId:       user.UserId,
-		UserPassword: user.UserPassword,
+		UserId:             user.UserId,
+		UserPassword:       user.UserPassword,
+		UserStatus:         user.UserStatus,
+		UserSuspendedUntil: user.UserSuspendedUntil,
+		SessionId:          user.SessionId,
 	}
 }
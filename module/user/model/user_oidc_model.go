@@ -0,0 +1,57 @@
+package model
+
+import "database/sql"
+
+// model UserOidcState, the state of one started single sign-on, the state itself is only kept as a hash. StateUserId
+// is the signed in user who started it to link an identity, 0 for a login.
+type UserOidcState struct {
+	StateId           int
+	StateKey          string
+	StateProvider     string
+	StateUserId       int
+	StateNonce        string
+	StateCodeVerifier string
+	StateExpiredAt    string
+	StateCreatedAt    string
+}
+
+// model UserIdentity links the subject of a provider to a user
+type UserIdentity struct {
+	IdentityId         int
+	IdentityUserId     int
+	IdentityProvider   string
+	IdentitySubject    string
+	IdentityEmail      string
+	IdentityEmailCheck sql.NullString
+	IdentityCreatedAt  string
+}
+
+// request
+type UserOidcCallbackRequest struct {
+	Code  string `validate:"required,min=1" json:"code"`
+	State string `validate:"required,min=1" json:"state"`
+}
+
+type UserOidcLoginRequest struct {
+	IdentityProvider  string
+	IdentitySubject   string
+	IdentityEmail     string
+	EmailVerified     bool
+	UserName          string
+	UserLangCode      string
+	AutoCreate        bool
+	IdentityCreatedAt string
+}
+
+type UserOidcLinkRequest struct {
+	UserId            int
+	IdentityProvider  string
+	IdentitySubject   string
+	IdentityEmail     string
+	IdentityCreatedAt string
+}
+
+// rersponse
+type UserOidcAuthorizationResponse struct {
+	AuthorizationUrl string `json:"authorization_url"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// model UserPasswordHistory, every hash a user has set is kept so old passwords can not be chosen again
+type UserPasswordHistory struct {
+	HistoryId        int
+	HistoryUserId    int
+	HistoryPassword  string
+	HistoryCreatedAt string
+}
@@ -0,0 +1,28 @@
+package model
+
+// model UserPasswordReset, the token itself is only kept as a hash
+type UserPasswordReset struct {
+	ResetId        int
+	ResetUserId    int
+	ResetToken     string
+	ResetExpiredAt string
+	ResetUsedAt    string
+	ResetCreatedAt string
+}
+
+// request
+type UserPasswordForgotRequest struct {
+	UserEmail      string `validate:"required,min=1,email" json:"email"`
+	ResetToken     string `json:"-"`
+	ResetExpiredAt string `json:"-"`
+	ResetCreatedAt string `json:"-"`
+	MaxRequests    int    `json:"-"`
+	WindowStart    string `json:"-"`
+}
+
+type UserPasswordResetTokenRequest struct {
+	ResetToken   string `validate:"required,min=1" json:"token"`
+	NewPassword  string `validate:"required,min=1" json:"new_password"`
+	UserPassword string `json:"-"`
+	ResetUsedAt  string `json:"-"`
+}
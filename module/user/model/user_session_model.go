@@ -0,0 +1,65 @@
+package model
+
+import "database/sql"
+
+// model UserSession, one row per logged in device
+type UserSession struct {
+	SessionId                int
+	SessionUserId            int
+	SessionUserAgent         string
+	SessionUserAgentCheck    sql.NullString
+	SessionIp                string
+	SessionIpCheck           sql.NullString
+	SessionToken             string
+	SessionTokenCheck        sql.NullString
+	SessionTokenRefresh      string
+	SessionTokenRefreshCheck sql.NullString
+	SessionCreatedAt         string
+	SessionLastSeenAt        string
+	SessionLastSeenAtCheck   sql.NullString
+	SessionRevokedAt         string
+}
+
+// request
+type UserSessionCreateRequest struct {
+	SessionUserId    int    `validate:"required"`
+	SessionUserAgent string `validate:"max=255"`
+	SessionIp        string `validate:"max=45"`
+	SessionCreatedAt string `validate:"required"`
+}
+
+type UserSessionRevokeRequest struct {
+	SessionId        int    `validate:"required"`
+	SessionUserId    int    `validate:"required"`
+	SessionRevokedAt string `validate:"required"`
+}
+
+// rersponse
+type UserSessionResponse struct {
+	SessionId         int    `json:"session_id"`
+	SessionUserId     int    `json:"user_id"`
+	SessionUserAgent  string `json:"user_agent"`
+	SessionIp         string `json:"ip"`
+	SessionCreatedAt  string `json:"created_at"`
+	SessionLastSeenAt string `json:"last_seen_at"`
+	SessionIsCurrent  bool   `json:"is_current"`
+}
+
+func ToUserSessionResponse(session UserSession) UserSessionResponse {
+	return UserSessionResponse{
+		SessionId:         session.SessionId,
+		SessionUserId:     session.SessionUserId,
+		SessionUserAgent:  session.SessionUserAgent,
+		SessionIp:         session.SessionIp,
+		SessionCreatedAt:  session.SessionCreatedAt,
+		SessionLastSeenAt: session.SessionLastSeenAt,
+	}
+}
+
+func ToUserSessionResponses(sessions []UserSession) []UserSessionResponse {
+	var sessionResponses []UserSessionResponse
+	for _, session := range sessions {
+		sessionResponses = append(sessionResponses, ToUserSessionResponse(session))
+	}
+	return sessionResponses
+}
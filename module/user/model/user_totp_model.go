@@ -0,0 +1,86 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// model UserTotp, the authenticator app secret of a user, two factor is only enabled once confirmed with a first code
+type UserTotp struct {
+	TotpUserId           int
+	TotpSecret           string
+	TotpLastStep         int64
+	TotpConfirmedAt      string
+	TotpConfirmedAtCheck sql.NullString
+	TotpCreatedAt        string
+}
+
+// model UserRecoveryCode, single use codes for a lost authenticator, only kept as a hash
+type UserRecoveryCode struct {
+	RecoveryId        int
+	RecoveryUserId    int
+	RecoveryCode      string
+	RecoveryUsedAt    string
+	RecoveryCreatedAt string
+}
+
+// request
+type UserTotpEnrollRequest struct {
+	UserId        int    `validate:"required"`
+	TotpSecret    string `validate:"required"`
+	TotpCreatedAt string `validate:"required"`
+}
+
+type UserTotpConfirmRequest struct {
+	Code            string    `validate:"required,len=6,numeric" json:"code"`
+	UserId          int       `json:"-"`
+	RecoveryCodes   []string  `json:"-"`
+	CurrentTime     time.Time `json:"-"`
+	TotpConfirmedAt string    `json:"-"`
+}
+
+type UserTotpDisableRequest struct {
+	CurrentPassword string    `validate:"required,min=1" json:"current_password"`
+	Code            string    `validate:"required,min=1" json:"code"`
+	UserId          int       `json:"-"`
+	CurrentTime     time.Time `json:"-"`
+}
+
+// UserTotpVerifyRequest completes a login, the code is either the current authenticator code or a recovery code
+type UserTotpVerifyRequest struct {
+	ChallengeToken string    `validate:"required,min=1" json:"challenge_token"`
+	Code           string    `validate:"required,min=1" json:"code"`
+	UserId         int       `json:"-"`
+	CurrentTime    time.Time `json:"-"`
+}
+
+// rersponse
+type UserTotpResponse struct {
+	UserId          int    `json:"user_id"`
+	TotpIsEnabled   bool   `json:"is_enabled"`
+	TotpConfirmedAt string `json:"confirmed_at"`
+	TotpSecret      string `json:"-"`
+}
+
+type UserTotpEnrollResponse struct {
+	TotpSecret string `json:"secret"`
+	TotpUri    string `json:"otpauth_uri"`
+}
+
+type UserTotpConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type UserLoginChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+}
+
+func ToUserTotpResponse(totp UserTotp) UserTotpResponse {
+	return UserTotpResponse{
+		UserId:          totp.TotpUserId,
+		TotpIsEnabled:   totp.TotpConfirmedAt != "",
+		TotpConfirmedAt: totp.TotpConfirmedAt,
+		TotpSecret:      totp.TotpSecret,
+	}
+}
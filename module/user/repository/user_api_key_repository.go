@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserApiKeyRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, apiKey model.UserApiKey) model.UserApiKey
+	Revoke(ctx context.Context, tx *sql.Tx, apiKey model.UserApiKey)
+	UpdateLastUsed(ctx context.Context, tx *sql.Tx, apiKey model.UserApiKey)
+	FindById(ctx context.Context, tx *sql.Tx, apiKeyId int) (model.UserApiKey, error)
+	FindByKey(ctx context.Context, tx *sql.Tx, apiKey string) (model.UserApiKey, error)
+	FindByUserId(ctx context.Context, tx *sql.Tx, userId int) []model.UserApiKey
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+	"strings"
+)
+
+type UserApiKeyRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewUserApiKeyRepository(db *sql.DB) UserApiKeyRepository {
+	return &UserApiKeyRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *UserApiKeyRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, apiKey model.UserApiKey) model.UserApiKey {
+	var expiredAt interface{}
+	if apiKey.ApiKeyExpiredAt != "" {
+		expiredAt = apiKey.ApiKeyExpiredAt
+	}
+
+	SQL := `INSERT INTO api_key
+			(
+				apikey_user_id,
+				apikey_name,
+				apikey_prefix,
+				apikey_key,
+				apikey_scopes,
+				apikey_expired_at,
+				created_by,
+				created_at
+			) VALUES (
+				?,
+				?,
+				?,
+				?,
+				?,
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		apiKey.ApiKeyUserId,
+		apiKey.ApiKeyName,
+		apiKey.ApiKeyPrefix,
+		helper.HashToken(apiKey.ApiKey),
+		strings.Join(apiKey.ApiKeyScopes, ","),
+		expiredAt,
+		apiKey.CreatedBy,
+		apiKey.CreatedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	apiKey.ApiKeyId = int(id)
+	return apiKey
+}
+
+func (repository *UserApiKeyRepositoryImpl) Revoke(ctx context.Context, tx *sql.Tx, apiKey model.UserApiKey) {
+	SQL := `UPDATE
+				api_key
+			SET
+				apikey_revoked_at = ?
+			WHERE
+				apikey_id = ?
+				AND apikey_revoked_at IS NULL`
+	_, err := tx.ExecContext(ctx, SQL,
+		apiKey.ApiKeyRevokedAt,
+		apiKey.ApiKeyId)
+	helper.IfError(err)
+}
+
+func (repository *UserApiKeyRepositoryImpl) UpdateLastUsed(ctx context.Context, tx *sql.Tx, apiKey model.UserApiKey) {
+	SQL := `UPDATE
+				api_key
+			SET
+				apikey_last_used_at = ?
+			WHERE
+				apikey_id = ?`
+	_, err := tx.ExecContext(ctx, SQL,
+		apiKey.ApiKeyLastUsedAt,
+		apiKey.ApiKeyId)
+	helper.IfError(err)
+}
+
+func (repository *UserApiKeyRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, apiKeyId int) (model.UserApiKey, error) {
+	SQL := `SELECT
+				apikey_id,
+				apikey_user_id,
+				apikey_name,
+				apikey_prefix,
+				apikey_scopes,
+				apikey_expired_at,
+				apikey_last_used_at,
+				apikey_revoked_at,
+				created_by,
+				created_at
+			FROM
+				api_key
+			WHERE
+				apikey_id = ?`
+	rows, err := tx.QueryContext(ctx, SQL, apiKeyId)
+	helper.IfError(err)
+	defer rows.Close()
+
+	apiKey := model.UserApiKey{}
+	if rows.Next() {
+		apiKey = scanApiKey(rows)
+	}
+
+	return apiKey, nil
+}
+
+// FindByKey looks a key up by its hash, revoked and expired keys are returned too so the caller decides
+func (repository *UserApiKeyRepositoryImpl) FindByKey(ctx context.Context, tx *sql.Tx, key string) (model.UserApiKey, error) {
+	SQL := `SELECT
+				apikey_id,
+				apikey_user_id,
+				apikey_name,
+				apikey_prefix,
+				apikey_scopes,
+				apikey_expired_at,
+				apikey_last_used_at,
+				apikey_revoked_at,
+				created_by,
+				created_at
+			FROM
+				api_key
+			WHERE
+				apikey_key = ?`
+	rows, err := tx.QueryContext(ctx, SQL, helper.HashToken(key))
+	helper.IfError(err)
+	defer rows.Close()
+
+	apiKey := model.UserApiKey{}
+	if rows.Next() {
+		apiKey = scanApiKey(rows)
+	}
+
+	return apiKey, nil
+}
+
+func (repository *UserApiKeyRepositoryImpl) FindByUserId(ctx context.Context, tx *sql.Tx, userId int) []model.UserApiKey {
+	SQL := `SELECT
+				apikey_id,
+				apikey_user_id,
+				apikey_name,
+				apikey_prefix,
+				apikey_scopes,
+				apikey_expired_at,
+				apikey_last_used_at,
+				apikey_revoked_at,
+				created_by,
+				created_at
+			FROM
+				api_key
+			WHERE
+				apikey_user_id = ?
+			ORDER BY
+				apikey_id DESC`
+	rows, err := tx.QueryContext(ctx, SQL, userId)
+	helper.IfError(err)
+	defer rows.Close()
+
+	var apiKeys []model.UserApiKey
+	for rows.Next() {
+		apiKeys = append(apiKeys, scanApiKey(rows))
+	}
+
+	return apiKeys
+}
+
+func scanApiKey(rows *sql.Rows) model.UserApiKey {
+	apiKey := model.UserApiKey{}
+	err := rows.Scan(
+		&apiKey.ApiKeyId,
+		&apiKey.ApiKeyUserId,
+		&apiKey.ApiKeyName,
+		&apiKey.ApiKeyPrefix,
+		&apiKey.ApiKeyScopesText,
+		&apiKey.ApiKeyExpiredAtCheck,
+		&apiKey.ApiKeyLastUsedAtCheck,
+		&apiKey.ApiKeyRevokedAtCheck,
+		&apiKey.CreatedByCheck,
+		&apiKey.CreatedAt)
+	helper.IfError(err)
+
+	apiKey.ApiKeyScopes = model.ApiKeyScopesFromText(apiKey.ApiKeyScopesText)
+	if apiKey.ApiKeyExpiredAtCheck.Valid {
+		apiKey.ApiKeyExpiredAt = apiKey.ApiKeyExpiredAtCheck.String
+	}
+	if apiKey.ApiKeyLastUsedAtCheck.Valid {
+		apiKey.ApiKeyLastUsedAt = apiKey.ApiKeyLastUsedAtCheck.String
+	}
+	if apiKey.ApiKeyRevokedAtCheck.Valid {
+		apiKey.ApiKeyRevokedAt = apiKey.ApiKeyRevokedAtCheck.String
+	}
+	if apiKey.CreatedByCheck.Valid {
+		apiKey.CreatedBy = int(apiKey.CreatedByCheck.Int32)
+	}
+
+	return apiKey
+}
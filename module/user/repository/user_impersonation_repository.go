@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserImpersonationRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, impersonation model.UserImpersonation) model.UserImpersonation
+	End(ctx context.Context, tx *sql.Tx, impersonation model.UserImpersonation) bool
+	FindBySessionId(ctx context.Context, tx *sql.Tx, sessionId int) model.UserImpersonation
+	SaveAction(ctx context.Context, tx *sql.Tx, action model.UserImpersonationAction) model.UserImpersonationAction
+}
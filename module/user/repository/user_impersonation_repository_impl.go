@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserImpersonationRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewUserImpersonationRepository(db *sql.DB) UserImpersonationRepository {
+	return &UserImpersonationRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *UserImpersonationRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, impersonation model.UserImpersonation) model.UserImpersonation {
+	SQL := `INSERT INTO impersonation
+			(
+				impersonation_actor_id,
+				impersonation_user_id,
+				impersonation_session_id,
+				impersonation_reason,
+				impersonation_started_at
+			) VALUES (
+				?,
+				?,
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		impersonation.ImpersonationActorId,
+		impersonation.ImpersonationUserId,
+		impersonation.ImpersonationSessionId,
+		impersonation.ImpersonationReason,
+		impersonation.ImpersonationStartedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	impersonation.ImpersonationId = int(id)
+	return impersonation
+}
+
+// End sets the end time once, false means the impersonation had already ended
+func (repository *UserImpersonationRepositoryImpl) End(ctx context.Context, tx *sql.Tx, impersonation model.UserImpersonation) bool {
+	SQL := `UPDATE
+				impersonation
+			SET
+				impersonation_ended_at = ?
+			WHERE
+				impersonation_id = ?
+				AND impersonation_ended_at IS NULL`
+	result, err := tx.ExecContext(ctx, SQL,
+		impersonation.ImpersonationEndedAt,
+		impersonation.ImpersonationId)
+	helper.IfError(err)
+
+	affected, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return affected == 1
+}
+
+func (repository *UserImpersonationRepositoryImpl) FindBySessionId(ctx context.Context, tx *sql.Tx, sessionId int) model.UserImpersonation {
+	SQL := `SELECT
+				impersonation_id,
+				impersonation_actor_id,
+				impersonation_user_id,
+				impersonation_session_id,
+				impersonation_reason,
+				impersonation_started_at,
+				impersonation_ended_at
+			FROM
+				impersonation
+			WHERE
+				impersonation_session_id = ?`
+	rows, err := tx.QueryContext(ctx, SQL, sessionId)
+	helper.IfError(err)
+	defer rows.Close()
+
+	impersonation := model.UserImpersonation{}
+	if rows.Next() {
+		err := rows.Scan(
+			&impersonation.ImpersonationId,
+			&impersonation.ImpersonationActorId,
+			&impersonation.ImpersonationUserId,
+			&impersonation.ImpersonationSessionId,
+			&impersonation.ImpersonationReason,
+			&impersonation.ImpersonationStartedAt,
+			&impersonation.ImpersonationEndedAtCheck)
+		helper.IfError(err)
+
+		if impersonation.ImpersonationEndedAtCheck.Valid {
+			impersonation.ImpersonationEndedAt = impersonation.ImpersonationEndedAtCheck.String
+		}
+	}
+
+	return impersonation
+}
+
+func (repository *UserImpersonationRepositoryImpl) SaveAction(ctx context.Context, tx *sql.Tx, action model.UserImpersonationAction) model.UserImpersonationAction {
+	SQL := `INSERT INTO impersonation_action
+			(
+				action_impersonation_id,
+				action_method,
+				action_path,
+				action_created_at
+			) VALUES (
+				?,
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		action.ActionImpersonationId,
+		action.ActionMethod,
+		action.ActionPath,
+		action.ActionCreatedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	action.ActionId = int(id)
+	return action
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserInvitationRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, invitation model.UserInvitation) model.UserInvitation
+	FindByToken(ctx context.Context, tx *sql.Tx, userId int, invitationToken string, currentTime string) model.UserInvitation
+	Accept(ctx context.Context, tx *sql.Tx, invitation model.UserInvitation) bool
+	RevokeByUserId(ctx context.Context, tx *sql.Tx, userId int, revokedAt string) int
+}
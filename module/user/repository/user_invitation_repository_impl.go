@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserInvitationRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewUserInvitationRepository(db *sql.DB) UserInvitationRepository {
+	return &UserInvitationRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *UserInvitationRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, invitation model.UserInvitation) model.UserInvitation {
+	SQL := `INSERT INTO invitation
+			(
+				invitation_user_id,
+				invitation_token,
+				invitation_expired_at,
+				created_by,
+				created_at
+			) VALUES (
+				?,
+				?,
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		invitation.InvitationUserId,
+		helper.HashToken(invitation.InvitationToken),
+		invitation.InvitationExpiredAt,
+		invitation.CreatedBy,
+		invitation.CreatedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	invitation.InvitationId = int(id)
+	return invitation
+}
+
+// FindByToken only returns an invitation of the user that is neither accepted, revoked nor expired at currentTime
+func (repository *UserInvitationRepositoryImpl) FindByToken(ctx context.Context, tx *sql.Tx, userId int, invitationToken string, currentTime string) model.UserInvitation {
+	SQL := `SELECT
+				invitation_id,
+				invitation_user_id,
+				invitation_expired_at,
+				created_at
+			FROM
+				invitation
+			WHERE
+				invitation_token = ?
+				AND invitation_user_id = ?
+				AND invitation_accepted_at IS NULL
+				AND invitation_revoked_at IS NULL
+				AND invitation_expired_at > ?`
+	rows, err := tx.QueryContext(ctx, SQL, helper.HashToken(invitationToken), userId, currentTime)
+	helper.IfError(err)
+	defer rows.Close()
+
+	invitation := model.UserInvitation{}
+	if rows.Next() {
+		err := rows.Scan(
+			&invitation.InvitationId,
+			&invitation.InvitationUserId,
+			&invitation.InvitationExpiredAt,
+			&invitation.CreatedAt)
+		helper.IfError(err)
+	}
+
+	return invitation
+}
+
+// Accept marks the invitation accepted, false means it was accepted or revoked in the meantime
+func (repository *UserInvitationRepositoryImpl) Accept(ctx context.Context, tx *sql.Tx, invitation model.UserInvitation) bool {
+	SQL := `UPDATE
+				invitation
+			SET
+				invitation_accepted_at = ?
+			WHERE
+				invitation_id = ?
+				AND invitation_accepted_at IS NULL
+				AND invitation_revoked_at IS NULL`
+	result, err := tx.ExecContext(ctx, SQL,
+		invitation.InvitationAcceptedAt,
+		invitation.InvitationId)
+	helper.IfError(err)
+
+	affected, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return affected == 1
+}
+
+// RevokeByUserId revokes every open invitation of the user and returns how many there were
+func (repository *UserInvitationRepositoryImpl) RevokeByUserId(ctx context.Context, tx *sql.Tx, userId int, revokedAt string) int {
+	SQL := `UPDATE
+				invitation
+			SET
+				invitation_revoked_at = ?
+			WHERE
+				invitation_user_id = ?
+				AND invitation_accepted_at IS NULL
+				AND invitation_revoked_at IS NULL`
+	result, err := tx.ExecContext(ctx, SQL,
+		revokedAt,
+		userId)
+	helper.IfError(err)
+
+	affected, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return int(affected)
+}
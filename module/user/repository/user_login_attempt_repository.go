@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserLoginAttemptRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, attempt model.UserLoginAttempt) model.UserLoginAttempt
+	Delete(ctx context.Context, tx *sql.Tx, attemptKeyType string, attemptKey string)
+	FindByKey(ctx context.Context, tx *sql.Tx, attemptKeyType string, attemptKey string) (model.UserLoginAttempt, error)
+}
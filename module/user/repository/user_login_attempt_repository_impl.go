@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserLoginAttemptRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewUserLoginAttemptRepository(db *sql.DB) UserLoginAttemptRepository {
+	return &UserLoginAttemptRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *UserLoginAttemptRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, attempt model.UserLoginAttempt) model.UserLoginAttempt {
+	var lockedUntil interface{}
+	if attempt.AttemptLockedUntil != "" {
+		lockedUntil = attempt.AttemptLockedUntil
+	}
+
+	SQL := `INSERT INTO login_attempt
+			(
+				attempt_key_type,
+				attempt_key,
+				attempt_count,
+				attempt_last_at,
+				attempt_locked_until
+			) VALUES (
+				?,
+				?,
+				?,
+				?,
+				?
+			) ON DUPLICATE KEY UPDATE
+				attempt_count = VALUES(attempt_count),
+				attempt_last_at = VALUES(attempt_last_at),
+				attempt_locked_until = VALUES(attempt_locked_until)`
+	_, err := tx.ExecContext(ctx, SQL,
+		attempt.AttemptKeyType,
+		attempt.AttemptKey,
+		attempt.AttemptCount,
+		attempt.AttemptLastAt,
+		lockedUntil)
+	helper.IfError(err)
+
+	return attempt
+}
+
+func (repository *UserLoginAttemptRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, attemptKeyType string, attemptKey string) {
+	SQL := `DELETE FROM login_attempt WHERE attempt_key_type = ? AND attempt_key = ?`
+	_, err := tx.ExecContext(ctx, SQL, attemptKeyType, attemptKey)
+	helper.IfError(err)
+}
+
+func (repository *UserLoginAttemptRepositoryImpl) FindByKey(ctx context.Context, tx *sql.Tx, attemptKeyType string, attemptKey string) (model.UserLoginAttempt, error) {
+	SQL := `SELECT
+				attempt_key_type,
+				attempt_key,
+				attempt_count,
+				attempt_last_at,
+				attempt_locked_until
+			FROM
+				login_attempt
+			WHERE
+				attempt_key_type = ?
+				AND attempt_key = ?`
+	rows, err := tx.QueryContext(ctx, SQL, attemptKeyType, attemptKey)
+	helper.IfError(err)
+	defer rows.Close()
+
+	attempt := model.UserLoginAttempt{}
+	if rows.Next() {
+		err := rows.Scan(
+			&attempt.AttemptKeyType,
+			&attempt.AttemptKey,
+			&attempt.AttemptCount,
+			&attempt.AttemptLastAt,
+			&attempt.AttemptLockedUntilCheck)
+		helper.IfError(err)
+	}
+
+	if attempt.AttemptLockedUntilCheck.Valid {
+		attempt.AttemptLockedUntil = attempt.AttemptLockedUntilCheck.String
+	}
+
+	return attempt, nil
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserLoginEventRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, event model.UserLoginEvent) model.UserLoginEvent
+	FindByUserId(ctx context.Context, tx *sql.Tx, userId int, query helper.ListQuery) ([]model.UserLoginEvent, helper.Pagination)
+	FindKnownDevice(ctx context.Context, tx *sql.Tx, userId int, ip string, userAgent string) (bool, bool, bool)
+}
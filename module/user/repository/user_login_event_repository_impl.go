@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserLoginEventRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewUserLoginEventRepository(db *sql.DB) UserLoginEventRepository {
+	return &UserLoginEventRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *UserLoginEventRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, event model.UserLoginEvent) model.UserLoginEvent {
+	var userId interface{}
+	if event.EventUserId != 0 {
+		userId = event.EventUserId
+	}
+
+	SQL := `INSERT INTO login_event
+			(
+				event_user_id,
+				event_email,
+				event_type,
+				event_is_success,
+				event_reason,
+				event_ip,
+				event_user_agent,
+				event_created_at
+			) VALUES (
+				?,
+				?,
+				?,
+				?,
+				?,
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		userId,
+		event.EventEmail,
+		event.EventType,
+		event.EventIsSuccess,
+		event.EventReason,
+		event.EventIp,
+		event.EventUserAgent,
+		event.EventCreatedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	event.EventId = int(id)
+	return event
+}
+
+// FindByUserId pages through the events of the user
+func (repository *UserLoginEventRepositoryImpl) FindByUserId(ctx context.Context, tx *sql.Tx, userId int, query helper.ListQuery) ([]model.UserLoginEvent, helper.Pagination) {
+	// the condition of the user comes before the filters, so its argument goes first
+	where, args := query.Where("event_user_id = ?")
+	SQL := `SELECT
+				COUNT(*)
+			FROM
+				login_event
+			` + where
+	var total int
+	err := tx.QueryRowContext(ctx, SQL, append([]interface{}{userId}, args...)...).Scan(&total)
+	helper.IfError(err)
+
+	where, args = query.PageWhere("event_user_id = ?")
+	orderBy, orderArgs := query.OrderBy()
+	SQL = `SELECT
+				event_id,
+				event_user_id,
+				event_email,
+				event_type,
+				event_is_success,
+				event_reason,
+				event_ip,
+				event_user_agent,
+				event_created_at` + query.CursorColumns() + `
+			FROM
+				login_event
+			` + where + `
+			` + orderBy
+	rows, err := tx.QueryContext(ctx, SQL, append(append([]interface{}{userId}, args...), orderArgs...)...)
+	helper.IfError(err)
+	defer rows.Close()
+
+	var events []model.UserLoginEvent
+	var lastKeys []interface{}
+	hasMore := false
+	for rows.Next() {
+		if len(events) == query.Limit {
+			hasMore = true
+			break
+		}
+
+		event := model.UserLoginEvent{}
+		keys := query.CursorKeys()
+		err := rows.Scan(append([]interface{}{
+			&event.EventId,
+			&event.EventUserIdCheck,
+			&event.EventEmail,
+			&event.EventType,
+			&event.EventIsSuccess,
+			&event.EventReason,
+			&event.EventIpCheck,
+			&event.EventUserAgentCheck,
+			&event.EventCreatedAt}, keys...)...)
+		helper.IfError(err)
+
+		if event.EventUserIdCheck.Valid {
+			event.EventUserId = int(event.EventUserIdCheck.Int32)
+		}
+		if event.EventIpCheck.Valid {
+			event.EventIp = event.EventIpCheck.String
+		}
+		if event.EventUserAgentCheck.Valid {
+			event.EventUserAgent = event.EventUserAgentCheck.String
+		}
+
+		events = append(events, event)
+		lastKeys = keys
+	}
+
+	return events, query.Pagination(total, hasMore, lastKeys)
+}
+
+// FindKnownDevice tells whether the user has signed in successfully before and, if so, whether once from that ip
+// and once with that user agent
+func (repository *UserLoginEventRepositoryImpl) FindKnownDevice(ctx context.Context, tx *sql.Tx, userId int, ip string, userAgent string) (bool, bool, bool) {
+	SQL := `SELECT
+				COUNT(*),
+				COALESCE(SUM(event_ip = ?), 0),
+				COALESCE(SUM(event_user_agent = ?), 0)
+			FROM
+				login_event
+			WHERE
+				event_user_id = ?
+				AND event_type = 'login'
+				AND event_is_success = 1`
+	rows, err := tx.QueryContext(ctx, SQL, ip, userAgent, userId)
+	helper.IfError(err)
+	defer rows.Close()
+
+	var logins, sameIp, sameUserAgent int
+	if rows.Next() {
+		err := rows.Scan(
+			&logins,
+			&sameIp,
+			&sameUserAgent)
+		helper.IfError(err)
+	}
+
+	return logins > 0, sameIp > 0, sameUserAgent > 0
+}
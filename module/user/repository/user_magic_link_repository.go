@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserMagicLinkRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, magicLink model.UserMagicLink) model.UserMagicLink
+	CountByUserId(ctx context.Context, tx *sql.Tx, userId int, since string) int
+	Use(ctx context.Context, tx *sql.Tx, magicLink model.UserMagicLink) bool
+}
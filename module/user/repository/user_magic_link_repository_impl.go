@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserMagicLinkRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewUserMagicLinkRepository(db *sql.DB) UserMagicLinkRepository {
+	return &UserMagicLinkRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *UserMagicLinkRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, magicLink model.UserMagicLink) model.UserMagicLink {
+	SQL := `INSERT INTO magic_link
+			(
+				link_user_id,
+				link_token,
+				link_expired_at,
+				link_created_at
+			) VALUES (
+				?,
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		magicLink.LinkUserId,
+		helper.HashToken(magicLink.LinkToken),
+		magicLink.LinkExpiredAt,
+		magicLink.LinkCreatedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	magicLink.LinkId = int(id)
+	return magicLink
+}
+
+// CountByUserId counts the links sent to the user since that time, used or not
+func (repository *UserMagicLinkRepositoryImpl) CountByUserId(ctx context.Context, tx *sql.Tx, userId int, since string) int {
+	SQL := `SELECT
+				COUNT(*)
+			FROM
+				magic_link
+			WHERE
+				link_user_id = ?
+				AND link_created_at > ?`
+	rows, err := tx.QueryContext(ctx, SQL, userId, since)
+	helper.IfError(err)
+	defer rows.Close()
+
+	count := 0
+	if rows.Next() {
+		err := rows.Scan(&count)
+		helper.IfError(err)
+	}
+
+	return count
+}
+
+// Use burns the link of the user, false means it is unknown, expired or was used already
+func (repository *UserMagicLinkRepositoryImpl) Use(ctx context.Context, tx *sql.Tx, magicLink model.UserMagicLink) bool {
+	SQL := `UPDATE
+				magic_link
+			SET
+				link_used_at = ?
+			WHERE
+				link_token = ?
+				AND link_user_id = ?
+				AND link_used_at IS NULL
+				AND link_expired_at > ?`
+	result, err := tx.ExecContext(ctx, SQL,
+		magicLink.LinkUsedAt,
+		helper.HashToken(magicLink.LinkToken),
+		magicLink.LinkUserId,
+		magicLink.LinkUsedAt)
+	helper.IfError(err)
+
+	affected, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return affected == 1
+}
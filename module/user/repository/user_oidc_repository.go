@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserOidcRepository interface {
+	SaveState(ctx context.Context, tx *sql.Tx, state model.UserOidcState) model.UserOidcState
+	UseState(ctx context.Context, tx *sql.Tx, stateKey string, provider string, currentTime string) model.UserOidcState
+	SaveIdentity(ctx context.Context, tx *sql.Tx, identity model.UserIdentity) model.UserIdentity
+	FindIdentity(ctx context.Context, tx *sql.Tx, provider string, subject string) model.UserIdentity
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserOidcRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewUserOidcRepository(db *sql.DB) UserOidcRepository {
+	return &UserOidcRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *UserOidcRepositoryImpl) SaveState(ctx context.Context, tx *sql.Tx, state model.UserOidcState) model.UserOidcState {
+	SQL := `INSERT INTO oidc_state
+			(
+				state_key,
+				state_provider,
+				state_user_id,
+				state_nonce,
+				state_code_verifier,
+				state_expired_at,
+				state_created_at
+			) VALUES (
+				?,
+				?,
+				?,
+				?,
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		helper.HashToken(state.StateKey),
+		state.StateProvider,
+		state.StateUserId,
+		state.StateNonce,
+		state.StateCodeVerifier,
+		state.StateExpiredAt,
+		state.StateCreatedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	state.StateId = int(id)
+	return state
+}
+
+// UseState returns the state of that provider when it has not expired at currentTime and deletes it, so a state
+// can only complete one login. Expired states of every provider are cleaned up on the way.
+func (repository *UserOidcRepositoryImpl) UseState(ctx context.Context, tx *sql.Tx, stateKey string, provider string, currentTime string) model.UserOidcState {
+	SQL := `SELECT
+				state_id,
+				state_provider,
+				state_user_id,
+				state_nonce,
+				state_code_verifier,
+				state_expired_at,
+				state_created_at
+			FROM
+				oidc_state
+			WHERE
+				state_key = ?
+				AND state_provider = ?
+				AND state_expired_at > ?
+			FOR UPDATE`
+	rows, err := tx.QueryContext(ctx, SQL, helper.HashToken(stateKey), provider, currentTime)
+	helper.IfError(err)
+
+	state := model.UserOidcState{}
+	if rows.Next() {
+		err := rows.Scan(
+			&state.StateId,
+			&state.StateProvider,
+			&state.StateUserId,
+			&state.StateNonce,
+			&state.StateCodeVerifier,
+			&state.StateExpiredAt,
+			&state.StateCreatedAt)
+		helper.IfError(err)
+	}
+	rows.Close()
+
+	if state.StateId != 0 {
+		SQL = `DELETE FROM oidc_state WHERE state_id = ?`
+		result, err := tx.ExecContext(ctx, SQL, state.StateId)
+		helper.IfError(err)
+
+		affected, err := result.RowsAffected()
+		helper.IfError(err)
+		if affected == 0 {
+			state = model.UserOidcState{}
+		}
+	}
+
+	SQL = `DELETE FROM oidc_state WHERE state_expired_at <= ?`
+	_, err = tx.ExecContext(ctx, SQL, currentTime)
+	helper.IfError(err)
+
+	return state
+}
+
+func (repository *UserOidcRepositoryImpl) SaveIdentity(ctx context.Context, tx *sql.Tx, identity model.UserIdentity) model.UserIdentity {
+	SQL := `INSERT INTO user_identity
+			(
+				identity_user_id,
+				identity_provider,
+				identity_subject,
+				identity_email,
+				identity_created_at
+			) VALUES (
+				?,
+				?,
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		identity.IdentityUserId,
+		identity.IdentityProvider,
+		identity.IdentitySubject,
+		identity.IdentityEmail,
+		identity.IdentityCreatedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	identity.IdentityId = int(id)
+	return identity
+}
+
+func (repository *UserOidcRepositoryImpl) FindIdentity(ctx context.Context, tx *sql.Tx, provider string, subject string) model.UserIdentity {
+	SQL := `SELECT
+				identity_id,
+				identity_user_id,
+				identity_provider,
+				identity_subject,
+				identity_email,
+				identity_created_at
+			FROM
+				user_identity
+			WHERE
+				identity_provider = ?
+				AND identity_subject = ?`
+	rows, err := tx.QueryContext(ctx, SQL, provider, subject)
+	helper.IfError(err)
+	defer rows.Close()
+
+	identity := model.UserIdentity{}
+	if rows.Next() {
+		err := rows.Scan(
+			&identity.IdentityId,
+			&identity.IdentityUserId,
+			&identity.IdentityProvider,
+			&identity.IdentitySubject,
+			&identity.IdentityEmailCheck,
+			&identity.IdentityCreatedAt)
+		helper.IfError(err)
+
+		if identity.IdentityEmailCheck.Valid {
+			identity.IdentityEmail = identity.IdentityEmailCheck.String
+		}
+	}
+
+	return identity
+}
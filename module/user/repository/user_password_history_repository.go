@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserPasswordHistoryRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, history model.UserPasswordHistory) model.UserPasswordHistory
+	FindByUserId(ctx context.Context, tx *sql.Tx, userId int, limit int) []model.UserPasswordHistory
+}
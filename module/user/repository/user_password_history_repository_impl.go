@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserPasswordHistoryRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewUserPasswordHistoryRepository(db *sql.DB) UserPasswordHistoryRepository {
+	return &UserPasswordHistoryRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *UserPasswordHistoryRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, history model.UserPasswordHistory) model.UserPasswordHistory {
+	SQL := `INSERT INTO password_history
+			(
+				history_user_id,
+				history_password,
+				history_created_at
+			) VALUES (
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		history.HistoryUserId,
+		history.HistoryPassword,
+		history.HistoryCreatedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	history.HistoryId = int(id)
+	return history
+}
+
+// FindByUserId returns the latest limit hashes of the user, newest first
+func (repository *UserPasswordHistoryRepositoryImpl) FindByUserId(ctx context.Context, tx *sql.Tx, userId int, limit int) []model.UserPasswordHistory {
+	SQL := `SELECT
+				history_id,
+				history_user_id,
+				history_password,
+				history_created_at
+			FROM
+				password_history
+			WHERE
+				history_user_id = ?
+			ORDER BY
+				history_id DESC
+			LIMIT ?`
+	rows, err := tx.QueryContext(ctx, SQL, userId, limit)
+	helper.IfError(err)
+	defer rows.Close()
+
+	var histories []model.UserPasswordHistory
+	for rows.Next() {
+		history := model.UserPasswordHistory{}
+		err := rows.Scan(
+			&history.HistoryId,
+			&history.HistoryUserId,
+			&history.HistoryPassword,
+			&history.HistoryCreatedAt)
+		helper.IfError(err)
+
+		histories = append(histories, history)
+	}
+
+	return histories
+}
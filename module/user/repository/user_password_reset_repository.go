@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserPasswordResetRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, passwordReset model.UserPasswordReset) model.UserPasswordReset
+	FindByToken(ctx context.Context, tx *sql.Tx, resetToken string, currentTime string) (model.UserPasswordReset, error)
+	Use(ctx context.Context, tx *sql.Tx, resetId int, usedAt string) bool
+	MarkUsedByUserId(ctx context.Context, tx *sql.Tx, userId int, usedAt string)
+	CountByUserId(ctx context.Context, tx *sql.Tx, userId int, since string) int
+}
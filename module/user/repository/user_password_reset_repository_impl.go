@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserPasswordResetRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewUserPasswordResetRepository(db *sql.DB) UserPasswordResetRepository {
+	return &UserPasswordResetRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *UserPasswordResetRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, passwordReset model.UserPasswordReset) model.UserPasswordReset {
+
+	SQL := `INSERT INTO password_reset
+			(
+				reset_user_id,
+				reset_token,
+				reset_expired_at,
+				reset_created_at
+			) VALUES (
+				?,
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		passwordReset.ResetUserId,
+		helper.HashToken(passwordReset.ResetToken),
+		passwordReset.ResetExpiredAt,
+		passwordReset.ResetCreatedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	passwordReset.ResetId = int(id)
+	return passwordReset
+}
+
+// FindByToken only returns a token that is neither used nor expired at currentTime
+func (repository *UserPasswordResetRepositoryImpl) FindByToken(ctx context.Context, tx *sql.Tx, resetToken string, currentTime string) (model.UserPasswordReset, error) {
+	SQL := `SELECT
+				reset_id,
+				reset_user_id,
+				reset_expired_at,
+				reset_created_at
+			FROM
+				password_reset
+			WHERE
+				reset_token = ?
+				AND reset_used_at IS NULL
+				AND reset_expired_at > ?`
+	rows, err := tx.QueryContext(ctx, SQL, helper.HashToken(resetToken), currentTime)
+	helper.IfError(err)
+	defer rows.Close()
+
+	passwordReset := model.UserPasswordReset{}
+	if rows.Next() {
+		err := rows.Scan(
+			&passwordReset.ResetId,
+			&passwordReset.ResetUserId,
+			&passwordReset.ResetExpiredAt,
+			&passwordReset.ResetCreatedAt)
+		helper.IfError(err)
+	}
+
+	return passwordReset, nil
+}
+
+// Use burns one token, false means a concurrent reset used it first
+func (repository *UserPasswordResetRepositoryImpl) Use(ctx context.Context, tx *sql.Tx, resetId int, usedAt string) bool {
+	SQL := `UPDATE
+				password_reset
+			SET
+				reset_used_at = ?
+			WHERE
+				reset_id = ?
+				AND reset_used_at IS NULL`
+	result, err := tx.ExecContext(ctx, SQL,
+		usedAt,
+		resetId)
+	helper.IfError(err)
+
+	affected, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return affected == 1
+}
+
+// MarkUsedByUserId burns every open token of the user, so only the latest emailed link is ever usable
+func (repository *UserPasswordResetRepositoryImpl) MarkUsedByUserId(ctx context.Context, tx *sql.Tx, userId int, usedAt string) {
+	SQL := `UPDATE
+				password_reset
+			SET
+				reset_used_at = ?
+			WHERE
+				reset_user_id = ?
+				AND reset_used_at IS NULL`
+	_, err := tx.ExecContext(ctx, SQL,
+		usedAt,
+		userId)
+	helper.IfError(err)
+}
+
+// CountByUserId counts the tokens sent to the user since that time, used or not
+func (repository *UserPasswordResetRepositoryImpl) CountByUserId(ctx context.Context, tx *sql.Tx, userId int, since string) int {
+	SQL := `SELECT
+				COUNT(*)
+			FROM
+				password_reset
+			WHERE
+				reset_user_id = ?
+				AND reset_created_at > ?`
+	rows, err := tx.QueryContext(ctx, SQL, userId, since)
+	helper.IfError(err)
+	defer rows.Close()
+
+	count := 0
+	if rows.Next() {
+		err := rows.Scan(&count)
+		helper.IfError(err)
+	}
+
+	return count
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"collapp/helper"
 	"collapp/module/user/model"
 	"context"
 	"database/sql"
@@ -9,12 +10,22 @@ import (
 type UserRepository interface {
 	Save(ctx context.Context, tx *sql.Tx, user model.UserCreateRequest) model.User
 	Update(ctx context.Context, tx *sql.Tx, user model.UserUpdateRequest) model.User
+	UpdateProfile(ctx context.Context, tx *sql.Tx, user model.UserProfileUpdateRequest) model.User
+	SaveRole(ctx context.Context, tx *sql.Tx, userId int, roleCode string) bool
 	Delete(ctx context.Context, tx *sql.Tx, user model.User)
 	SoftDelete(ctx context.Context, tx *sql.Tx, user model.User)
 	FindById(ctx context.Context, tx *sql.Tx, userId int) (model.User, error)
-	FindAll(ctx context.Context, tx *sql.Tx) []model.User
+	FindAll(ctx context.Context, tx *sql.Tx, query helper.ListQuery) ([]model.User, helper.Pagination)
+	Export(ctx context.Context, tx *sql.Tx, query helper.ListQuery, write func(user model.User) error) error
+	FindAllDeleted(ctx context.Context, tx *sql.Tx, query helper.ListQuery) ([]model.User, helper.Pagination)
+	FindDeletedById(ctx context.Context, tx *sql.Tx, userId int) (model.User, error)
+	FindDeletedBefore(ctx context.Context, tx *sql.Tx, deletedBefore string) []model.User
+	Restore(ctx context.Context, tx *sql.Tx, user model.User) bool
 	FindByEmail(ctx context.Context, tx *sql.Tx, userEmail string) (model.User, error)
-	FindByTokenRefresh(ctx context.Context, tx *sql.Tx, userTokenRefresh string) (model.User, error)
-	UpdateToken(ctx context.Context, tx *sql.Tx, user model.User) model.User
-	Logout(ctx context.Context, tx *sql.Tx, user model.User) model.User
+	UpdateLastLogin(ctx context.Context, tx *sql.Tx, user model.User) model.User
+	UpdatePassword(ctx context.Context, tx *sql.Tx, user model.User) model.User
+	UpdatePasswordHash(ctx context.Context, tx *sql.Tx, user model.User) model.User
+	UpdateStatus(ctx context.Context, tx *sql.Tx, user model.User) model.User
+	LiftSuspensions(ctx context.Context, tx *sql.Tx, currentTime string) int
+	DisableInactive(ctx context.Context, tx *sql.Tx, inactiveSince string, statusReason string, updatedAt string) int
 }
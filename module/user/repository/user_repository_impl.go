@@ -24,6 +24,9 @@ func (repository *UserRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, user
 				user_name, 
 				user_email, 
 				user_password, 
+				user_must_change_password, 
+				user_is_service_account, 
+				user_status, 
 				user_lang_code, 
 				user_photo,
 				created_by, 
@@ -35,12 +38,18 @@ func (repository *UserRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, user
 				?, 
 				?, 
 				?, 
+				?, 
+				?, 
+				?, 
 				?
 			)`
 	result, err := tx.ExecContext(ctx, SQL,
 		user.UserName,
 		user.UserEmail,
 		user.UserPassword,
+		user.UserMustChangePassword,
+		user.UserIsServiceAccount,
+		user.UserStatus,
 		user.UserLangCode,
 		user.UserPhotoName,
 		user.CreatedBy,
@@ -82,9 +91,96 @@ func (repository *UserRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, us
 	return res
 }
 
+func (repository *UserRepositoryImpl) UpdateProfile(ctx context.Context, tx *sql.Tx, user model.UserProfileUpdateRequest) model.User {
+	SQL := `UPDATE 
+				user 
+			SET 
+				user_name = ?, 
+				user_lang_code = ?, 
+				user_photo = ?,
+				updated_by = ?, 
+				updated_at = ? 
+			WHERE 
+				user_id = ?
+				AND deleted_at IS NULL`
+	_, err := tx.ExecContext(ctx, SQL,
+		user.UserName,
+		user.UserLangCode,
+		user.UserPhotoName,
+		user.UpdatedBy,
+		user.UpdatedAt,
+		user.UserId)
+	helper.IfError(err)
+
+	res := model.User{}
+	res.UserId = user.UserId
+	return res
+}
+
+// SaveRole gives the user the role of roleCode inside the transaction that creates the user
+func (repository *UserRepositoryImpl) SaveRole(ctx context.Context, tx *sql.Tx, userId int, roleCode string) bool {
+	SQL := `INSERT INTO user_role
+			(
+				userrole_user_id,
+				userrole_role_id
+			)
+			SELECT
+				?,
+				role_id
+			FROM
+				role
+			WHERE
+				role_code = ?`
+	result, err := tx.ExecContext(ctx, SQL,
+		userId,
+		roleCode)
+	helper.IfError(err)
+
+	total, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return total > 0
+}
+
+// userOwnedTables are the tables whose rows belong to one user, with the column holding the user id. Delete removes
+// them with the user so no session, key or identity keeps resolving to a user that is gone.
+var userOwnedTables = []struct {
+	table  string
+	column string
+}{
+	{"user_role", "userrole_user_id"},
+	{"user_session", "session_user_id"},
+	{"password_reset", "reset_user_id"},
+	{"user_totp", "totp_user_id"},
+	{"recovery_code", "recovery_user_id"},
+	{"api_key", "apikey_user_id"},
+	{"oidc_state", "state_user_id"},
+	{"user_identity", "identity_user_id"},
+	{"invitation", "invitation_user_id"},
+	{"password_history", "history_user_id"},
+	{"login_event", "event_user_id"},
+	{"magic_link", "link_user_id"},
+}
+
+// Delete removes the user and every row it owns in the same transaction, the impersonation records stay as the audit
+// trail of the actors
 func (repository *UserRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, user model.User) {
-	SQL := `DELETE FROM user WHERE user_id = ?`
-	_, err := tx.ExecContext(ctx, SQL, user.UserId)
+	SQL := `DELETE FROM 
+				login_attempt 
+			WHERE 
+				attempt_key_type = ? 
+				AND attempt_key = (SELECT LOWER(TRIM(user_email)) FROM user WHERE user_id = ?)`
+	_, err := tx.ExecContext(ctx, SQL, model.LoginAttemptKeyEmail, user.UserId)
+	helper.IfError(err)
+
+	for _, owned := range userOwnedTables {
+		SQL = `DELETE FROM ` + owned.table + ` WHERE ` + owned.column + ` = ?`
+		_, err = tx.ExecContext(ctx, SQL, user.UserId)
+		helper.IfError(err)
+	}
+
+	SQL = `DELETE FROM user WHERE user_id = ?`
+	_, err = tx.ExecContext(ctx, SQL, user.UserId)
 	helper.IfError(err)
 }
 
@@ -108,8 +204,12 @@ func (repository *UserRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx,
 				a.user_id, 
 				a.user_name, 
 				a.user_email, 
-				a.user_token, 
-				a.user_token_refresh, 
+				a.user_password, 
+				a.user_must_change_password, 
+				a.user_is_service_account, 
+				a.user_status, 
+				a.user_status_reason, 
+				a.user_suspended_until, 
 				a.user_lang_code, 
 				a.user_last_login, 
 				a.user_photo,
@@ -138,8 +238,12 @@ func (repository *UserRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx,
 			&user.UserId,
 			&user.UserName,
 			&user.UserEmail,
-			&user.UserTokenCheck,
-			&user.UserTokenRefreshCheck,
+			&user.UserPassword,
+			&user.UserMustChangePassword,
+			&user.UserIsServiceAccount,
+			&user.UserStatus,
+			&user.UserStatusReasonCheck,
+			&user.UserSuspendedUntilCheck,
 			&user.UserLangCode,
 			&user.UserLastLoginCheck,
 			&user.UserPhotoCheck,
@@ -152,11 +256,11 @@ func (repository *UserRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx,
 		helper.IfError(err)
 	}
 
-	if user.UserTokenCheck.Valid {
-		user.UserToken = user.UserTokenCheck.String
+	if user.UserStatusReasonCheck.Valid {
+		user.UserStatusReason = user.UserStatusReasonCheck.String
 	}
-	if user.UserTokenRefreshCheck.Valid {
-		user.UserTokenRefresh = user.UserTokenRefreshCheck.String
+	if user.UserSuspendedUntilCheck.Valid {
+		user.UserSuspendedUntil = user.UserSuspendedUntilCheck.String
 	}
 	if user.UserLastLoginCheck.Valid {
 		user.UserLastLogin = user.UserLastLoginCheck.String
@@ -186,13 +290,28 @@ func (repository *UserRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx,
 	return user, nil
 }
 
-func (repository *UserRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) []model.User {
+func (repository *UserRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx, query helper.ListQuery) ([]model.User, helper.Pagination) {
+	where, args := query.Where("a.deleted_at IS NULL")
 	SQL := `SELECT 
+				COUNT(*) 
+			FROM 
+				user a 
+			` + where
+	var total int
+	err := tx.QueryRowContext(ctx, SQL, args...).Scan(&total)
+	helper.IfError(err)
+
+	where, args = query.PageWhere("a.deleted_at IS NULL")
+	orderBy, orderArgs := query.OrderBy()
+	SQL = `SELECT 
 				a.user_id, 
 				a.user_name, 
 				a.user_email, 
-				a.user_token, 
-				a.user_token_refresh, 
+				a.user_must_change_password, 
+				a.user_is_service_account, 
+				a.user_status, 
+				a.user_status_reason, 
+				a.user_suspended_until, 
 				a.user_lang_code, 
 				a.user_last_login, 
 				a.user_photo, 
@@ -201,28 +320,39 @@ func (repository *UserRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) [
 				a.created_at, 
 				a.updated_by,
 				c.user_name,
-				a.updated_at
+				a.updated_at` + query.CursorColumns() + `
 			FROM 
 				user a
 			LEFT JOIN
 				user b ON b.user_id = a.created_by
 			LEFT JOIN
 				user c ON c.user_id = a.updated_by
-			WHERE
-				a.deleted_at IS NULL`
-	rows, err := tx.QueryContext(ctx, SQL)
+			` + where + `
+			` + orderBy
+	rows, err := tx.QueryContext(ctx, SQL, append(args, orderArgs...)...)
 	helper.IfError(err)
 	defer rows.Close()
 
 	var users []model.User
+	var lastKeys []interface{}
+	hasMore := false
 	for rows.Next() {
+		if len(users) == query.Limit {
+			hasMore = true
+			break
+		}
+
 		user := model.User{}
-		err := rows.Scan(
+		keys := query.CursorKeys()
+		err := rows.Scan(append([]interface{}{
 			&user.UserId,
 			&user.UserName,
 			&user.UserEmail,
-			&user.UserTokenCheck,
-			&user.UserTokenRefreshCheck,
+			&user.UserMustChangePassword,
+			&user.UserIsServiceAccount,
+			&user.UserStatus,
+			&user.UserStatusReasonCheck,
+			&user.UserSuspendedUntilCheck,
 			&user.UserLangCode,
 			&user.UserLastLoginCheck,
 			&user.UserPhotoCheck,
@@ -231,14 +361,14 @@ func (repository *UserRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) [
 			&user.CreatedAtCheck,
 			&user.UpdatedByCheck,
 			&user.UpdatedByNameCheck,
-			&user.UpdatedAtCheck)
+			&user.UpdatedAtCheck}, keys...)...)
 		helper.IfError(err)
 
-		if user.UserTokenCheck.Valid {
-			user.UserToken = user.UserTokenCheck.String
+		if user.UserStatusReasonCheck.Valid {
+			user.UserStatusReason = user.UserStatusReasonCheck.String
 		}
-		if user.UserTokenRefreshCheck.Valid {
-			user.UserTokenRefresh = user.UserTokenRefreshCheck.String
+		if user.UserSuspendedUntilCheck.Valid {
+			user.UserSuspendedUntil = user.UserSuspendedUntilCheck.String
 		}
 		if user.UserLastLoginCheck.Valid {
 			user.UserLastLogin = user.UserLastLoginCheck.String
@@ -266,23 +396,179 @@ func (repository *UserRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) [
 		}
 
 		users = append(users, user)
+		lastKeys = keys
 	}
 
-	return users
+	return users, query.Pagination(total, hasMore, lastKeys)
 }
 
-func (repository *UserRepositoryImpl) FindByEmail(ctx context.Context, tx *sql.Tx, userEmail string) (model.User, error) {
+// Export passes the users of the query to write one by one as they are read, it stops at the first error of write
+func (repository *UserRepositoryImpl) Export(ctx context.Context, tx *sql.Tx, query helper.ListQuery, write func(user model.User) error) error {
+	where, args := query.Where("deleted_at IS NULL")
 	SQL := `SELECT 
 				user_id, 
 				user_name, 
-				user_password, 
-				user_lang_code 
+				user_email, 
+				user_is_service_account, 
+				user_status, 
+				user_status_reason, 
+				user_suspended_until, 
+				user_lang_code, 
+				user_last_login, 
+				user_photo, 
+				created_by,
+				created_at, 
+				updated_by,
+				updated_at
+			FROM 
+				user a
+			` + where + `
+			` + query.Sort()
+	rows, err := tx.QueryContext(ctx, SQL, args...)
+	helper.IfError(err)
+	defer rows.Close()
+
+	for rows.Next() {
+		user := model.User{}
+		err := rows.Scan(
+			&user.UserId,
+			&user.UserName,
+			&user.UserEmail,
+			&user.UserIsServiceAccount,
+			&user.UserStatus,
+			&user.UserStatusReasonCheck,
+			&user.UserSuspendedUntilCheck,
+			&user.UserLangCode,
+			&user.UserLastLoginCheck,
+			&user.UserPhotoCheck,
+			&user.CreatedByCheck,
+			&user.CreatedAtCheck,
+			&user.UpdatedByCheck,
+			&user.UpdatedAtCheck)
+		helper.IfError(err)
+
+		if user.UserStatusReasonCheck.Valid {
+			user.UserStatusReason = user.UserStatusReasonCheck.String
+		}
+		if user.UserSuspendedUntilCheck.Valid {
+			user.UserSuspendedUntil = user.UserSuspendedUntilCheck.String
+		}
+		if user.UserLastLoginCheck.Valid {
+			user.UserLastLogin = user.UserLastLoginCheck.String
+		}
+		if user.UserPhotoCheck.Valid {
+			user.UserPhoto = user.UserPhotoCheck.String
+		}
+		if user.CreatedByCheck.Valid {
+			user.CreatedBy = int(user.CreatedByCheck.Int32)
+		}
+		if user.CreatedAtCheck.Valid {
+			user.CreatedAt = user.CreatedAtCheck.String
+		}
+		if user.UpdatedByCheck.Valid {
+			user.UpdatedBy = int(user.UpdatedByCheck.Int32)
+		}
+		if user.UpdatedAtCheck.Valid {
+			user.UpdatedAt = user.UpdatedAtCheck.String
+		}
+
+		err = write(user)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// FindAllDeleted lists the users in the trash, soft deleted and not purged yet
+func (repository *UserRepositoryImpl) FindAllDeleted(ctx context.Context, tx *sql.Tx, query helper.ListQuery) ([]model.User, helper.Pagination) {
+	where, args := query.Where("a.deleted_at IS NOT NULL")
+	SQL := `SELECT 
+				COUNT(*) 
+			FROM 
+				user a 
+			` + where
+	var total int
+	err := tx.QueryRowContext(ctx, SQL, args...).Scan(&total)
+	helper.IfError(err)
+
+	where, args = query.PageWhere("a.deleted_at IS NOT NULL")
+	orderBy, orderArgs := query.OrderBy()
+	SQL = `SELECT 
+				a.user_id, 
+				a.user_name, 
+				a.user_email, 
+				a.user_is_service_account, 
+				a.user_status, 
+				a.user_lang_code, 
+				a.user_photo, 
+				a.deleted_by,
+				b.user_name,
+				a.deleted_at` + query.CursorColumns() + `
+			FROM 
+				user a
+			LEFT JOIN
+				user b ON b.user_id = a.deleted_by
+			` + where + `
+			` + orderBy
+	rows, err := tx.QueryContext(ctx, SQL, append(args, orderArgs...)...)
+	helper.IfError(err)
+	defer rows.Close()
+
+	var users []model.User
+	var lastKeys []interface{}
+	hasMore := false
+	for rows.Next() {
+		if len(users) == query.Limit {
+			hasMore = true
+			break
+		}
+
+		user := model.User{}
+		keys := query.CursorKeys()
+		err := rows.Scan(append([]interface{}{
+			&user.UserId,
+			&user.UserName,
+			&user.UserEmail,
+			&user.UserIsServiceAccount,
+			&user.UserStatus,
+			&user.UserLangCode,
+			&user.UserPhotoCheck,
+			&user.DeletedByCheck,
+			&user.DeletedByNameCheck,
+			&user.DeletedAt}, keys...)...)
+		helper.IfError(err)
+
+		if user.UserPhotoCheck.Valid {
+			user.UserPhoto = user.UserPhotoCheck.String
+		}
+		if user.DeletedByCheck.Valid {
+			user.DeletedBy = int(user.DeletedByCheck.Int32)
+		}
+		if user.DeletedByNameCheck.Valid {
+			user.DeletedByName = user.DeletedByNameCheck.String
+		}
+
+		users = append(users, user)
+		lastKeys = keys
+	}
+
+	return users, query.Pagination(total, hasMore, lastKeys)
+}
+
+func (repository *UserRepositoryImpl) FindDeletedById(ctx context.Context, tx *sql.Tx, userId int) (model.User, error) {
+	SQL := `SELECT 
+				user_id, 
+				user_email, 
+				user_is_service_account, 
+				deleted_at 
 			FROM 
 				user 
 			WHERE 
-				user_email = ?
-				AND deleted_at IS NULL`
-	rows, err := tx.QueryContext(ctx, SQL, userEmail)
+				user_id = ?
+				AND deleted_at IS NOT NULL`
+	rows, err := tx.QueryContext(ctx, SQL, userId)
 	helper.IfError(err)
 	defer rows.Close()
 
@@ -290,27 +576,87 @@ func (repository *UserRepositoryImpl) FindByEmail(ctx context.Context, tx *sql.T
 	if rows.Next() {
 		err := rows.Scan(
 			&user.UserId,
-			&user.UserName,
-			&user.UserPassword,
-			&user.UserLangCode)
+			&user.UserEmail,
+			&user.UserIsServiceAccount,
+			&user.DeletedAt)
 		helper.IfError(err)
 	}
 
 	return user, nil
 }
 
-func (repository *UserRepositoryImpl) FindByTokenRefresh(ctx context.Context, tx *sql.Tx, userTokenRefresh string) (model.User, error) {
+// FindDeletedBefore finds the users soft deleted before the retention date, with the photo to remove with them
+func (repository *UserRepositoryImpl) FindDeletedBefore(ctx context.Context, tx *sql.Tx, deletedBefore string) []model.User {
+	SQL := `SELECT 
+				user_id, 
+				user_photo 
+			FROM 
+				user 
+			WHERE 
+				deleted_at IS NOT NULL
+				AND deleted_at < ?`
+	rows, err := tx.QueryContext(ctx, SQL, deletedBefore)
+	helper.IfError(err)
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		user := model.User{}
+		err := rows.Scan(
+			&user.UserId,
+			&user.UserPhotoCheck)
+		helper.IfError(err)
+
+		if user.UserPhotoCheck.Valid {
+			user.UserPhoto = user.UserPhotoCheck.String
+		}
+
+		users = append(users, user)
+	}
+
+	return users
+}
+
+// Restore takes the user out of the trash, it tells false when the user was not deleted
+func (repository *UserRepositoryImpl) Restore(ctx context.Context, tx *sql.Tx, user model.User) bool {
+	SQL := `UPDATE 
+				user 
+			SET 
+				deleted_by = NULL, 
+				deleted_at = NULL, 
+				updated_by = ?, 
+				updated_at = ? 
+			WHERE 
+				user_id = ?
+				AND deleted_at IS NOT NULL`
+	result, err := tx.ExecContext(ctx, SQL,
+		user.UpdatedBy,
+		user.UpdatedAt,
+		user.UserId)
+	helper.IfError(err)
+
+	total, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return total == 1
+}
+
+// FindByEmail only finds users that sign in with a password, service accounts authenticate with API keys
+func (repository *UserRepositoryImpl) FindByEmail(ctx context.Context, tx *sql.Tx, userEmail string) (model.User, error) {
 	SQL := `SELECT 
 				user_id, 
 				user_name, 
 				user_password, 
-				user_lang_code 
+				user_lang_code, 
+				user_status, 
+				user_suspended_until 
 			FROM 
 				user 
 			WHERE 
-				user_token_refresh = ?
+				user_email = ?
+				AND user_is_service_account = 0
 				AND deleted_at IS NULL`
-	rows, err := tx.QueryContext(ctx, SQL, userTokenRefresh)
+	rows, err := tx.QueryContext(ctx, SQL, userEmail)
 	helper.IfError(err)
 	defer rows.Close()
 
@@ -320,26 +666,28 @@ func (repository *UserRepositoryImpl) FindByTokenRefresh(ctx context.Context, tx
 			&user.UserId,
 			&user.UserName,
 			&user.UserPassword,
-			&user.UserLangCode)
+			&user.UserLangCode,
+			&user.UserStatus,
+			&user.UserSuspendedUntilCheck)
 		helper.IfError(err)
 	}
 
+	if user.UserSuspendedUntilCheck.Valid {
+		user.UserSuspendedUntil = user.UserSuspendedUntilCheck.String
+	}
+
 	return user, nil
 }
 
-func (repository *UserRepositoryImpl) UpdateToken(ctx context.Context, tx *sql.Tx, user model.User) model.User {
+func (repository *UserRepositoryImpl) UpdateLastLogin(ctx context.Context, tx *sql.Tx, user model.User) model.User {
 	SQL := `UPDATE 
 				user 
 			SET 
-				user_token = ?, 
-				user_token_refresh = ?, 
 				user_last_login = ? 
 			WHERE 
 				user_id = ?
 				AND deleted_at IS NULL`
 	_, err := tx.ExecContext(ctx, SQL,
-		user.UserToken,
-		user.UserTokenRefresh,
 		user.UserLastLogin,
 		user.UserId)
 	helper.IfError(err)
@@ -347,17 +695,125 @@ func (repository *UserRepositoryImpl) UpdateToken(ctx context.Context, tx *sql.T
 	return user
 }
 
-func (repository *UserRepositoryImpl) Logout(ctx context.Context, tx *sql.Tx, user model.User) model.User {
+func (repository *UserRepositoryImpl) UpdatePassword(ctx context.Context, tx *sql.Tx, user model.User) model.User {
+	SQL := `UPDATE 
+				user 
+			SET 
+				user_password = ?, 
+				user_must_change_password = ?, 
+				updated_by = ?, 
+				updated_at = ? 
+			WHERE 
+				user_id = ?
+				AND deleted_at IS NULL`
+	_, err := tx.ExecContext(ctx, SQL,
+		user.UserPassword,
+		user.UserMustChangePassword,
+		user.UpdatedBy,
+		user.UpdatedAt,
+		user.UserId)
+	helper.IfError(err)
+
+	return user
+}
+
+// UpdatePasswordHash only swaps the hash of an unchanged password, e.g. after the hashing parameters were raised
+func (repository *UserRepositoryImpl) UpdatePasswordHash(ctx context.Context, tx *sql.Tx, user model.User) model.User {
+	SQL := `UPDATE 
+				user 
+			SET 
+				user_password = ? 
+			WHERE 
+				user_id = ?
+				AND deleted_at IS NULL`
+	_, err := tx.ExecContext(ctx, SQL,
+		user.UserPassword,
+		user.UserId)
+	helper.IfError(err)
+
+	return user
+}
+
+// UpdateStatus sets the status with its reason and the end of a suspension, empty ones are stored as NULL
+func (repository *UserRepositoryImpl) UpdateStatus(ctx context.Context, tx *sql.Tx, user model.User) model.User {
+	var statusReason, suspendedUntil interface{}
+	if user.UserStatusReason != "" {
+		statusReason = user.UserStatusReason
+	}
+	if user.UserSuspendedUntil != "" {
+		suspendedUntil = user.UserSuspendedUntil
+	}
+
 	SQL := `UPDATE 
 				user 
 			SET 
-				user_token = NULL, 
-				user_token_refresh = NULL 
+				user_status = ?, 
+				user_status_reason = ?, 
+				user_suspended_until = ?, 
+				updated_by = ?, 
+				updated_at = ? 
 			WHERE 
 				user_id = ?
 				AND deleted_at IS NULL`
-	_, err := tx.ExecContext(ctx, SQL, user.UserId)
+	_, err := tx.ExecContext(ctx, SQL,
+		user.UserStatus,
+		statusReason,
+		suspendedUntil,
+		user.UpdatedBy,
+		user.UpdatedAt,
+		user.UserId)
 	helper.IfError(err)
 
 	return user
 }
+
+// LiftSuspensions reactivates every suspended user whose suspension ended before currentTime
+func (repository *UserRepositoryImpl) LiftSuspensions(ctx context.Context, tx *sql.Tx, currentTime string) int {
+	SQL := `UPDATE 
+				user 
+			SET 
+				user_status = 'active', 
+				user_status_reason = NULL, 
+				user_suspended_until = NULL, 
+				updated_at = ? 
+			WHERE 
+				user_status = 'suspended'
+				AND user_suspended_until <= ?
+				AND deleted_at IS NULL`
+	result, err := tx.ExecContext(ctx, SQL,
+		currentTime,
+		currentTime)
+	helper.IfError(err)
+
+	affected, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return int(affected)
+}
+
+// DisableInactive disables the active users that have not signed in since inactiveSince, a user that never signed
+// in counts from its creation. Service accounts do not sign in and are left alone.
+func (repository *UserRepositoryImpl) DisableInactive(ctx context.Context, tx *sql.Tx, inactiveSince string, statusReason string, updatedAt string) int {
+	SQL := `UPDATE 
+				user 
+			SET 
+				user_status = 'disabled', 
+				user_status_reason = ?, 
+				user_suspended_until = NULL, 
+				updated_at = ? 
+			WHERE 
+				user_status = 'active'
+				AND user_is_service_account = 0
+				AND COALESCE(user_last_login, created_at) < ?
+				AND deleted_at IS NULL`
+	result, err := tx.ExecContext(ctx, SQL,
+		statusReason,
+		updatedAt,
+		inactiveSince)
+	helper.IfError(err)
+
+	affected, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return int(affected)
+}
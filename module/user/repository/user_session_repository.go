@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserSessionRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, session model.UserSessionCreateRequest) model.UserSession
+	UpdateToken(ctx context.Context, tx *sql.Tx, session model.UserSession) model.UserSession
+	RotateToken(ctx context.Context, tx *sql.Tx, session model.UserSession, tokenRefreshUsed string) bool
+	UpdateLastSeen(ctx context.Context, tx *sql.Tx, session model.UserSession)
+	Revoke(ctx context.Context, tx *sql.Tx, session model.UserSession)
+	RevokeByUserId(ctx context.Context, tx *sql.Tx, userId int, exceptSessionId int, revokedAt string)
+	FindById(ctx context.Context, tx *sql.Tx, sessionId int) (model.UserSession, error)
+	FindByUserId(ctx context.Context, tx *sql.Tx, userId int) []model.UserSession
+}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserSessionRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewUserSessionRepository(db *sql.DB) UserSessionRepository {
+	return &UserSessionRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *UserSessionRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, session model.UserSessionCreateRequest) model.UserSession {
+
+	SQL := `INSERT INTO user_session
+			(
+				session_user_id,
+				session_user_agent,
+				session_ip,
+				session_created_at,
+				session_last_seen_at
+			) VALUES (
+				?,
+				?,
+				?,
+				?,
+				?
+			)`
+	result, err := tx.ExecContext(ctx, SQL,
+		session.SessionUserId,
+		session.SessionUserAgent,
+		session.SessionIp,
+		session.SessionCreatedAt,
+		session.SessionCreatedAt)
+	helper.IfError(err)
+
+	id, err := result.LastInsertId()
+	helper.IfError(err)
+
+	res := model.UserSession{}
+	res.SessionId = int(id)
+	return res
+}
+
+// UpdateToken stores the hashes of the token pair, a session without refresh token (impersonation) stores NULL so no
+// refresh token can ever match it
+func (repository *UserSessionRepositoryImpl) UpdateToken(ctx context.Context, tx *sql.Tx, session model.UserSession) model.UserSession {
+	SQL := `UPDATE
+				user_session
+			SET
+				session_token = ?,
+				session_token_refresh = ?,
+				session_last_seen_at = ?
+			WHERE
+				session_id = ?
+				AND session_revoked_at IS NULL`
+	_, err := tx.ExecContext(ctx, SQL,
+		helper.HashToken(session.SessionToken),
+		hashTokenOrNull(session.SessionTokenRefresh),
+		session.SessionLastSeenAt,
+		session.SessionId)
+	helper.IfError(err)
+
+	return session
+}
+
+// RotateToken replaces the token pair only while tokenRefreshUsed is still the current refresh token of the session, so
+// of two refreshes with the same token only one wins
+func (repository *UserSessionRepositoryImpl) RotateToken(ctx context.Context, tx *sql.Tx, session model.UserSession, tokenRefreshUsed string) bool {
+	SQL := `UPDATE
+				user_session
+			SET
+				session_token = ?,
+				session_token_refresh = ?,
+				session_last_seen_at = ?
+			WHERE
+				session_id = ?
+				AND session_token_refresh = ?
+				AND session_revoked_at IS NULL`
+	result, err := tx.ExecContext(ctx, SQL,
+		helper.HashToken(session.SessionToken),
+		helper.HashToken(session.SessionTokenRefresh),
+		session.SessionLastSeenAt,
+		session.SessionId,
+		helper.HashToken(tokenRefreshUsed))
+	helper.IfError(err)
+
+	affected, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return affected == 1
+}
+
+func (repository *UserSessionRepositoryImpl) UpdateLastSeen(ctx context.Context, tx *sql.Tx, session model.UserSession) {
+	SQL := `UPDATE
+				user_session
+			SET
+				session_last_seen_at = ?
+			WHERE
+				session_id = ?`
+	_, err := tx.ExecContext(ctx, SQL,
+		session.SessionLastSeenAt,
+		session.SessionId)
+	helper.IfError(err)
+}
+
+func (repository *UserSessionRepositoryImpl) Revoke(ctx context.Context, tx *sql.Tx, session model.UserSession) {
+	SQL := `UPDATE
+				user_session
+			SET
+				session_token = NULL,
+				session_token_refresh = NULL,
+				session_revoked_at = ?
+			WHERE
+				session_id = ?
+				AND session_revoked_at IS NULL`
+	_, err := tx.ExecContext(ctx, SQL,
+		session.SessionRevokedAt,
+		session.SessionId)
+	helper.IfError(err)
+}
+
+func (repository *UserSessionRepositoryImpl) RevokeByUserId(ctx context.Context, tx *sql.Tx, userId int, exceptSessionId int, revokedAt string) {
+	SQL := `UPDATE
+				user_session
+			SET
+				session_token = NULL,
+				session_token_refresh = NULL,
+				session_revoked_at = ?
+			WHERE
+				session_user_id = ?
+				AND session_id <> ?
+				AND session_revoked_at IS NULL`
+	_, err := tx.ExecContext(ctx, SQL,
+		revokedAt,
+		userId,
+		exceptSessionId)
+	helper.IfError(err)
+}
+
+func (repository *UserSessionRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, sessionId int) (model.UserSession, error) {
+	SQL := `SELECT
+				session_id,
+				session_user_id,
+				session_user_agent,
+				session_ip,
+				session_token,
+				session_token_refresh,
+				session_created_at,
+				session_last_seen_at
+			FROM
+				user_session
+			WHERE
+				session_id = ?
+				AND session_revoked_at IS NULL`
+	rows, err := tx.QueryContext(ctx, SQL, sessionId)
+	helper.IfError(err)
+	defer rows.Close()
+
+	session := model.UserSession{}
+	if rows.Next() {
+		err := rows.Scan(
+			&session.SessionId,
+			&session.SessionUserId,
+			&session.SessionUserAgentCheck,
+			&session.SessionIpCheck,
+			&session.SessionTokenCheck,
+			&session.SessionTokenRefreshCheck,
+			&session.SessionCreatedAt,
+			&session.SessionLastSeenAtCheck)
+		helper.IfError(err)
+	}
+
+	if session.SessionUserAgentCheck.Valid {
+		session.SessionUserAgent = session.SessionUserAgentCheck.String
+	}
+	if session.SessionIpCheck.Valid {
+		session.SessionIp = session.SessionIpCheck.String
+	}
+	if session.SessionTokenCheck.Valid {
+		session.SessionToken = session.SessionTokenCheck.String
+	}
+	if session.SessionTokenRefreshCheck.Valid {
+		session.SessionTokenRefresh = session.SessionTokenRefreshCheck.String
+	}
+	if session.SessionLastSeenAtCheck.Valid {
+		session.SessionLastSeenAt = session.SessionLastSeenAtCheck.String
+	}
+
+	return session, nil
+}
+
+func (repository *UserSessionRepositoryImpl) FindByUserId(ctx context.Context, tx *sql.Tx, userId int) []model.UserSession {
+	SQL := `SELECT
+				session_id,
+				session_user_id,
+				session_user_agent,
+				session_ip,
+				session_created_at,
+				session_last_seen_at
+			FROM
+				user_session
+			WHERE
+				session_user_id = ?
+				AND session_revoked_at IS NULL
+			ORDER BY
+				session_last_seen_at DESC`
+	rows, err := tx.QueryContext(ctx, SQL, userId)
+	helper.IfError(err)
+	defer rows.Close()
+
+	var sessions []model.UserSession
+	for rows.Next() {
+		session := model.UserSession{}
+		err := rows.Scan(
+			&session.SessionId,
+			&session.SessionUserId,
+			&session.SessionUserAgentCheck,
+			&session.SessionIpCheck,
+			&session.SessionCreatedAt,
+			&session.SessionLastSeenAtCheck)
+		helper.IfError(err)
+
+		if session.SessionUserAgentCheck.Valid {
+			session.SessionUserAgent = session.SessionUserAgentCheck.String
+		}
+		if session.SessionIpCheck.Valid {
+			session.SessionIp = session.SessionIpCheck.String
+		}
+		if session.SessionLastSeenAtCheck.Valid {
+			session.SessionLastSeenAt = session.SessionLastSeenAtCheck.String
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions
+}
+
+// hashTokenOrNull hashes a token for storage, an empty token is stored as NULL rather than as the hash of nothing
+func hashTokenOrNull(token string) sql.NullString {
+	if token == "" {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: helper.HashToken(token), Valid: true}
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserTotpRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, totp model.UserTotp) model.UserTotp
+	Confirm(ctx context.Context, tx *sql.Tx, totp model.UserTotp)
+	UseStep(ctx context.Context, tx *sql.Tx, userId int, step int64) bool
+	Delete(ctx context.Context, tx *sql.Tx, userId int)
+	FindByUserId(ctx context.Context, tx *sql.Tx, userId int) (model.UserTotp, error)
+	SaveRecoveryCode(ctx context.Context, tx *sql.Tx, recoveryCode model.UserRecoveryCode)
+	DeleteRecoveryCode(ctx context.Context, tx *sql.Tx, userId int)
+	UseRecoveryCode(ctx context.Context, tx *sql.Tx, recoveryCode model.UserRecoveryCode) bool
+}
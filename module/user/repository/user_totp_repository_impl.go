@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"collapp/helper"
+	"collapp/module/user/model"
+	"context"
+	"database/sql"
+)
+
+type UserTotpRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewUserTotpRepository(db *sql.DB) UserTotpRepository {
+	return &UserTotpRepositoryImpl{
+		DB: db,
+	}
+}
+
+// Save stores a new unconfirmed secret, enrolling again replaces a previous secret that was never confirmed
+func (repository *UserTotpRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, totp model.UserTotp) model.UserTotp {
+	SQL := `INSERT INTO user_totp
+			(
+				totp_user_id,
+				totp_secret,
+				totp_last_step,
+				totp_created_at
+			) VALUES (
+				?,
+				?,
+				0,
+				?
+			) ON DUPLICATE KEY UPDATE
+				totp_secret = VALUES(totp_secret),
+				totp_last_step = 0,
+				totp_confirmed_at = NULL,
+				totp_created_at = VALUES(totp_created_at)`
+	_, err := tx.ExecContext(ctx, SQL,
+		totp.TotpUserId,
+		totp.TotpSecret,
+		totp.TotpCreatedAt)
+	helper.IfError(err)
+
+	return totp
+}
+
+func (repository *UserTotpRepositoryImpl) Confirm(ctx context.Context, tx *sql.Tx, totp model.UserTotp) {
+	SQL := `UPDATE
+				user_totp
+			SET
+				totp_confirmed_at = ?
+			WHERE
+				totp_user_id = ?`
+	_, err := tx.ExecContext(ctx, SQL,
+		totp.TotpConfirmedAt,
+		totp.TotpUserId)
+	helper.IfError(err)
+}
+
+// UseStep records the time step of an accepted code, it returns false when that step or a later one was already used
+// so every code works only once
+func (repository *UserTotpRepositoryImpl) UseStep(ctx context.Context, tx *sql.Tx, userId int, step int64) bool {
+	SQL := `UPDATE
+				user_totp
+			SET
+				totp_last_step = ?
+			WHERE
+				totp_user_id = ?
+				AND totp_last_step < ?`
+	result, err := tx.ExecContext(ctx, SQL,
+		step,
+		userId,
+		step)
+	helper.IfError(err)
+
+	affected, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return affected > 0
+}
+
+func (repository *UserTotpRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, userId int) {
+	SQL := `DELETE FROM user_totp WHERE totp_user_id = ?`
+	_, err := tx.ExecContext(ctx, SQL, userId)
+	helper.IfError(err)
+}
+
+func (repository *UserTotpRepositoryImpl) FindByUserId(ctx context.Context, tx *sql.Tx, userId int) (model.UserTotp, error) {
+	SQL := `SELECT
+				totp_user_id,
+				totp_secret,
+				totp_last_step,
+				totp_confirmed_at,
+				totp_created_at
+			FROM
+				user_totp
+			WHERE
+				totp_user_id = ?`
+	rows, err := tx.QueryContext(ctx, SQL, userId)
+	helper.IfError(err)
+	defer rows.Close()
+
+	totp := model.UserTotp{}
+	if rows.Next() {
+		err := rows.Scan(
+			&totp.TotpUserId,
+			&totp.TotpSecret,
+			&totp.TotpLastStep,
+			&totp.TotpConfirmedAtCheck,
+			&totp.TotpCreatedAt)
+		helper.IfError(err)
+	}
+
+	if totp.TotpConfirmedAtCheck.Valid {
+		totp.TotpConfirmedAt = totp.TotpConfirmedAtCheck.String
+	}
+
+	return totp, nil
+}
+
+func (repository *UserTotpRepositoryImpl) SaveRecoveryCode(ctx context.Context, tx *sql.Tx, recoveryCode model.UserRecoveryCode) {
+	SQL := `INSERT INTO recovery_code
+			(
+				recovery_user_id,
+				recovery_code,
+				recovery_created_at
+			) VALUES (
+				?,
+				?,
+				?
+			)`
+	_, err := tx.ExecContext(ctx, SQL,
+		recoveryCode.RecoveryUserId,
+		helper.HashToken(recoveryCode.RecoveryCode),
+		recoveryCode.RecoveryCreatedAt)
+	helper.IfError(err)
+}
+
+func (repository *UserTotpRepositoryImpl) DeleteRecoveryCode(ctx context.Context, tx *sql.Tx, userId int) {
+	SQL := `DELETE FROM recovery_code WHERE recovery_user_id = ?`
+	_, err := tx.ExecContext(ctx, SQL, userId)
+	helper.IfError(err)
+}
+
+// UseRecoveryCode burns an unused recovery code of the user, it returns false when there is no such code
+func (repository *UserTotpRepositoryImpl) UseRecoveryCode(ctx context.Context, tx *sql.Tx, recoveryCode model.UserRecoveryCode) bool {
+	SQL := `UPDATE
+				recovery_code
+			SET
+				recovery_used_at = ?
+			WHERE
+				recovery_user_id = ?
+				AND recovery_code = ?
+				AND recovery_used_at IS NULL`
+	result, err := tx.ExecContext(ctx, SQL,
+		recoveryCode.RecoveryUsedAt,
+		recoveryCode.RecoveryUserId,
+		helper.HashToken(recoveryCode.RecoveryCode))
+	helper.IfError(err)
+
+	affected, err := result.RowsAffected()
+	helper.IfError(err)
+
+	return affected > 0
+}
@@ -1,19 +1,73 @@
 package service
 
 import (
+	"collapp/helper"
 	"collapp/module/user/model"
 	"context"
 )
 
 type UserService interface {
 	Create(ctx context.Context, request model.UserCreateRequest) model.UserResponse
+	Import(ctx context.Context, requests []model.UserImportRequest) []model.UserResponse
 	Update(ctx context.Context, request model.UserUpdateRequest) (model.UserResponse, string)
+	UpdateProfile(ctx context.Context, request model.UserProfileUpdateRequest) (model.UserResponse, string)
 	Delete(ctx context.Context, userId int) model.UserResponse
 	SoftDelete(ctx context.Context, request model.UserDeleteRequest) model.UserResponse
 	FindById(ctx context.Context, userId int) model.UserResponse
-	FindAll(ctx context.Context) []model.UserResponse
+	FindAll(ctx context.Context, query helper.ListQuery) ([]model.UserResponse, helper.Pagination)
+	Export(ctx context.Context, query helper.ListQuery, write func(user model.UserExportResponse) error) error
+	FindAllDeleted(ctx context.Context, query helper.ListQuery) ([]model.UserResponse, helper.Pagination)
+	FindDeletedById(ctx context.Context, userId int) model.UserResponse
+	Restore(ctx context.Context, request model.UserRestoreRequest) model.UserResponse
+	PurgeDeleted(ctx context.Context, deletedBefore string) []model.UserResponse
 	FindByEmail(ctx context.Context, userEmail string) model.UserLoginResponse
-	FindByTokenRefresh(ctx context.Context, userTokenRefresh string) model.UserLoginResponse
+	FindPasswordById(ctx context.Context, userId int) model.UserLoginResponse
+	UpdatePassword(ctx context.Context, request model.UserPasswordUpdateRequest) model.UserResponse
+	UpdatePasswordHash(ctx context.Context, userId int, userPassword string)
+	FindPasswordHistory(ctx context.Context, userId int, limit int) []string
+	ResetPassword(ctx context.Context, request model.UserPasswordResetRequest) model.UserResponse
+	FindLoginAttempt(ctx context.Context, request model.UserLoginAttemptRequest) model.UserLoginAttemptResponse
+	RecordLoginFailure(ctx context.Context, request model.UserLoginAttemptRequest) model.UserLoginAttemptResponse
+	ResetLoginAttempt(ctx context.Context, request model.UserLoginAttemptRequest)
+	UnlockLogin(ctx context.Context, userId int) model.UserResponse
+	RecordLoginEvent(ctx context.Context, request model.UserLoginEventCreateRequest) model.UserLoginEventResponse
+	FindLoginEventByUserId(ctx context.Context, userId int, query helper.ListQuery) ([]model.UserLoginEventResponse, helper.Pagination)
+	UpdateStatus(ctx context.Context, request model.UserStatusUpdateRequest) model.UserResponse
+	LiftSuspensions(ctx context.Context, currentTime string) int
+	DisableInactive(ctx context.Context, inactiveSince string, statusReason string, updatedAt string) int
+	CreatePasswordReset(ctx context.Context, request model.UserPasswordForgotRequest) model.UserResponse
+	FindPasswordResetUser(ctx context.Context, resetToken string, currentTime string) model.UserResponse
+	ResetPasswordByToken(ctx context.Context, request model.UserPasswordResetTokenRequest) model.UserResponse
+	UseTokenRefresh(ctx context.Context, request model.UserRefreshTokenRequest) (model.UserLoginResponse, bool)
 	UpdateToken(ctx context.Context, request model.UserUpdateTokenRequest) model.UserResponse
-	Logout(ctx context.Context, userId int) model.UserResponse
+	RotateToken(ctx context.Context, request model.UserUpdateTokenRequest) (model.UserResponse, bool)
+	Logout(ctx context.Context, request model.UserSessionRevokeRequest) model.UserResponse
+	CreateSession(ctx context.Context, request model.UserSessionCreateRequest) model.UserSessionResponse
+	ValidateSession(ctx context.Context, sessionId int, userId int, token string, lastSeenAt string) model.UserResponse
+	IntrospectSession(ctx context.Context, sessionId int, userId int, token string, isRefresh bool) model.UserResponse
+	FindSessionByUserId(ctx context.Context, userId int) []model.UserSessionResponse
+	RevokeSession(ctx context.Context, request model.UserSessionRevokeRequest) model.UserSessionResponse
+	FindTotpByUserId(ctx context.Context, userId int) model.UserTotpResponse
+	EnrollTotp(ctx context.Context, request model.UserTotpEnrollRequest) model.UserTotpResponse
+	ConfirmTotp(ctx context.Context, request model.UserTotpConfirmRequest) model.UserTotpResponse
+	VerifyTotp(ctx context.Context, request model.UserTotpVerifyRequest) bool
+	DisableTotp(ctx context.Context, userId int) model.UserTotpResponse
+	CreateServiceAccount(ctx context.Context, request model.UserServiceAccountCreateRequest) model.UserResponse
+	CreateApiKey(ctx context.Context, request model.UserApiKeyCreateRequest) model.UserApiKeyResponse
+	FindApiKeyByUserId(ctx context.Context, userId int) []model.UserApiKeyResponse
+	RevokeApiKey(ctx context.Context, request model.UserApiKeyRevokeRequest) model.UserApiKeyResponse
+	ValidateApiKey(ctx context.Context, apiKey string, usedAt string) model.UserApiKeyResponse
+	CreateOidcState(ctx context.Context, request model.UserOidcState) model.UserOidcState
+	UseOidcState(ctx context.Context, stateKey string, provider string, currentTime string) model.UserOidcState
+	LoginOidc(ctx context.Context, request model.UserOidcLoginRequest) model.UserResponse
+	LinkOidc(ctx context.Context, request model.UserOidcLinkRequest) model.UserResponse
+	CreateInvitation(ctx context.Context, request model.UserInvitationCreateRequest) model.UserResponse
+	RevokeInvitation(ctx context.Context, request model.UserInvitationRevokeRequest) model.UserResponse
+	AcceptInvitation(ctx context.Context, request model.UserInvitationAcceptRequest) model.UserResponse
+	CreateMagicLink(ctx context.Context, request model.UserMagicLinkCreateRequest) bool
+	UseMagicLink(ctx context.Context, request model.UserMagicLinkConsumeRequest) bool
+	StartImpersonation(ctx context.Context, request model.UserImpersonationCreateRequest) model.UserImpersonationResponse
+	UpdateImpersonationToken(ctx context.Context, sessionId int, token string, updatedAt string)
+	StopImpersonation(ctx context.Context, request model.UserImpersonationStopRequest) model.UserImpersonationResponse
+	RecordImpersonationAction(ctx context.Context, request model.UserImpersonationActionRequest)
 }
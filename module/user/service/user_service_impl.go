@@ -6,17 +6,45 @@ import (
 	"collapp/module/user/repository"
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 )
 
+// sessionLastSeenInterval is how stale the last seen time of a session may get before a request writes it again, so
+// not every authenticated request updates the session row
+const sessionLastSeenInterval = time.Minute
+
 type UserServiceImpl struct {
-	UserRepository repository.UserRepository
-	DB             *sql.DB
+	UserRepository                repository.UserRepository
+	UserSessionRepository         repository.UserSessionRepository
+	UserPasswordResetRepository   repository.UserPasswordResetRepository
+	UserLoginAttemptRepository    repository.UserLoginAttemptRepository
+	UserTotpRepository            repository.UserTotpRepository
+	UserApiKeyRepository          repository.UserApiKeyRepository
+	UserOidcRepository            repository.UserOidcRepository
+	UserInvitationRepository      repository.UserInvitationRepository
+	UserImpersonationRepository   repository.UserImpersonationRepository
+	UserPasswordHistoryRepository repository.UserPasswordHistoryRepository
+	UserLoginEventRepository      repository.UserLoginEventRepository
+	UserMagicLinkRepository       repository.UserMagicLinkRepository
+	DB                            *sql.DB
 }
 
-func NewUserService(DB *sql.DB, userRepo repository.UserRepository) UserService {
+func NewUserService(DB *sql.DB, userRepo repository.UserRepository, userSessionRepo repository.UserSessionRepository, userPasswordResetRepo repository.UserPasswordResetRepository, userLoginAttemptRepo repository.UserLoginAttemptRepository, userTotpRepo repository.UserTotpRepository, userApiKeyRepo repository.UserApiKeyRepository, userOidcRepo repository.UserOidcRepository, userInvitationRepo repository.UserInvitationRepository, userImpersonationRepo repository.UserImpersonationRepository, userPasswordHistoryRepo repository.UserPasswordHistoryRepository, userLoginEventRepo repository.UserLoginEventRepository, userMagicLinkRepo repository.UserMagicLinkRepository) UserService {
 	return &UserServiceImpl{
-		UserRepository: userRepo,
-		DB:             DB,
+		UserRepository:                userRepo,
+		UserSessionRepository:         userSessionRepo,
+		UserPasswordResetRepository:   userPasswordResetRepo,
+		UserLoginAttemptRepository:    userLoginAttemptRepo,
+		UserTotpRepository:            userTotpRepo,
+		UserApiKeyRepository:          userApiKeyRepo,
+		UserOidcRepository:            userOidcRepo,
+		UserInvitationRepository:      userInvitationRepo,
+		UserImpersonationRepository:   userImpersonationRepo,
+		UserPasswordHistoryRepository: userPasswordHistoryRepo,
+		UserLoginEventRepository:      userLoginEventRepo,
+		UserMagicLinkRepository:       userMagicLinkRepo,
+		DB:                            DB,
 	}
 }
 
@@ -36,6 +64,28 @@ func (service *UserServiceImpl) Create(ctx context.Context, request model.UserCr
 	}
 }
 
+// Import creates the users of an import and their role in one transaction, the responses follow the order of requests
+func (service *UserServiceImpl) Import(ctx context.Context, requests []model.UserImportRequest) []model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	var userResponses []model.UserResponse
+	for _, request := range requests {
+		userData := service.UserRepository.Save(ctx, tx, request.UserCreateRequest)
+		if request.RoleCode != "" {
+			service.UserRepository.SaveRole(ctx, tx, userData.UserId, request.RoleCode)
+		}
+
+		userData, err = service.UserRepository.FindById(ctx, tx, userData.UserId)
+		helper.IfError(err)
+
+		userResponses = append(userResponses, model.ToUserResponse(userData))
+	}
+
+	return userResponses
+}
+
 func (service *UserServiceImpl) Update(ctx context.Context, request model.UserUpdateRequest) (model.UserResponse, string) {
 	tx, err := service.DB.Begin()
 	helper.IfError(err)
@@ -55,6 +105,32 @@ func (service *UserServiceImpl) Update(ctx context.Context, request model.UserUp
 	return model.ToUserResponse(userData), userPhoto
 }
 
+// UpdateProfile updates what users may change about themselves, the current photo is kept when no new one is given.
+// The replaced photo is returned so the caller can delete its file.
+func (service *UserServiceImpl) UpdateProfile(ctx context.Context, request model.UserProfileUpdateRequest) (model.UserResponse, string) {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, request.UserId)
+	if err != nil || userData.UserId == 0 {
+		return model.ToUserResponse(model.User{}), ""
+	}
+
+	userPhoto := userData.UserPhoto
+	if request.UserPhotoName == "" {
+		request.UserPhotoName = userPhoto
+		userPhoto = ""
+	}
+
+	service.UserRepository.UpdateProfile(ctx, tx, request)
+
+	userData, err = service.UserRepository.FindById(ctx, tx, request.UserId)
+	helper.IfError(err)
+
+	return model.ToUserResponse(userData), userPhoto
+}
+
 func (service *UserServiceImpl) Delete(ctx context.Context, userId int) model.UserResponse {
 	tx, err := service.DB.Begin()
 	helper.IfError(err)
@@ -80,6 +156,7 @@ func (service *UserServiceImpl) SoftDelete(ctx context.Context, request model.Us
 		userData.DeletedAt = request.DeletedAt
 
 		service.UserRepository.SoftDelete(ctx, tx, userData)
+		service.UserSessionRepository.RevokeByUserId(ctx, tx, userData.UserId, 0, request.DeletedAt)
 	}
 
 	return model.ToUserResponse(userData)
@@ -95,12 +172,79 @@ func (service *UserServiceImpl) FindById(ctx context.Context, userId int) model.
 	return model.ToUserResponse(userData)
 }
 
-func (service *UserServiceImpl) FindAll(ctx context.Context) []model.UserResponse {
+func (service *UserServiceImpl) FindAll(ctx context.Context, query helper.ListQuery) ([]model.UserResponse, helper.Pagination) {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	usersData, pagination := service.UserRepository.FindAll(ctx, tx, query)
+
+	return model.ToUserResponses(usersData), pagination
+}
+
+// Export streams the users of the query to write without keeping them in memory
+func (service *UserServiceImpl) Export(ctx context.Context, query helper.ListQuery, write func(user model.UserExportResponse) error) error {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	return service.UserRepository.Export(ctx, tx, query, func(user model.User) error {
+		return write(model.ToUserExportResponse(user))
+	})
+}
+
+func (service *UserServiceImpl) FindAllDeleted(ctx context.Context, query helper.ListQuery) ([]model.UserResponse, helper.Pagination) {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	usersData, pagination := service.UserRepository.FindAllDeleted(ctx, tx, query)
+
+	return model.ToUserResponses(usersData), pagination
+}
+
+func (service *UserServiceImpl) FindDeletedById(ctx context.Context, userId int) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, _ := service.UserRepository.FindDeletedById(ctx, tx, userId)
+
+	return model.ToUserResponse(userData)
+}
+
+// Restore takes the user out of the trash, the sessions revoked by the deletion stay revoked
+func (service *UserServiceImpl) Restore(ctx context.Context, request model.UserRestoreRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData := model.User{}
+	userData.UserId = request.UserId
+	userData.UpdatedBy = request.UpdatedBy
+	userData.UpdatedAt = request.UpdatedAt
+
+	if !service.UserRepository.Restore(ctx, tx, userData) {
+		return model.UserResponse{}
+	}
+
+	userData, err = service.UserRepository.FindById(ctx, tx, request.UserId)
+	helper.IfError(err)
+
+	return model.ToUserResponse(userData)
+}
+
+// PurgeDeleted hard deletes the users soft deleted before deletedBefore and returns them, the caller removes their
+// photo files once the rows are gone
+func (service *UserServiceImpl) PurgeDeleted(ctx context.Context, deletedBefore string) []model.UserResponse {
 	tx, err := service.DB.Begin()
 	helper.IfError(err)
 	defer helper.CommitOrRollback(tx)
 
-	usersData := service.UserRepository.FindAll(ctx, tx)
+	usersData := service.UserRepository.FindDeletedBefore(ctx, tx, deletedBefore)
+	for _, userData := range usersData {
+		service.UserRepository.Delete(ctx, tx, userData)
+	}
 
 	return model.ToUserResponses(usersData)
 }
@@ -115,43 +259,1044 @@ func (service *UserServiceImpl) FindByEmail(ctx context.Context, userEmail strin
 	return model.ToUserLoginResponse(userData)
 }
 
-func (service *UserServiceImpl) FindByTokenRefresh(ctx context.Context, userTokenRefresh string) model.UserLoginResponse {
+func (service *UserServiceImpl) FindPasswordById(ctx context.Context, userId int) model.UserLoginResponse {
 	tx, err := service.DB.Begin()
 	helper.IfError(err)
 	defer helper.CommitOrRollback(tx)
 
-	userData, _ := service.UserRepository.FindByTokenRefresh(ctx, tx, userTokenRefresh)
+	userData, _ := service.UserRepository.FindById(ctx, tx, userId)
 
 	return model.ToUserLoginResponse(userData)
 }
 
-func (service *UserServiceImpl) UpdateToken(ctx context.Context, request model.UserUpdateTokenRequest) model.UserResponse {
+// UpdatePassword stores the new password hash and signs out every other session of the user
+func (service *UserServiceImpl) UpdatePassword(ctx context.Context, request model.UserPasswordUpdateRequest) model.UserResponse {
 	tx, err := service.DB.Begin()
 	helper.IfError(err)
 	defer helper.CommitOrRollback(tx)
 
 	userData, err := service.UserRepository.FindById(ctx, tx, request.UserId)
-	if err == nil {
-		userData.UserId = request.UserId
-		userData.UserToken = request.UserToken
-		userData.UserTokenRefresh = request.UserTokenRefresh
-		userData.UserLastLogin = request.UserLastLogin
+	if err == nil && userData.UserId != 0 {
+		userData.UserPassword = request.UserPassword
+		userData.UserMustChangePassword = false
+		userData.UpdatedBy = request.UpdatedBy
+		userData.UpdatedAt = request.UpdatedAt
+		service.UserRepository.UpdatePassword(ctx, tx, userData)
+		service.savePasswordHistory(ctx, tx, userData.UserId, userData.UserPassword, request.UpdatedAt)
 
-		userData = service.UserRepository.UpdateToken(ctx, tx, userData)
+		service.UserSessionRepository.RevokeByUserId(ctx, tx, userData.UserId, request.SessionId, request.UpdatedAt)
 	}
 
 	return model.ToUserResponse(userData)
 }
 
-func (service *UserServiceImpl) Logout(ctx context.Context, userId int) model.UserResponse {
+// UpdatePasswordHash replaces the hash of the current password, it is used to upgrade the hash on login
+func (service *UserServiceImpl) UpdatePasswordHash(ctx context.Context, userId int, userPassword string) {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData := model.User{}
+	userData.UserId = userId
+	userData.UserPassword = userPassword
+	service.UserRepository.UpdatePasswordHash(ctx, tx, userData)
+}
+
+// FindPasswordHistory returns the current hash of the user followed by the latest limit hashes it had before
+func (service *UserServiceImpl) FindPasswordHistory(ctx context.Context, userId int, limit int) []string {
 	tx, err := service.DB.Begin()
 	helper.IfError(err)
 	defer helper.CommitOrRollback(tx)
 
+	var passwords []string
 	userData, err := service.UserRepository.FindById(ctx, tx, userId)
-	if err == nil {
-		service.UserRepository.Logout(ctx, tx, userData)
+	if err == nil && userData.UserPassword != "" {
+		passwords = append(passwords, userData.UserPassword)
+	}
+
+	if limit > 0 {
+		for _, history := range service.UserPasswordHistoryRepository.FindByUserId(ctx, tx, userId, limit) {
+			passwords = append(passwords, history.HistoryPassword)
+		}
+	}
+
+	return passwords
+}
+
+func (service *UserServiceImpl) savePasswordHistory(ctx context.Context, tx *sql.Tx, userId int, userPassword string, createdAt string) {
+	history := model.UserPasswordHistory{}
+	history.HistoryUserId = userId
+	history.HistoryPassword = userPassword
+	history.HistoryCreatedAt = createdAt
+	service.UserPasswordHistoryRepository.Save(ctx, tx, history)
+}
+
+// ResetPassword stores a password chosen by an admin, the user has to change it on the next login
+func (service *UserServiceImpl) ResetPassword(ctx context.Context, request model.UserPasswordResetRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, request.UserId)
+	if err == nil && userData.UserId != 0 {
+		userData.UserPassword = request.UserPassword
+		userData.UserMustChangePassword = true
+		userData.UpdatedBy = request.UpdatedBy
+		userData.UpdatedAt = request.UpdatedAt
+		service.UserRepository.UpdatePassword(ctx, tx, userData)
+		service.savePasswordHistory(ctx, tx, userData.UserId, userData.UserPassword, request.UpdatedAt)
+
+		service.UserSessionRepository.RevokeByUserId(ctx, tx, userData.UserId, 0, request.UpdatedAt)
+	}
+
+	return model.ToUserResponse(userData)
+}
+
+// FindLoginAttempt reports the recent failures of the email and the client ip, and whether one of them is locked.
+// Dates share the 2006-01-02 15:04:05 layout, so they are compared as strings.
+func (service *UserServiceImpl) FindLoginAttempt(ctx context.Context, request model.UserLoginAttemptRequest) model.UserLoginAttemptResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	attemptResponse := model.UserLoginAttemptResponse{}
+	for keyType, key := range loginAttemptKeys(request) {
+		attempt, _ := service.UserLoginAttemptRepository.FindByKey(ctx, tx, keyType, key)
+
+		if attempt.AttemptLockedUntil > request.CurrentTime {
+			attemptResponse.IsLocked = true
+			if attempt.AttemptLockedUntil > attemptResponse.LockedUntil {
+				attemptResponse.LockedUntil = attempt.AttemptLockedUntil
+			}
+		}
+		if attempt.AttemptLastAt >= request.WindowStart && attempt.AttemptCount > attemptResponse.Failures {
+			attemptResponse.Failures = attempt.AttemptCount
+		}
+	}
+
+	return attemptResponse
+}
+
+// RecordLoginFailure counts a failed login for the email and the client ip, a key that reaches its threshold is
+// locked until request.LockedUntil
+func (service *UserServiceImpl) RecordLoginFailure(ctx context.Context, request model.UserLoginAttemptRequest) model.UserLoginAttemptResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	attemptResponse := model.UserLoginAttemptResponse{}
+	for keyType, key := range loginAttemptKeys(request) {
+		attempt, _ := service.UserLoginAttemptRepository.FindByKey(ctx, tx, keyType, key)
+		if attempt.AttemptLastAt < request.WindowStart {
+			attempt.AttemptCount = 0
+		}
+
+		attempt.AttemptKeyType = keyType
+		attempt.AttemptKey = key
+		attempt.AttemptCount++
+		attempt.AttemptLastAt = request.CurrentTime
+
+		maxAttempts := request.MaxAttempts
+		if keyType == model.LoginAttemptKeyIp {
+			maxAttempts = request.MaxAttemptsIp
+		}
+		if maxAttempts > 0 && attempt.AttemptCount >= maxAttempts {
+			attempt.AttemptLockedUntil = request.LockedUntil
+		}
+
+		service.UserLoginAttemptRepository.Save(ctx, tx, attempt)
+
+		if attempt.AttemptLockedUntil > request.CurrentTime {
+			attemptResponse.IsLocked = true
+			attemptResponse.LockedUntil = attempt.AttemptLockedUntil
+		}
+		if attempt.AttemptCount > attemptResponse.Failures {
+			attemptResponse.Failures = attempt.AttemptCount
+		}
+	}
+
+	return attemptResponse
+}
+
+// ResetLoginAttempt clears the email counter after a successful login, the ip counter keeps running
+func (service *UserServiceImpl) ResetLoginAttempt(ctx context.Context, request model.UserLoginAttemptRequest) {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	service.UserLoginAttemptRepository.Delete(ctx, tx, model.LoginAttemptKeyEmail, strings.ToLower(strings.TrimSpace(request.UserEmail)))
+}
+
+func (service *UserServiceImpl) UnlockLogin(ctx context.Context, userId int) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err == nil && userData.UserId != 0 {
+		service.UserLoginAttemptRepository.Delete(ctx, tx, model.LoginAttemptKeyEmail, strings.ToLower(strings.TrimSpace(userData.UserEmail)))
+	}
+
+	return model.ToUserResponse(userData)
+}
+
+func loginAttemptKeys(request model.UserLoginAttemptRequest) map[string]string {
+	return map[string]string{
+		model.LoginAttemptKeyEmail: strings.ToLower(strings.TrimSpace(request.UserEmail)),
+		model.LoginAttemptKeyIp:    request.Ip,
+	}
+}
+
+// RecordLoginEvent stores the event, a successful login is first compared with the earlier ones of the user to tell
+// whether it came from a new device or location
+func (service *UserServiceImpl) RecordLoginEvent(ctx context.Context, request model.UserLoginEventCreateRequest) model.UserLoginEventResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	isNewDevice := false
+	if request.EventUserId != 0 && request.EventType == model.LoginEventLogin && request.EventIsSuccess {
+		hasLogin, isKnownIp, isKnownUserAgent := service.UserLoginEventRepository.FindKnownDevice(ctx, tx, request.EventUserId, request.EventIp, request.EventUserAgent)
+		isNewDevice = hasLogin && (!isKnownIp || !isKnownUserAgent)
+	}
+
+	event := model.UserLoginEvent{}
+	event.EventUserId = request.EventUserId
+	event.EventEmail = request.EventEmail
+	event.EventType = request.EventType
+	event.EventIsSuccess = request.EventIsSuccess
+	event.EventReason = request.EventReason
+	event.EventIp = request.EventIp
+	event.EventUserAgent = request.EventUserAgent
+	event.EventCreatedAt = request.EventCreatedAt
+	event = service.UserLoginEventRepository.Save(ctx, tx, event)
+
+	eventResponse := model.ToUserLoginEventResponse(event)
+	eventResponse.IsNewDevice = isNewDevice
+
+	return eventResponse
+}
+
+func (service *UserServiceImpl) FindLoginEventByUserId(ctx context.Context, userId int, query helper.ListQuery) ([]model.UserLoginEventResponse, helper.Pagination) {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	events, pagination := service.UserLoginEventRepository.FindByUserId(ctx, tx, userId, query)
+
+	return model.ToUserLoginEventResponses(events), pagination
+}
+
+// UpdateStatus moves the user to the requested status, becoming active clears the reason and the end of a suspension
+func (service *UserServiceImpl) UpdateStatus(ctx context.Context, request model.UserStatusUpdateRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, request.UserId)
+	if err == nil && userData.UserId != 0 {
+		userData.UserStatus = request.UserStatus
+		userData.UserStatusReason = request.UserStatusReason
+		userData.UserSuspendedUntil = request.UserSuspendedUntil
+		if request.UserStatus == model.UserStatusActive {
+			userData.UserStatusReason = ""
+		}
+		if request.UserStatus != model.UserStatusSuspended {
+			userData.UserSuspendedUntil = ""
+		}
+		userData.UpdatedBy = request.UpdatedBy
+		userData.UpdatedAt = request.UpdatedAt
+		service.UserRepository.UpdateStatus(ctx, tx, userData)
+	}
+
+	return model.ToUserResponse(userData)
+}
+
+// LiftSuspensions reactivates the users whose suspension ended before currentTime and returns how many there were
+func (service *UserServiceImpl) LiftSuspensions(ctx context.Context, currentTime string) int {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	return service.UserRepository.LiftSuspensions(ctx, tx, currentTime)
+}
+
+// DisableInactive disables the users that have not signed in since inactiveSince and returns how many there were
+func (service *UserServiceImpl) DisableInactive(ctx context.Context, inactiveSince string, statusReason string, updatedAt string) int {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	return service.UserRepository.DisableInactive(ctx, tx, inactiveSince, statusReason, updatedAt)
+}
+
+// CreatePasswordReset stores a new reset token for the owner of the email and returns that user, or an empty user
+// when the email is unknown or already got MaxRequests tokens since WindowStart
+func (service *UserServiceImpl) CreatePasswordReset(ctx context.Context, request model.UserPasswordForgotRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userCheck, _ := service.UserRepository.FindByEmail(ctx, tx, request.UserEmail)
+	if userCheck.UserId == 0 {
+		return model.ToUserResponse(model.User{})
+	}
+
+	if request.MaxRequests > 0 && service.UserPasswordResetRepository.CountByUserId(ctx, tx, userCheck.UserId, request.WindowStart) >= request.MaxRequests {
+		return model.ToUserResponse(model.User{})
+	}
+
+	service.UserPasswordResetRepository.MarkUsedByUserId(ctx, tx, userCheck.UserId, request.ResetCreatedAt)
+
+	passwordReset := model.UserPasswordReset{}
+	passwordReset.ResetUserId = userCheck.UserId
+	passwordReset.ResetToken = request.ResetToken
+	passwordReset.ResetExpiredAt = request.ResetExpiredAt
+	passwordReset.ResetCreatedAt = request.ResetCreatedAt
+	service.UserPasswordResetRepository.Save(ctx, tx, passwordReset)
+
+	userData, _ := service.UserRepository.FindById(ctx, tx, userCheck.UserId)
+
+	return model.ToUserResponse(userData)
+}
+
+// FindPasswordResetUser returns the user of an open reset token without consuming it, or an empty user
+func (service *UserServiceImpl) FindPasswordResetUser(ctx context.Context, resetToken string, currentTime string) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	passwordReset, _ := service.UserPasswordResetRepository.FindByToken(ctx, tx, resetToken, currentTime)
+	if passwordReset.ResetId == 0 {
+		return model.ToUserResponse(model.User{})
+	}
+
+	userData, _ := service.UserRepository.FindById(ctx, tx, passwordReset.ResetUserId)
+
+	return model.ToUserResponse(userData)
+}
+
+// ResetPasswordByToken consumes a reset token, sets the new password and signs out every session of the user
+func (service *UserServiceImpl) ResetPasswordByToken(ctx context.Context, request model.UserPasswordResetTokenRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	passwordReset, _ := service.UserPasswordResetRepository.FindByToken(ctx, tx, request.ResetToken, request.ResetUsedAt)
+	if passwordReset.ResetId == 0 || !service.UserPasswordResetRepository.Use(ctx, tx, passwordReset.ResetId, request.ResetUsedAt) {
+		return model.ToUserResponse(model.User{})
+	}
+
+	service.UserPasswordResetRepository.MarkUsedByUserId(ctx, tx, passwordReset.ResetUserId, request.ResetUsedAt)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, passwordReset.ResetUserId)
+	if err == nil && userData.UserId != 0 {
+		userData.UserPassword = request.UserPassword
+		userData.UserMustChangePassword = false
+		userData.UpdatedBy = userData.UserId
+		userData.UpdatedAt = request.ResetUsedAt
+		service.UserRepository.UpdatePassword(ctx, tx, userData)
+		service.savePasswordHistory(ctx, tx, userData.UserId, userData.UserPassword, request.ResetUsedAt)
+
+		service.UserSessionRepository.RevokeByUserId(ctx, tx, userData.UserId, 0, request.ResetUsedAt)
+	}
+
+	return model.ToUserResponse(userData)
+}
+
+// UseTokenRefresh checks the refresh token against the current one of its session, a refresh token that was already
+// rotated out means it has been replayed, so the whole session (the token family) gets revoked and true is returned
+func (service *UserServiceImpl) UseTokenRefresh(ctx context.Context, request model.UserRefreshTokenRequest) (model.UserLoginResponse, bool) {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	// a session without refresh token (impersonation) can not be refreshed, nor count a refresh as reuse
+	sessionData, _ := service.UserSessionRepository.FindById(ctx, tx, request.SessionId)
+	if sessionData.SessionId == 0 || sessionData.SessionUserId != request.UserId || sessionData.SessionTokenRefresh == "" || request.UserTokenRefresh == "" {
+		return model.ToUserLoginResponse(model.User{}), false
+	}
+
+	if sessionData.SessionTokenRefresh != helper.HashToken(request.UserTokenRefresh) {
+		sessionData.SessionRevokedAt = request.RevokedAt
+		service.UserSessionRepository.Revoke(ctx, tx, sessionData)
+
+		return model.ToUserLoginResponse(model.User{}), true
+	}
+
+	userData, _ := service.UserRepository.FindById(ctx, tx, sessionData.SessionUserId)
+	userData.SessionId = sessionData.SessionId
+
+	return model.ToUserLoginResponse(userData), false
+}
+
+func (service *UserServiceImpl) UpdateToken(ctx context.Context, request model.UserUpdateTokenRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, request.UserId)
+	if err == nil && userData.UserId != 0 {
+		userData.UserLastLogin = request.UserLastLogin
+		userData = service.UserRepository.UpdateLastLogin(ctx, tx, userData)
+
+		sessionData := model.UserSession{}
+		sessionData.SessionId = request.SessionId
+		sessionData.SessionToken = request.UserToken
+		sessionData.SessionTokenRefresh = request.UserTokenRefresh
+		sessionData.SessionLastSeenAt = request.UserLastLogin
+		service.UserSessionRepository.UpdateToken(ctx, tx, sessionData)
+
+		userData.UserToken = request.UserToken
+		userData.UserTokenRefresh = request.UserTokenRefresh
 	}
 
 	return model.ToUserResponse(userData)
 }
+
+// RotateToken stores the token pair of a refresh in place of UserTokenRefreshUsed. When a concurrent refresh with the
+// same token rotated the session first, this one is a replay too: the session gets revoked and true is returned.
+func (service *UserServiceImpl) RotateToken(ctx context.Context, request model.UserUpdateTokenRequest) (model.UserResponse, bool) {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	sessionData := model.UserSession{}
+	sessionData.SessionId = request.SessionId
+	sessionData.SessionToken = request.UserToken
+	sessionData.SessionTokenRefresh = request.UserTokenRefresh
+	sessionData.SessionLastSeenAt = request.UserLastLogin
+	if !service.UserSessionRepository.RotateToken(ctx, tx, sessionData, request.UserTokenRefreshUsed) {
+		sessionData.SessionRevokedAt = request.UserLastLogin
+		service.UserSessionRepository.Revoke(ctx, tx, sessionData)
+
+		return model.ToUserResponse(model.User{}), true
+	}
+
+	userData, err := service.UserRepository.FindById(ctx, tx, request.UserId)
+	if err == nil && userData.UserId != 0 {
+		userData.UserLastLogin = request.UserLastLogin
+		userData = service.UserRepository.UpdateLastLogin(ctx, tx, userData)
+
+		userData.UserToken = request.UserToken
+		userData.UserTokenRefresh = request.UserTokenRefresh
+	}
+
+	return model.ToUserResponse(userData), false
+}
+
+func (service *UserServiceImpl) Logout(ctx context.Context, request model.UserSessionRevokeRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, request.SessionUserId)
+	if err == nil && userData.UserId != 0 {
+		sessionData := model.UserSession{}
+		sessionData.SessionId = request.SessionId
+		sessionData.SessionRevokedAt = request.SessionRevokedAt
+		service.UserSessionRepository.Revoke(ctx, tx, sessionData)
+	}
+
+	return model.ToUserResponse(userData)
+}
+
+func (service *UserServiceImpl) CreateSession(ctx context.Context, request model.UserSessionCreateRequest) model.UserSessionResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	sessionData := service.UserSessionRepository.Save(ctx, tx, request)
+	if sessionData.SessionId > 0 {
+		sessionData, err := service.UserSessionRepository.FindById(ctx, tx, sessionData.SessionId)
+		helper.IfError(err)
+
+		return model.ToUserSessionResponse(sessionData)
+	} else {
+		return model.ToUserSessionResponse(sessionData)
+	}
+}
+
+// ValidateSession returns the session owner when the token is the current access token of an active session,
+// otherwise an empty user
+func (service *UserServiceImpl) ValidateSession(ctx context.Context, sessionId int, userId int, token string, lastSeenAt string) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	sessionData, _ := service.UserSessionRepository.FindById(ctx, tx, sessionId)
+	if sessionData.SessionId == 0 || sessionData.SessionUserId != userId || sessionData.SessionToken != helper.HashToken(token) {
+		return model.ToUserResponse(model.User{})
+	}
+
+	userData, _ := service.UserRepository.FindById(ctx, tx, userId)
+	if userData.UserId == 0 {
+		return model.ToUserResponse(model.User{})
+	}
+
+	if isSessionLastSeenStale(sessionData.SessionLastSeenAt, lastSeenAt) {
+		sessionData.SessionLastSeenAt = lastSeenAt
+		service.UserSessionRepository.UpdateLastSeen(ctx, tx, sessionData)
+	}
+
+	return model.ToUserResponse(userData)
+}
+
+// isSessionLastSeenStale tells whether the stored last seen time is at least sessionLastSeenInterval older than the
+// current one, a missing or unreadable stored time is stale
+func isSessionLastSeenStale(storedLastSeenAt string, lastSeenAt string) bool {
+	stored, err := time.Parse("2006-01-02 15:04:05", storedLastSeenAt)
+	if err != nil {
+		return true
+	}
+	current, err := time.Parse("2006-01-02 15:04:05", lastSeenAt)
+	if err != nil {
+		return true
+	}
+
+	return current.Sub(stored) >= sessionLastSeenInterval
+}
+
+// IntrospectSession returns the session owner when the token is the current access or refresh token of an active
+// session, otherwise an empty user. Unlike ValidateSession it does not touch the session, the caller is another service.
+func (service *UserServiceImpl) IntrospectSession(ctx context.Context, sessionId int, userId int, token string, isRefresh bool) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	sessionData, _ := service.UserSessionRepository.FindById(ctx, tx, sessionId)
+	sessionToken := sessionData.SessionToken
+	if isRefresh {
+		sessionToken = sessionData.SessionTokenRefresh
+	}
+	if sessionData.SessionId == 0 || sessionData.SessionUserId != userId || sessionToken == "" || token == "" || sessionToken != helper.HashToken(token) {
+		return model.ToUserResponse(model.User{})
+	}
+
+	userData, _ := service.UserRepository.FindById(ctx, tx, userId)
+
+	return model.ToUserResponse(userData)
+}
+
+func (service *UserServiceImpl) FindSessionByUserId(ctx context.Context, userId int) []model.UserSessionResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	sessionsData := service.UserSessionRepository.FindByUserId(ctx, tx, userId)
+
+	return model.ToUserSessionResponses(sessionsData)
+}
+
+func (service *UserServiceImpl) RevokeSession(ctx context.Context, request model.UserSessionRevokeRequest) model.UserSessionResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	sessionData, err := service.UserSessionRepository.FindById(ctx, tx, request.SessionId)
+	if err == nil && sessionData.SessionUserId == request.SessionUserId {
+		sessionData.SessionRevokedAt = request.SessionRevokedAt
+		service.UserSessionRepository.Revoke(ctx, tx, sessionData)
+
+		return model.ToUserSessionResponse(sessionData)
+	}
+
+	return model.ToUserSessionResponse(model.UserSession{})
+}
+
+func (service *UserServiceImpl) FindTotpByUserId(ctx context.Context, userId int) model.UserTotpResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	totpData, _ := service.UserTotpRepository.FindByUserId(ctx, tx, userId)
+
+	return model.ToUserTotpResponse(totpData)
+}
+
+// EnrollTotp stores a new secret that stays disabled until ConfirmTotp, an already enabled secret is left untouched
+func (service *UserServiceImpl) EnrollTotp(ctx context.Context, request model.UserTotpEnrollRequest) model.UserTotpResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	totpData, _ := service.UserTotpRepository.FindByUserId(ctx, tx, request.UserId)
+	if totpData.TotpConfirmedAt != "" {
+		return model.ToUserTotpResponse(totpData)
+	}
+
+	totpData = model.UserTotp{}
+	totpData.TotpUserId = request.UserId
+	totpData.TotpSecret = request.TotpSecret
+	totpData.TotpCreatedAt = request.TotpCreatedAt
+	totpData = service.UserTotpRepository.Save(ctx, tx, totpData)
+
+	return model.ToUserTotpResponse(totpData)
+}
+
+// ConfirmTotp enables two factor when the code matches the enrolled secret and replaces the recovery codes, it returns
+// an empty response when the code is wrong
+func (service *UserServiceImpl) ConfirmTotp(ctx context.Context, request model.UserTotpConfirmRequest) model.UserTotpResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	totpData, _ := service.UserTotpRepository.FindByUserId(ctx, tx, request.UserId)
+	if totpData.TotpUserId == 0 || totpData.TotpConfirmedAt != "" {
+		return model.ToUserTotpResponse(model.UserTotp{})
+	}
+
+	step, isValid := helper.ValidateTotp(totpData.TotpSecret, request.Code, request.CurrentTime)
+	if !isValid || !service.UserTotpRepository.UseStep(ctx, tx, totpData.TotpUserId, step) {
+		return model.ToUserTotpResponse(model.UserTotp{})
+	}
+
+	totpData.TotpConfirmedAt = request.TotpConfirmedAt
+	service.UserTotpRepository.Confirm(ctx, tx, totpData)
+
+	service.UserTotpRepository.DeleteRecoveryCode(ctx, tx, totpData.TotpUserId)
+	for _, code := range request.RecoveryCodes {
+		recoveryCode := model.UserRecoveryCode{}
+		recoveryCode.RecoveryUserId = totpData.TotpUserId
+		recoveryCode.RecoveryCode = code
+		recoveryCode.RecoveryCreatedAt = request.TotpConfirmedAt
+		service.UserTotpRepository.SaveRecoveryCode(ctx, tx, recoveryCode)
+	}
+
+	return model.ToUserTotpResponse(totpData)
+}
+
+// VerifyTotp accepts the current authenticator code or an unused recovery code of a user with two factor enabled,
+// both are burned once accepted
+func (service *UserServiceImpl) VerifyTotp(ctx context.Context, request model.UserTotpVerifyRequest) bool {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	totpData, _ := service.UserTotpRepository.FindByUserId(ctx, tx, request.UserId)
+	if totpData.TotpConfirmedAt == "" {
+		return false
+	}
+
+	code := strings.ToLower(strings.TrimSpace(request.Code))
+	if len(code) == helper.TotpDigits {
+		step, isValid := helper.ValidateTotp(totpData.TotpSecret, code, request.CurrentTime)
+
+		return isValid && service.UserTotpRepository.UseStep(ctx, tx, totpData.TotpUserId, step)
+	}
+
+	recoveryCode := model.UserRecoveryCode{}
+	recoveryCode.RecoveryUserId = totpData.TotpUserId
+	recoveryCode.RecoveryCode = code
+	recoveryCode.RecoveryUsedAt = request.CurrentTime.Format("2006-01-02 15:04:05")
+
+	return service.UserTotpRepository.UseRecoveryCode(ctx, tx, recoveryCode)
+}
+
+func (service *UserServiceImpl) DisableTotp(ctx context.Context, userId int) model.UserTotpResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	totpData, _ := service.UserTotpRepository.FindByUserId(ctx, tx, userId)
+	if totpData.TotpUserId != 0 {
+		service.UserTotpRepository.Delete(ctx, tx, userId)
+		service.UserTotpRepository.DeleteRecoveryCode(ctx, tx, userId)
+	}
+
+	return model.ToUserTotpResponse(totpData)
+}
+
+// CreateServiceAccount creates a user without a password, it can only authenticate with its API keys
+func (service *UserServiceImpl) CreateServiceAccount(ctx context.Context, request model.UserServiceAccountCreateRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userCreateRequest := model.UserCreateRequest{}
+	userCreateRequest.UserName = request.UserName
+	userCreateRequest.UserEmail = request.UserEmail
+	userCreateRequest.UserLangCode = request.UserLangCode
+	userCreateRequest.UserIsServiceAccount = true
+	userCreateRequest.UserStatus = model.UserStatusActive
+	userCreateRequest.CreatedBy = request.CreatedBy
+	userCreateRequest.CreatedAt = request.CreatedAt
+
+	userData := service.UserRepository.Save(ctx, tx, userCreateRequest)
+	userData, err = service.UserRepository.FindById(ctx, tx, userData.UserId)
+	helper.IfError(err)
+
+	return model.ToUserResponse(userData)
+}
+
+// CreateApiKey stores a new key of a service account, it returns an empty response when the user is not one
+func (service *UserServiceImpl) CreateApiKey(ctx context.Context, request model.UserApiKeyCreateRequest) model.UserApiKeyResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, request.ApiKeyUserId)
+	if err != nil || userData.UserId == 0 || !userData.UserIsServiceAccount {
+		return model.ToUserApiKeyResponse(model.UserApiKey{})
+	}
+
+	apiKeyData := model.UserApiKey{}
+	apiKeyData.ApiKeyUserId = request.ApiKeyUserId
+	apiKeyData.ApiKeyName = request.ApiKeyName
+	apiKeyData.ApiKey = request.ApiKey
+	apiKeyData.ApiKeyPrefix = request.ApiKey[:model.ApiKeyPrefixLength]
+	apiKeyData.ApiKeyScopes = request.ApiKeyScopes
+	apiKeyData.ApiKeyExpiredAt = request.ApiKeyExpiredAt
+	apiKeyData.CreatedBy = request.CreatedBy
+	apiKeyData.CreatedAt = request.CreatedAt
+	apiKeyData = service.UserApiKeyRepository.Save(ctx, tx, apiKeyData)
+
+	return model.ToUserApiKeyResponse(apiKeyData)
+}
+
+func (service *UserServiceImpl) FindApiKeyByUserId(ctx context.Context, userId int) []model.UserApiKeyResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	apiKeysData := service.UserApiKeyRepository.FindByUserId(ctx, tx, userId)
+
+	return model.ToUserApiKeyResponses(apiKeysData)
+}
+
+func (service *UserServiceImpl) RevokeApiKey(ctx context.Context, request model.UserApiKeyRevokeRequest) model.UserApiKeyResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	apiKeyData, err := service.UserApiKeyRepository.FindById(ctx, tx, request.ApiKeyId)
+	if err == nil && apiKeyData.ApiKeyId != 0 && apiKeyData.ApiKeyUserId == request.ApiKeyUserId {
+		apiKeyData.ApiKeyRevokedAt = request.ApiKeyRevokedAt
+		service.UserApiKeyRepository.Revoke(ctx, tx, apiKeyData)
+
+		return model.ToUserApiKeyResponse(apiKeyData)
+	}
+
+	return model.ToUserApiKeyResponse(model.UserApiKey{})
+}
+
+// ValidateApiKey returns the key when it is neither revoked nor expired at usedAt and its service account still
+// exists, otherwise an empty response. The last used time of a valid key is updated.
+func (service *UserServiceImpl) ValidateApiKey(ctx context.Context, apiKey string, usedAt string) model.UserApiKeyResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	apiKeyData, _ := service.UserApiKeyRepository.FindByKey(ctx, tx, apiKey)
+	if apiKeyData.ApiKeyId == 0 || apiKeyData.ApiKeyRevokedAt != "" || (apiKeyData.ApiKeyExpiredAt != "" && apiKeyData.ApiKeyExpiredAt <= usedAt) {
+		return model.ToUserApiKeyResponse(model.UserApiKey{})
+	}
+
+	userData, _ := service.UserRepository.FindById(ctx, tx, apiKeyData.ApiKeyUserId)
+	if userData.UserId == 0 || !userData.UserIsServiceAccount {
+		return model.ToUserApiKeyResponse(model.UserApiKey{})
+	}
+
+	apiKeyData.ApiKeyLastUsedAt = usedAt
+	service.UserApiKeyRepository.UpdateLastUsed(ctx, tx, apiKeyData)
+
+	return model.ToUserApiKeyResponse(apiKeyData)
+}
+
+func (service *UserServiceImpl) CreateOidcState(ctx context.Context, request model.UserOidcState) model.UserOidcState {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	return service.UserOidcRepository.SaveState(ctx, tx, request)
+}
+
+// UseOidcState consumes the state of a single sign-on, it returns an empty state when it is unknown, expired or was
+// started for another provider
+func (service *UserServiceImpl) UseOidcState(ctx context.Context, stateKey string, provider string, currentTime string) model.UserOidcState {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	return service.UserOidcRepository.UseState(ctx, tx, stateKey, provider, currentTime)
+}
+
+// LoginOidc finds the user of a verified provider identity. An unknown subject gets a new account when the provider
+// allows it and no account has its email yet, an existing account has to link the identity with LinkOidc first. Service
+// accounts never sign in through a provider. An empty user is returned when none of these apply.
+func (service *UserServiceImpl) LoginOidc(ctx context.Context, request model.UserOidcLoginRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	identityData := service.UserOidcRepository.FindIdentity(ctx, tx, request.IdentityProvider, request.IdentitySubject)
+	if identityData.IdentityId != 0 {
+		userData, _ := service.UserRepository.FindById(ctx, tx, identityData.IdentityUserId)
+		if userData.UserIsServiceAccount {
+			return model.ToUserResponse(model.User{})
+		}
+
+		return model.ToUserResponse(userData)
+	}
+
+	if request.IdentityEmail == "" || !request.EmailVerified || !request.AutoCreate {
+		return model.ToUserResponse(model.User{})
+	}
+
+	userCheck, _ := service.UserRepository.FindByEmail(ctx, tx, request.IdentityEmail)
+	if userCheck.UserId != 0 {
+		return model.ToUserResponse(model.User{})
+	}
+
+	userName := request.UserName
+	if userName == "" {
+		userName = request.IdentityEmail
+	}
+
+	// no password, the account signs in through its provider until a password is set with the forgot password flow
+	userCreateRequest := model.UserCreateRequest{}
+	userCreateRequest.UserName = helper.Truncate(userName, 200)
+	userCreateRequest.UserEmail = request.IdentityEmail
+	userCreateRequest.UserLangCode = request.UserLangCode
+	userCreateRequest.UserStatus = model.UserStatusActive
+	userCreateRequest.CreatedAt = request.IdentityCreatedAt
+	userId := service.UserRepository.Save(ctx, tx, userCreateRequest).UserId
+
+	identityData.IdentityUserId = userId
+	identityData.IdentityProvider = request.IdentityProvider
+	identityData.IdentitySubject = request.IdentitySubject
+	identityData.IdentityEmail = request.IdentityEmail
+	identityData.IdentityCreatedAt = request.IdentityCreatedAt
+	service.UserOidcRepository.SaveIdentity(ctx, tx, identityData)
+
+	userData, _ := service.UserRepository.FindById(ctx, tx, userId)
+
+	return model.ToUserResponse(userData)
+}
+
+// LinkOidc links a provider identity to the signed in user who started the sign-on, it returns an empty user when the
+// identity already belongs to another user or the user is a service account
+func (service *UserServiceImpl) LinkOidc(ctx context.Context, request model.UserOidcLinkRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, request.UserId)
+	if err != nil || userData.UserId == 0 || userData.UserIsServiceAccount {
+		return model.ToUserResponse(model.User{})
+	}
+
+	identityData := service.UserOidcRepository.FindIdentity(ctx, tx, request.IdentityProvider, request.IdentitySubject)
+	if identityData.IdentityId != 0 {
+		if identityData.IdentityUserId != userData.UserId {
+			return model.ToUserResponse(model.User{})
+		}
+
+		return model.ToUserResponse(userData)
+	}
+
+	identityData.IdentityUserId = userData.UserId
+	identityData.IdentityProvider = request.IdentityProvider
+	identityData.IdentitySubject = request.IdentitySubject
+	identityData.IdentityEmail = request.IdentityEmail
+	identityData.IdentityCreatedAt = request.IdentityCreatedAt
+	service.UserOidcRepository.SaveIdentity(ctx, tx, identityData)
+
+	return model.ToUserResponse(userData)
+}
+
+// CreateInvitation replaces the open invitations of a pending user with a new one, it returns an empty user when the
+// user is not pending
+func (service *UserServiceImpl) CreateInvitation(ctx context.Context, request model.UserInvitationCreateRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, request.UserId)
+	if err != nil || userData.UserId == 0 || userData.UserStatus != model.UserStatusPending {
+		return model.ToUserResponse(model.User{})
+	}
+
+	service.UserInvitationRepository.RevokeByUserId(ctx, tx, userData.UserId, request.CreatedAt)
+
+	invitationData := model.UserInvitation{}
+	invitationData.InvitationUserId = userData.UserId
+	invitationData.InvitationToken = request.InvitationToken
+	invitationData.InvitationExpiredAt = request.InvitationExpiredAt
+	invitationData.CreatedBy = request.CreatedBy
+	invitationData.CreatedAt = request.CreatedAt
+	service.UserInvitationRepository.Save(ctx, tx, invitationData)
+
+	return model.ToUserResponse(userData)
+}
+
+// RevokeInvitation revokes the open invitations of a pending user, the account itself stays pending
+func (service *UserServiceImpl) RevokeInvitation(ctx context.Context, request model.UserInvitationRevokeRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, request.UserId)
+	if err != nil || userData.UserId == 0 || userData.UserStatus != model.UserStatusPending {
+		return model.ToUserResponse(model.User{})
+	}
+
+	if service.UserInvitationRepository.RevokeByUserId(ctx, tx, userData.UserId, request.RevokedAt) == 0 {
+		return model.ToUserResponse(model.User{})
+	}
+
+	return model.ToUserResponse(userData)
+}
+
+// AcceptInvitation consumes an open invitation, sets the password chosen by the user and activates the account
+func (service *UserServiceImpl) AcceptInvitation(ctx context.Context, request model.UserInvitationAcceptRequest) model.UserResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	invitationData := service.UserInvitationRepository.FindByToken(ctx, tx, request.UserId, request.InvitationToken, request.InvitationAcceptedAt)
+	if invitationData.InvitationId == 0 {
+		return model.ToUserResponse(model.User{})
+	}
+
+	userData, err := service.UserRepository.FindById(ctx, tx, invitationData.InvitationUserId)
+	if err != nil || userData.UserId == 0 || userData.UserStatus != model.UserStatusPending {
+		return model.ToUserResponse(model.User{})
+	}
+
+	invitationData.InvitationAcceptedAt = request.InvitationAcceptedAt
+	if !service.UserInvitationRepository.Accept(ctx, tx, invitationData) {
+		return model.ToUserResponse(model.User{})
+	}
+
+	userData.UserPassword = request.UserPassword
+	userData.UserMustChangePassword = false
+	userData.UserStatus = model.UserStatusActive
+	userData.UpdatedBy = userData.UserId
+	userData.UpdatedAt = request.InvitationAcceptedAt
+	service.UserRepository.UpdatePassword(ctx, tx, userData)
+	service.savePasswordHistory(ctx, tx, userData.UserId, userData.UserPassword, request.InvitationAcceptedAt)
+	service.UserRepository.UpdateStatus(ctx, tx, userData)
+
+	return model.ToUserResponse(userData)
+}
+
+// CreateMagicLink stores the id of a new magic link token, false means the user already got MaxRequests links since
+// WindowStart and no link must be sent
+func (service *UserServiceImpl) CreateMagicLink(ctx context.Context, request model.UserMagicLinkCreateRequest) bool {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	if request.MaxRequests > 0 && service.UserMagicLinkRepository.CountByUserId(ctx, tx, request.UserId, request.WindowStart) >= request.MaxRequests {
+		return false
+	}
+
+	magicLink := model.UserMagicLink{}
+	magicLink.LinkUserId = request.UserId
+	magicLink.LinkToken = request.LinkToken
+	magicLink.LinkExpiredAt = request.LinkExpiredAt
+	magicLink.LinkCreatedAt = request.LinkCreatedAt
+	service.UserMagicLinkRepository.Save(ctx, tx, magicLink)
+
+	return true
+}
+
+// UseMagicLink consumes the magic link token of the user, false means it is unknown, expired or was used already
+func (service *UserServiceImpl) UseMagicLink(ctx context.Context, request model.UserMagicLinkConsumeRequest) bool {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	magicLink := model.UserMagicLink{}
+	magicLink.LinkUserId = request.UserId
+	magicLink.LinkToken = request.LinkToken
+	magicLink.LinkUsedAt = request.LinkUsedAt
+
+	return service.UserMagicLinkRepository.Use(ctx, tx, magicLink)
+}
+
+// StartImpersonation opens a session of the impersonated user that is tied to the actor, it returns an empty response
+// when the user does not exist, is a service account or is not active
+func (service *UserServiceImpl) StartImpersonation(ctx context.Context, request model.UserImpersonationCreateRequest) model.UserImpersonationResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	userData, err := service.UserRepository.FindById(ctx, tx, request.ImpersonationUserId)
+	if err != nil || userData.UserId == 0 || userData.UserIsServiceAccount || userData.UserStatus != model.UserStatusActive {
+		return model.ToUserImpersonationResponse(model.UserImpersonation{})
+	}
+
+	sessionRequest := model.UserSessionCreateRequest{}
+	sessionRequest.SessionUserId = userData.UserId
+	sessionRequest.SessionUserAgent = request.SessionUserAgent
+	sessionRequest.SessionIp = request.SessionIp
+	sessionRequest.SessionCreatedAt = request.ImpersonationStartedAt
+	sessionData := service.UserSessionRepository.Save(ctx, tx, sessionRequest)
+
+	impersonationData := model.UserImpersonation{}
+	impersonationData.ImpersonationActorId = request.ImpersonationActorId
+	impersonationData.ImpersonationUserId = userData.UserId
+	impersonationData.ImpersonationSessionId = sessionData.SessionId
+	impersonationData.ImpersonationReason = request.ImpersonationReason
+	impersonationData.ImpersonationStartedAt = request.ImpersonationStartedAt
+	impersonationData = service.UserImpersonationRepository.Save(ctx, tx, impersonationData)
+
+	return model.ToUserImpersonationResponse(impersonationData)
+}
+
+// UpdateImpersonationToken stores the access token of an impersonation session, the session never gets a refresh token
+func (service *UserServiceImpl) UpdateImpersonationToken(ctx context.Context, sessionId int, token string, updatedAt string) {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	sessionData := model.UserSession{}
+	sessionData.SessionId = sessionId
+	sessionData.SessionToken = token
+	sessionData.SessionLastSeenAt = updatedAt
+	service.UserSessionRepository.UpdateToken(ctx, tx, sessionData)
+}
+
+// StopImpersonation ends the impersonation of the session and revokes the session, only the actor who started it can
+func (service *UserServiceImpl) StopImpersonation(ctx context.Context, request model.UserImpersonationStopRequest) model.UserImpersonationResponse {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	impersonationData := service.UserImpersonationRepository.FindBySessionId(ctx, tx, request.ImpersonationSessionId)
+	if impersonationData.ImpersonationId == 0 || impersonationData.ImpersonationActorId != request.ImpersonationActorId {
+		return model.ToUserImpersonationResponse(model.UserImpersonation{})
+	}
+
+	impersonationData.ImpersonationEndedAt = request.ImpersonationEndedAt
+	if !service.UserImpersonationRepository.End(ctx, tx, impersonationData) {
+		return model.ToUserImpersonationResponse(model.UserImpersonation{})
+	}
+
+	sessionData := model.UserSession{}
+	sessionData.SessionId = impersonationData.ImpersonationSessionId
+	sessionData.SessionRevokedAt = request.ImpersonationEndedAt
+	service.UserSessionRepository.Revoke(ctx, tx, sessionData)
+
+	return model.ToUserImpersonationResponse(impersonationData)
+}
+
+func (service *UserServiceImpl) RecordImpersonationAction(ctx context.Context, request model.UserImpersonationActionRequest) {
+	tx, err := service.DB.Begin()
+	helper.IfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	impersonationData := service.UserImpersonationRepository.FindBySessionId(ctx, tx, request.ImpersonationSessionId)
+	if impersonationData.ImpersonationId == 0 {
+		return
+	}
+
+	actionData := model.UserImpersonationAction{}
+	actionData.ActionImpersonationId = impersonationData.ImpersonationId
+	actionData.ActionMethod = request.ActionMethod
+	actionData.ActionPath = helper.Truncate(request.ActionPath, 255)
+	actionData.ActionCreatedAt = request.ActionCreatedAt
+	service.UserImpersonationRepository.SaveAction(ctx, tx, actionData)
+}
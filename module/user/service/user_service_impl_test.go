@@ -0,0 +1,28 @@
+package service
+
+import "testing"
+
+func TestIsSessionLastSeenStale(t *testing.T) {
+	tests := []struct {
+		name             string
+		storedLastSeenAt string
+		lastSeenAt       string
+		isStale          bool
+	}{
+		{name: "same second", storedLastSeenAt: "2022-10-01 08:00:00", lastSeenAt: "2022-10-01 08:00:00"},
+		{name: "within the interval", storedLastSeenAt: "2022-10-01 08:00:00", lastSeenAt: "2022-10-01 08:00:59"},
+		{name: "interval passed", storedLastSeenAt: "2022-10-01 08:00:00", lastSeenAt: "2022-10-01 08:01:00", isStale: true},
+		{name: "next day", storedLastSeenAt: "2022-10-01 23:59:30", lastSeenAt: "2022-10-02 00:00:30", isStale: true},
+		{name: "stored in the future", storedLastSeenAt: "2022-10-01 08:05:00", lastSeenAt: "2022-10-01 08:00:00"},
+		{name: "never seen", storedLastSeenAt: "", lastSeenAt: "2022-10-01 08:00:00", isStale: true},
+		{name: "unreadable", storedLastSeenAt: "2022-10-01T08:00:00Z", lastSeenAt: "2022-10-01 08:00:00", isStale: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if isStale := isSessionLastSeenStale(test.storedLastSeenAt, test.lastSeenAt); isStale != test.isStale {
+				t.Errorf("isSessionLastSeenStale = %v, want %v", isStale, test.isStale)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package http
 
 import (
 	"collapp/configs"
+	"collapp/infras"
 	"collapp/transport/http/middleware"
 	"collapp/transport/http/router"
+	"collapp/transport/job"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,32 +15,44 @@ type HTTP struct {
 	Config         *configs.Config
 	Router         router.Router
 	AuthMiddleware middleware.AuthMiddleware
+	KeySet         *infras.KeySet
+	Job            *job.Job
 	routerEngine   *gin.Engine
 }
 
 // NewHTTP is the provider for HTTP.
-func NewHTTP(config *configs.Config, router router.Router, authMiddleware middleware.AuthMiddleware) *HTTP {
+func NewHTTP(config *configs.Config, router router.Router, authMiddleware middleware.AuthMiddleware, keySet *infras.KeySet, job *job.Job) *HTTP {
 	return &HTTP{
 		Config:         config,
 		Router:         router,
 		AuthMiddleware: authMiddleware,
+		KeySet:         keySet,
+		Job:            job,
 	}
 }
 
 func (h *HTTP) setupRoutes() {
-	h.routerEngine = gin.Default()
 	routerV1 := h.routerEngine.Group("/api/v1")
 	h.Router.SetupRoutes(routerV1, h.AuthMiddleware)
+
+	// the public keys are served as a bare JWK set so other services can verify tokens with any JOSE library
+	h.routerEngine.GET("/.well-known/jwks.json", func(context *gin.Context) {
+		context.Header("Cache-Control", "public, max-age=300")
+		context.JSON(200, h.KeySet.JWKS())
+	})
 }
 
+// setupMiddleware must run before setupRoutes, gin only applies middleware to the routes registered after it
 func (h *HTTP) setupMiddleware() {
-	h.routerEngine.Use(middleware.CORS())
+	h.routerEngine = gin.Default()
+	h.routerEngine.Use(middleware.CORS(h.Config))
 }
 
 // SetupAndServe will build a new router and prepare whatever the http router's need
 func (h *HTTP) SetupAndServe() {
-	h.setupRoutes()
 	h.setupMiddleware()
+	h.setupRoutes()
+	h.Job.Start()
 
 	h.routerEngine.Run(h.Config.Address)
 }
@@ -3,94 +3,259 @@ package middleware
 import (
 	"collapp/configs"
 	"collapp/helper"
+	"collapp/infras"
+	roleService "collapp/module/role/service"
 	translationService "collapp/module/translation/service"
+	"collapp/module/user/model"
 	"collapp/module/user/service"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	TokenTypeAccess     = "access"
+	TokenTypeRefresh    = "refresh"
+	TokenTypeChallenge  = "2fa_challenge"
+	TokenTypeInvitation = "invitation"
+	TokenTypeMagicLink  = "magic_link"
+	RefreshTokenCookie  = "refresh_token"
+
+	// PasswordChangePath is the only route a user flagged with must_change_password can reach
+	PasswordChangePath = "/api/v1/users/me/password"
+)
+
 type Claims struct {
-	UserId       int    `json:"user_id"`
-	UserName     string `json:"user_name"`
-	UserEmail    string `json:"user_email"`
-	UserLangCode string `json:"user_lang_code"`
+	UserId       int          `json:"user_id"`
+	UserName     string       `json:"user_name"`
+	UserEmail    string       `json:"user_email"`
+	UserLangCode string       `json:"user_lang_code"`
+	UserRoles    []string     `json:"user_roles"`
+	SessionId    int          `json:"session_id"`
+	TokenType    string       `json:"typ"`
+	Act          *ActorClaims `json:"act,omitempty"`
 	jwt.StandardClaims
 }
 
+// ActorClaims is the act claim of an impersonation token, the user who really acts on behalf of the token user
+type ActorClaims struct {
+	UserId    int    `json:"user_id"`
+	UserName  string `json:"user_name"`
+	SessionId int    `json:"session_id"`
+}
+
 type AuthMiddleware struct {
 	config             *configs.Config
+	keySet             *infras.KeySet
 	translationService translationService.TranslationService
 	userService        service.UserService
+	roleService        roleService.RoleService
 }
 
-func NewAuthMiddleware(cfg *configs.Config, translationService translationService.TranslationService, userService service.UserService) AuthMiddleware {
+func NewAuthMiddleware(cfg *configs.Config, keySet *infras.KeySet, translationService translationService.TranslationService, userService service.UserService, roleService roleService.RoleService) AuthMiddleware {
 	return AuthMiddleware{
 		config:             cfg,
+		keySet:             keySet,
 		translationService: translationService,
 		userService:        userService,
+		roleService:        roleService,
 	}
 }
 
 func (a *AuthMiddleware) Auth() gin.HandlerFunc {
 	return func(context *gin.Context) {
 
-		jwtKey := []byte(a.config.JWT.Key)
 		defaultLang := a.config.DefaultLang
 
-		reqToken := context.Request.Header.Get("Authorization")
-		if reqToken == "" {
-			webResponse := helper.WebResponse{
-				Code:   http.StatusUnauthorized,
-				Status: a.translationService.Translation(context, "unauthorized", defaultLang),
+		authorization := context.Request.Header.Get("Authorization")
+
+		// browsers in the cookie auth mode send the access token as an HttpOnly cookie, an explicit header still wins
+		if authorization == "" && a.config.JWT.AuthCookie {
+			accessToken, err := context.Cookie(AccessTokenCookie)
+			if err == nil && accessToken != "" {
+				if !ValidCsrfToken(context) {
+					a.csrfResponse(context, defaultLang)
+					return
+				}
+
+				authorization = "Bearer " + accessToken
 			}
+		}
 
-			context.Writer.Header().Add("Content-Type", "application/json")
-			context.JSON(http.StatusUnauthorized, webResponse)
-			context.Abort()
+		scheme, reqToken, isValid := ParseAuthorization(authorization)
+		if !isValid || (scheme != SchemeBearer && scheme != SchemeApiKey) {
+			reason := TokenMalformed
+			if authorization == "" {
+				reason = TokenMissing
+			}
+
+			a.unauthorizedResponse(context, reason, defaultLang)
 			return
 		}
 
-		splitToken := strings.Split(reqToken, "Bearer ")
-		reqToken = splitToken[1]
+		if scheme == SchemeApiKey {
+			a.apiKeyAuth(context, reqToken)
+			return
+		}
 
-		claims := &Claims{}
+		claims, reason := ParseToken(a.config, a.keySet, reqToken, TokenTypeAccess)
+		if reason != "" {
+			a.unauthorizedResponse(context, reason, defaultLang)
+			return
+		}
 
-		tkn, _ := jwt.ParseWithClaims(reqToken, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
+		currentTime := time.Now()
+		userResponse := a.userService.ValidateSession(context.Request.Context(), claims.SessionId, claims.UserId, reqToken, currentTime.Format("2006-01-02 15:04:05"))
 
-		if !tkn.Valid {
-			webResponse := helper.WebResponse{
-				Code:   http.StatusUnauthorized,
-				Status: a.translationService.Translation(context, "unauthorized", defaultLang),
+		if userResponse.UserId != 0 {
+			reason := model.UserStatusReason(userResponse.UserStatus, userResponse.UserSuspendedUntil, currentTime.Format("2006-01-02 15:04:05"))
+			if reason != "" {
+				a.accountStatusResponse(context, reason, claims.UserLangCode)
+				return
 			}
 
-			context.Writer.Header().Add("Content-Type", "application/json")
-			context.JSON(http.StatusUnauthorized, webResponse)
-			context.Abort()
-			return
-		}
+			if userResponse.UserMustChangePassword && context.FullPath() != PasswordChangePath {
+				webResponse := helper.WebResponse{
+					Code:   http.StatusForbidden,
+					Status: a.translationService.Translation(context, "password_change_required", claims.UserLangCode),
+				}
 
-		userResponse := a.userService.FindById(context.Request.Context(), claims.UserId)
+				context.Writer.Header().Add("Content-Type", "application/json")
+				context.JSON(http.StatusForbidden, webResponse)
+				context.Abort()
+				return
+			}
 
-		if userResponse.UserToken == reqToken {
 			context.Set("user_id", claims.UserId)
 			context.Set("user_email", claims.UserEmail)
 			context.Set("user_name", claims.UserName)
 			context.Set("user_lang_code", claims.UserLangCode)
+			// the roles of the token are only what the user held when it was signed, a role taken away since must not
+			// keep granting its permissions until the token expires
+			context.Set("user_roles", a.roleService.FindRoleCodesByUserId(context.Request.Context(), claims.UserId))
+			context.Set("session_id", claims.SessionId)
+			if claims.Act != nil {
+				context.Set("actor_id", claims.Act.UserId)
+				context.Set("actor_name", claims.Act.UserName)
+				context.Set("actor_session_id", claims.Act.SessionId)
+
+				// every write made while impersonating goes to the audit trail of the impersonation
+				if method := context.Request.Method; method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions {
+					userImpersonationActionRequest := model.UserImpersonationActionRequest{}
+					userImpersonationActionRequest.ImpersonationSessionId = claims.SessionId
+					userImpersonationActionRequest.ActionMethod = method
+					userImpersonationActionRequest.ActionPath = context.Request.URL.Path
+					userImpersonationActionRequest.ActionCreatedAt = currentTime.Format("2006-01-02 15:04:05")
+					a.userService.RecordImpersonationAction(context.Request.Context(), userImpersonationActionRequest)
+				}
+			}
 			context.Next()
 		} else {
+			a.unauthorizedResponse(context, TokenRevoked, claims.UserLangCode)
+		}
+	}
+}
+
+// apiKeyAuth authenticates a service account by one of its API keys, the scopes of the key are kept in the context
+// so RequirePermission can narrow the permissions of its roles down to them
+func (a *AuthMiddleware) apiKeyAuth(context *gin.Context, apiKey string) {
+	defaultLang := a.config.DefaultLang
+
+	currentTime := time.Now()
+	apiKeyResponse := a.userService.ValidateApiKey(context.Request.Context(), apiKey, currentTime.Format("2006-01-02 15:04:05"))
+	if apiKeyResponse.ApiKeyId == 0 {
+		a.unauthorizedResponse(context, ApiKeyInvalid, defaultLang)
+		return
+	}
+
+	userResponse := a.userService.FindById(context.Request.Context(), apiKeyResponse.ApiKeyUserId)
+
+	reason := model.UserStatusReason(userResponse.UserStatus, userResponse.UserSuspendedUntil, currentTime.Format("2006-01-02 15:04:05"))
+	if reason != "" {
+		a.accountStatusResponse(context, reason, userResponse.UserLangCode)
+		return
+	}
+	userRoles := a.roleService.FindRoleCodesByUserId(context.Request.Context(), userResponse.UserId)
+
+	context.Set("user_id", userResponse.UserId)
+	context.Set("user_email", userResponse.UserEmail)
+	context.Set("user_name", userResponse.UserName)
+	context.Set("user_lang_code", userResponse.UserLangCode)
+	context.Set("user_roles", userRoles)
+	context.Set("api_key_id", apiKeyResponse.ApiKeyId)
+	context.Set("api_key_scopes", apiKeyResponse.ApiKeyScopes)
+	context.Next()
+}
+
+// unauthorizedResponse aborts with a 401 whose data tells the client why the token was refused
+func (a *AuthMiddleware) unauthorizedResponse(context *gin.Context, reason string, langCode string) {
+	webResponse := helper.WebResponse{
+		Code:   http.StatusUnauthorized,
+		Status: a.translationService.Translation(context, "unauthorized", langCode),
+		Data:   a.translationService.Translation(context, reason, langCode),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(http.StatusUnauthorized, webResponse)
+	context.Abort()
+}
+
+// accountStatusResponse aborts a request of a user whose account is not active, the reason is the translation key
+func (a *AuthMiddleware) accountStatusResponse(context *gin.Context, reason string, langCode string) {
+	webResponse := helper.WebResponse{
+		Code:   http.StatusForbidden,
+		Status: a.translationService.Translation(context, reason, langCode),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(http.StatusForbidden, webResponse)
+	context.Abort()
+}
+
+// csrfResponse aborts a cookie authenticated request whose csrf header is missing or does not match the cookie
+func (a *AuthMiddleware) csrfResponse(context *gin.Context, langCode string) {
+	webResponse := helper.WebResponse{
+		Code:   http.StatusForbidden,
+		Status: a.translationService.Translation(context, "forbidden", langCode),
+		Data:   a.translationService.Translation(context, CsrfTokenInvalid, langCode),
+	}
+
+	context.Writer.Header().Add("Content-Type", "application/json")
+	context.JSON(http.StatusForbidden, webResponse)
+	context.Abort()
+}
+
+// RequirePermission must be used after Auth, it rejects the request when none of the current roles of the user grant
+// the permission or, for an API key, when the permission is not one of the key scopes
+func (a *AuthMiddleware) RequirePermission(permission string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		payloadJwt := helper.PayloadJwt(context)
+
+		isGranted := a.roleService.HasPermission(context.Request.Context(), payloadJwt.UserRoles, permission)
+		if isGranted && payloadJwt.ApiKeyId != 0 {
+			isGranted = false
+			for _, scope := range payloadJwt.ApiKeyScopes {
+				if scope == permission {
+					isGranted = true
+					break
+				}
+			}
+		}
+
+		if !isGranted {
 			webResponse := helper.WebResponse{
-				Code:   http.StatusUnauthorized,
-				Status: a.translationService.Translation(context, "unauthorized", defaultLang),
+				Code:   http.StatusForbidden,
+				Status: a.translationService.Translation(context, "forbidden", payloadJwt.UserLangCode),
 			}
 
 			context.Writer.Header().Add("Content-Type", "application/json")
-			context.JSON(http.StatusUnauthorized, webResponse)
+			context.JSON(http.StatusForbidden, webResponse)
 			context.Abort()
+			return
 		}
+
+		context.Next()
 	}
 }
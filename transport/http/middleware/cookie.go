@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"collapp/configs"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cookies of the cookie auth mode (JWT.AUTH_COOKIE), the csrf cookie is the only one scripts can read
+const (
+	AccessTokenCookie = "access_token"
+	CsrfTokenCookie   = "csrf_token"
+	CsrfTokenHeader   = "X-CSRF-Token"
+	CsrfTokenInvalid  = "csrf_token_invalid"
+)
+
+// CookieSameSite maps JWT.COOKIE_SAME_SITE to its http.SameSite, anything unknown is strict
+func CookieSameSite(cfg *configs.Config) http.SameSite {
+	switch strings.ToLower(cfg.JWT.CookieSameSite) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
+
+// ValidCsrfToken is the double submit check of a request authenticated by cookie, a state changing method has to
+// repeat the value of the csrf cookie in the X-CSRF-Token header, which another site can not read to forge
+func ValidCsrfToken(context *gin.Context) bool {
+	switch context.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	csrfToken, err := context.Cookie(CsrfTokenCookie)
+	if err != nil || csrfToken == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(csrfToken), []byte(context.GetHeader(CsrfTokenHeader))) == 1
+}
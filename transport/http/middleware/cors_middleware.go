@@ -1,12 +1,36 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"collapp/configs"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORS will handle the CORS middleware. Origins of CORS.ALLOWED_ORIGINS are echoed back and may send credentials, which
+// the cookie auth mode needs from another origin. Without allowed origins every origin may call the API with a bearer
+// token, but browsers do not send cookies along.
+func CORS(cfg *configs.Config) gin.HandlerFunc {
+	allowedOrigins := map[string]bool{}
+	for _, origin := range strings.Split(cfg.Cors.AllowedOrigins, ",") {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		if origin != "" {
+			allowedOrigins[origin] = true
+		}
+	}
 
-// CORS will handle the CORS middleware
-func CORS() gin.HandlerFunc {
 	return func(context *gin.Context) {
-		context.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		context.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		if len(allowedOrigins) == 0 {
+			context.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			context.Writer.Header().Add("Vary", "Origin")
+
+			origin := context.Request.Header.Get("Origin")
+			if allowedOrigins[origin] {
+				context.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+				context.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
 		context.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
 		context.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
 
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"collapp/configs"
+	"collapp/helper"
+	"collapp/infras"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Reasons a token is refused, each one is also the translation key of the 401 message
+const (
+	TokenMissing          = "token_missing"
+	TokenMalformed        = "token_malformed"
+	TokenUnverifiable     = "token_unverifiable"
+	TokenInvalidSignature = "token_invalid_signature"
+	TokenExpired          = "token_expired"
+	TokenNotValidYet      = "token_not_valid_yet"
+	TokenIssuedInFuture   = "token_issued_in_future"
+	TokenInvalidIssuer    = "token_invalid_issuer"
+	TokenInvalidAudience  = "token_invalid_audience"
+	TokenMissingId        = "token_missing_id"
+	TokenInvalidType      = "token_invalid_type"
+	TokenRevoked          = "token_revoked"
+	ApiKeyInvalid         = "api_key_invalid"
+)
+
+// Authorization schemes accepted by Auth
+const (
+	SchemeBearer = "bearer"
+	SchemeApiKey = "apikey"
+)
+
+// ParseAuthorization splits an "Authorization: <scheme> <credentials>" header, the scheme is returned lower cased and
+// anything but exactly one non empty credential after it is refused
+func ParseAuthorization(header string) (string, string, bool) {
+	parts := strings.Split(header, " ")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return strings.ToLower(parts[0]), parts[1], true
+}
+
+// NewStandardClaims fills the registered claims every issued token carries
+func NewStandardClaims(cfg *configs.Config, expirationTime time.Time) jwt.StandardClaims {
+	now := time.Now().Unix()
+	return jwt.StandardClaims{
+		Id:        helper.GenerateRandomToken(16),
+		Issuer:    cfg.JWT.Issuer,
+		Audience:  cfg.JWT.Audience,
+		IssuedAt:  now,
+		NotBefore: now,
+		ExpiresAt: expirationTime.Unix(),
+	}
+}
+
+// ParseToken verifies the signature with the pinned algorithm of the key set, then exp, nbf, iat, iss, aud, jti and
+// the token type. It returns the claims and an empty reason, or the reason the token is refused.
+func ParseToken(cfg *configs.Config, keySet *infras.KeySet, tokenString string, tokenType string) (*Claims, string) {
+	claims := &Claims{}
+	if tokenString == "" {
+		return claims, TokenMissing
+	}
+
+	parser := &jwt.Parser{ValidMethods: []string{keySet.Algorithm()}}
+	_, err := parser.ParseWithClaims(tokenString, claims, keySet.Keyfunc)
+	if err != nil {
+		validationError, ok := err.(*jwt.ValidationError)
+		if !ok {
+			return claims, TokenMalformed
+		}
+
+		switch {
+		case validationError.Errors&jwt.ValidationErrorMalformed != 0:
+			return claims, TokenMalformed
+		case validationError.Errors&jwt.ValidationErrorUnverifiable != 0:
+			return claims, TokenUnverifiable
+		case validationError.Errors&jwt.ValidationErrorSignatureInvalid != 0:
+			return claims, TokenInvalidSignature
+		case validationError.Errors&jwt.ValidationErrorExpired != 0:
+			return claims, TokenExpired
+		case validationError.Errors&jwt.ValidationErrorNotValidYet != 0:
+			return claims, TokenNotValidYet
+		case validationError.Errors&jwt.ValidationErrorIssuedAt != 0:
+			return claims, TokenIssuedInFuture
+		default:
+			return claims, TokenMalformed
+		}
+	}
+
+	// exp, nbf and iat were checked by the parser but only when present, every issued token carries all three
+	switch {
+	case claims.ExpiresAt == 0 || claims.NotBefore == 0 || claims.IssuedAt == 0:
+		return claims, TokenMalformed
+	case !claims.VerifyIssuer(cfg.JWT.Issuer, cfg.JWT.Issuer != ""):
+		return claims, TokenInvalidIssuer
+	case !claims.VerifyAudience(cfg.JWT.Audience, cfg.JWT.Audience != ""):
+		return claims, TokenInvalidAudience
+	case claims.Id == "":
+		return claims, TokenMissingId
+	case claims.TokenType != tokenType:
+		return claims, TokenInvalidType
+	}
+
+	return claims, ""
+}
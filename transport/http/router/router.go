@@ -2,6 +2,7 @@ package router
 
 import (
 	langHandler "collapp/module/lang/handler"
+	roleHandler "collapp/module/role/handler"
 	translationHandler "collapp/module/translation/handler"
 	userHandler "collapp/module/user/handler"
 	"collapp/transport/http/middleware"
@@ -14,6 +15,7 @@ type ModuleHandlers struct {
 	UserHandler        userHandler.UserHandler
 	LangHandler        langHandler.LangHandler
 	TranslationHandler translationHandler.TranslationHandler
+	RoleHandler        roleHandler.RoleHandler
 }
 
 // Router is the router struct containing handlers.
@@ -33,4 +35,5 @@ func (r *Router) SetupRoutes(routerGroup *gin.RouterGroup, auth middleware.AuthM
 	r.ModuleHandlers.UserHandler.Router(routerGroup, auth)
 	r.ModuleHandlers.TranslationHandler.Router(routerGroup, auth)
 	r.ModuleHandlers.LangHandler.Router(routerGroup, auth)
+	r.ModuleHandlers.RoleHandler.Router(routerGroup, auth)
 }
@@ -0,0 +1,108 @@
+package job
+
+import (
+	"collapp/configs"
+	"collapp/infras"
+	roleService "collapp/module/role/service"
+	"collapp/module/user/model"
+	"collapp/module/user/service"
+	"context"
+	"log"
+	"time"
+)
+
+// inactiveReason is the status reason of accounts disabled for inactivity
+const inactiveReason = "inactive"
+
+// Job runs the periodic maintenance of the accounts next to the HTTP server.
+type Job struct {
+	Config      *configs.Config
+	UserService service.UserService
+	RoleService roleService.RoleService
+	Storage     infras.Storage
+}
+
+// NewJob is the provider for Job.
+func NewJob(config *configs.Config, userService service.UserService, roleSvc roleService.RoleService, storage infras.Storage) *Job {
+	return &Job{
+		Config:      config,
+		UserService: userService,
+		RoleService: roleSvc,
+		Storage:     storage,
+	}
+}
+
+// Start grants the first administrator, then runs the tasks once and every ACCOUNT.JOB_INTERVAL in the background
+func (j *Job) Start() {
+	if j.Config.AdminEmail != "" {
+		j.grantFirstAdmin(context.Background(), j.Config.AdminEmail)
+	}
+
+	interval := j.Config.Account.JobInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			j.run(time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+func (j *Job) run(currentTime time.Time) {
+	ctx := context.Background()
+
+	lifted := j.UserService.LiftSuspensions(ctx, currentTime.Format("2006-01-02 15:04:05"))
+	if lifted > 0 {
+		log.Println("Lifted ended suspensions.", lifted)
+	}
+
+	// ACCOUNT.INACTIVE_DAYS of 0 keeps inactive accounts open
+	if j.Config.Account.InactiveDays > 0 {
+		inactiveSince := currentTime.AddDate(0, 0, -j.Config.Account.InactiveDays)
+		disabled := j.UserService.DisableInactive(ctx, inactiveSince.Format("2006-01-02 15:04:05"), inactiveReason, currentTime.Format("2006-01-02 15:04:05"))
+		if disabled > 0 {
+			log.Println("Disabled inactive accounts.", disabled)
+		}
+	}
+
+	// ACCOUNT.TRASH_RETENTION_DAYS of 0 keeps deleted users in the trash
+	if j.Config.Account.TrashRetentionDays > 0 {
+		deletedBefore := currentTime.AddDate(0, 0, -j.Config.Account.TrashRetentionDays)
+		purged := j.UserService.PurgeDeleted(ctx, deletedBefore.Format("2006-01-02 15:04:05"))
+		for _, user := range purged {
+			if user.UserPhoto != "" {
+				j.deletePhoto(ctx, user.UserPhoto)
+			}
+		}
+		if len(purged) > 0 {
+			log.Println("Purged deleted users.", len(purged))
+		}
+	}
+}
+
+// grantFirstAdmin gives the admin role to the active account of ADMIN_EMAIL while no account holds it yet
+func (j *Job) grantFirstAdmin(ctx context.Context, email string) {
+	user := j.UserService.FindByEmail(ctx, email)
+	if user.UserId == 0 || user.UserStatus != model.UserStatusActive {
+		log.Println("ADMIN_EMAIL names no active account.", email)
+		return
+	}
+
+	if j.RoleService.GrantFirstAdmin(ctx, user.UserId) {
+		log.Println("Granted the admin role to ADMIN_EMAIL.", email)
+	}
+}
+
+// deletePhoto removes the photo of a purged user, a failure only leaves an orphan file behind so it is logged
+func (j *Job) deletePhoto(ctx context.Context, key string) {
+	err := j.Storage.Delete(ctx, key)
+	if err != nil {
+		log.Println("Failed deleting photo.", key, err)
+	}
+}
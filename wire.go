@@ -12,6 +12,9 @@ import (
 	langHandler "collapp/module/lang/handler"
 	langRepo "collapp/module/lang/repository"
 	langService "collapp/module/lang/service"
+	roleHandler "collapp/module/role/handler"
+	roleRepo "collapp/module/role/repository"
+	roleService "collapp/module/role/service"
 	translationHandler "collapp/module/translation/handler"
 	translationRepo "collapp/module/translation/repository"
 	translationService "collapp/module/translation/service"
@@ -21,6 +24,7 @@ import (
 
 	httpTransport "collapp/transport/http"
 	httpRouter "collapp/transport/http/router"
+	transportJob "collapp/transport/job"
 )
 
 // Wiring for configurations.
@@ -33,6 +37,31 @@ var database = wire.NewSet(
 	infras.NewMysqlDB,
 )
 
+// Wiring for JWT signing keys.
+var keySet = wire.NewSet(
+	infras.NewKeySet,
+)
+
+// Wiring for OpenID Connect providers.
+var oidc = wire.NewSet(
+	infras.NewOidc,
+)
+
+// Wiring for the password policy and hashing.
+var passwordPolicy = wire.NewSet(
+	infras.NewPasswordPolicy,
+)
+
+// Wiring for mail delivery.
+var mailer = wire.NewSet(
+	infras.NewMailer,
+)
+
+// Wiring for the storage of uploaded files.
+var storage = wire.NewSet(
+	infras.NewStorage,
+)
+
 var translationModule = wire.NewSet(
 	// TranslationRepository interface and implementation
 	translationRepo.NewTranslationRepository,
@@ -45,6 +74,39 @@ var userModule = wire.NewSet(
 	// UserRepository interface and implementation
 	userRepo.NewUserRepository,
 
+	// UserSessionRepository interface and implementation
+	userRepo.NewUserSessionRepository,
+
+	// UserPasswordResetRepository interface and implementation
+	userRepo.NewUserPasswordResetRepository,
+
+	// UserLoginAttemptRepository interface and implementation
+	userRepo.NewUserLoginAttemptRepository,
+
+	// UserTotpRepository interface and implementation
+	userRepo.NewUserTotpRepository,
+
+	// UserApiKeyRepository interface and implementation
+	userRepo.NewUserApiKeyRepository,
+
+	// UserOidcRepository interface and implementation
+	userRepo.NewUserOidcRepository,
+
+	// UserInvitationRepository interface and implementation
+	userRepo.NewUserInvitationRepository,
+
+	// UserImpersonationRepository interface and implementation
+	userRepo.NewUserImpersonationRepository,
+
+	// UserPasswordHistoryRepository interface and implementation
+	userRepo.NewUserPasswordHistoryRepository,
+
+	// UserLoginEventRepository interface and implementation
+	userRepo.NewUserLoginEventRepository,
+
+	// UserMagicLinkRepository interface and implementation
+	userRepo.NewUserMagicLinkRepository,
+
 	// UserService interface and implementation
 	userService.NewUserService,
 )
@@ -57,10 +119,19 @@ var langModule = wire.NewSet(
 	langService.NewLangService,
 )
 
+var roleModule = wire.NewSet(
+	// RoleRepository interface and implementation
+	roleRepo.NewRoleRepository,
+
+	// RoleService interface and implementation
+	roleService.NewRoleService,
+)
+
 var modules = wire.NewSet(
 	translationModule,
 	userModule,
 	langModule,
+	roleModule,
 )
 
 var authMiddleware = wire.NewSet(
@@ -72,11 +143,17 @@ var routing = wire.NewSet(
 	translationHandler.NewTranslationHandler,
 	userHandler.NewUserHandler,
 	langHandler.NewLangHandler,
-	wire.Struct(new(httpRouter.ModuleHandlers), "TranslationHandler", "UserHandler", "LangHandler"),
+	roleHandler.NewRoleHandler,
+	wire.Struct(new(httpRouter.ModuleHandlers), "TranslationHandler", "UserHandler", "LangHandler", "RoleHandler"),
 
 	httpRouter.NewRouter,
 )
 
+// Wiring for the background jobs.
+var jobs = wire.NewSet(
+	transportJob.NewJob,
+)
+
 var httpServer = wire.NewSet(
 	httpTransport.NewHTTP,
 )
@@ -87,12 +164,24 @@ func InitializeService() *httpTransport.HTTP {
 		configurations,
 		// persistences
 		database,
+		// mail
+		mailer,
+		// files
+		storage,
+		// jwt keys
+		keySet,
+		// single sign-on
+		oidc,
+		// passwords
+		passwordPolicy,
 		// middleware
 		authMiddleware,
 		// domains
 		modules,
 		// routing
 		routing,
+		// jobs
+		jobs,
 		// http
 		httpServer,
 	)